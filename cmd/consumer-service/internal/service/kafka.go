@@ -5,8 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/consumer"
+	"kafka-notification-system/pkg/deadletter"
+	"kafka-notification-system/pkg/health"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
 	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/tracing"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
@@ -15,63 +21,140 @@ import (
 
 type KafkaService struct {
 	reader       *kafka.Reader
-	deadLetterWriter *kafka.Writer
+	deadLetterWriter *deadletter.Writer
+	control          *consumer.Control
+	poll             *health.PollTracker
+	stopped          chan struct{}
+	metrics          *metrics.Metrics
 	config       *config.KafkaConfig
 	logger       *zap.Logger
 }
 
 
-func NewKafkaService(kafkaConfig *config.KafkaConfig) *KafkaService {
+func NewKafkaService(kafkaConfig *config.KafkaConfig, m *metrics.Metrics) *KafkaService {
+	// Читаем уведомления всех приоритетов
 	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  kafkaConfig.Brokers,
-		Topic:    kafkaConfig.NotificationsTopic,
-		GroupID:  kafkaConfig.GroupID,
-		MinBytes: 10e3, // 10KB
-		MaxBytes: 10e6, // 10MB
+		Brokers:     kafkaConfig.Brokers,
+		GroupTopics: kafkaConfig.NotificationTopics(),
+		GroupID:     kafkaConfig.GroupID,
+		MinBytes:    10e3, // 10KB
+		MaxBytes:    10e6, // 10MB
 	})
 
-	deadLetterWriter := &kafka.Writer{
-		Addr:         kafka.TCP(kafkaConfig.Brokers...),
-		Topic:        kafkaConfig.DeadLetterTopic,
-		Balancer:     &kafka.LeastBytes{},
-		RequiredAcks: kafka.RequireOne,
-		Async:        false,
-	}
+	deadLetterWriter := deadletter.NewWriter(kafkaConfig, "consumer-service")
+	m.RegisterReader("notifications", reader)
+	m.RegisterWriter(kafkaConfig.DeadLetterTopic, deadLetterWriter)
 
 	return &KafkaService{
 		reader:           reader,
 		deadLetterWriter: deadLetterWriter,
+		control:          consumer.NewControl(),
+		poll:             health.NewPollTracker(),
+		stopped:          make(chan struct{}),
+		metrics:          m,
 		config:           kafkaConfig,
 		logger:           logger.GetLogger(),
 	}
 }
 
-// StartConsuming начинает потребление сообщений из Kafka
+// StartConsuming начинает потребление сообщений из Kafka. Offset фиксируется
+// после обработки сообщения. После Drain текущее сообщение обрабатывается до
+// конца, и StartConsuming возвращает nil
 func (s *KafkaService) StartConsuming(ctx context.Context) error {
+	defer close(s.stopped)
+
 	s.logger.Info("Starting Kafka consumer",
-		zap.String("topic", s.config.NotificationsTopic),
+		zap.Strings("topics", s.config.NotificationTopics()),
 		zap.String("groupId", s.config.GroupID))
 
-	for {
+	// Чтение прерывается и при отмене контекста, и при drain, а обработка — только при отмене
+	fetchCtx, stopFetching := context.WithCancel(ctx)
+	defer stopFetching()
+	go func() {
 		select {
-		case <-ctx.Done():
-			s.logger.Info("Stopping Kafka consumer")
-			return ctx.Err()
-		default:
-			message, err := s.reader.ReadMessage(ctx)
-			if err != nil {
-				s.logger.Error("Failed to read message", zap.Error(err))
-				continue
+		case <-s.control.Draining():
+			stopFetching()
+		case <-fetchCtx.Done():
+		}
+	}()
+
+	for {
+		s.poll.Waiting()
+		if err := s.control.Wait(fetchCtx); err != nil {
+			return s.stopConsuming(ctx)
+		}
+
+		message, err := s.reader.FetchMessage(fetchCtx)
+		if err != nil {
+			if fetchCtx.Err() != nil {
+				return s.stopConsuming(ctx)
 			}
+			s.logger.Error("Failed to read message", zap.Error(err))
+			continue
+		}
+		s.poll.Polled()
+
+		// Пауза могла начаться во время чтения: сообщение ждет возобновления
+		if err := s.control.Wait(fetchCtx); err != nil {
+			return s.stopConsuming(ctx)
+		}
 
-			if err := s.processMessage(ctx, message); err != nil {
-				s.logger.Error("Failed to process message", zap.Error(err))
-				if err := s.handleDeadLetter(ctx, message); err != nil {
-					s.logger.Error("Failed to send message to dead letter topic", zap.Error(err))
-				}
+		s.control.Begin()
+		// Спан обработки продолжает трассировку продюсера из заголовков сообщения
+		spanCtx, span := tracing.StartConsumer(ctx, message)
+		spanCtx = logger.WithRequestID(spanCtx, headerValue(message.Headers, shared.HeaderRequestID))
+		err = s.processMessage(spanCtx, message)
+		if err != nil {
+			log := logger.WithContext(spanCtx, s.logger)
+			log.Error("Failed to process message", zap.Error(err))
+			messageType := headerValue(message.Headers, shared.HeaderMessageType)
+			s.metrics.MessageConsumed(messageType, metrics.StatusFailed)
+			if sendErr := s.deadLetterWriter.Send(spanCtx, message, err); sendErr != nil {
+				log.Error("Failed to send message to dead letter topic", zap.Error(sendErr))
+			} else {
+				s.metrics.MessageConsumed(messageType, metrics.StatusDeadLettered)
 			}
 		}
+		tracing.End(span, err)
+		if err := s.reader.CommitMessages(ctx, message); err != nil {
+			logger.WithContext(spanCtx, s.logger).Error("Failed to commit message", zap.Error(err))
+		}
+		s.control.Done()
+	}
+}
+
+// stopConsuming завершает цикл потребления. Чтение прерывается только отменой
+// контекста или drain, поэтому при активном контексте это остановка через drain
+func (s *KafkaService) stopConsuming(ctx context.Context) error {
+	if ctx.Err() != nil {
+		s.logger.Info("Stopping Kafka consumer")
+		return ctx.Err()
 	}
+	s.logger.Info("Kafka consumer drained")
+	return nil
+}
+
+// Drain останавливает потребление: новые сообщения не читаются, а текущее
+// сообщение обрабатывается до конца с фиксацией offset
+func (s *KafkaService) Drain(ctx context.Context) error {
+	s.control.Drain()
+
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Control возвращает управление потреблением: паузу, возобновление и состояние
+func (s *KafkaService) Control() *consumer.Control {
+	return s.control
+}
+
+// Poll возвращает отметки цикла чтения для проверки готовности
+func (s *KafkaService) Poll() *health.PollTracker {
+	return s.poll
 }
 
 // processMessage обрабатывает полученное сообщение
@@ -97,51 +180,64 @@ func (s *KafkaService) processMessage(ctx context.Context, message kafka.Message
 		return fmt.Errorf("failed to parse kafka message: %w", err)
 	}
 
+	// Заголовок мог не дойти, если сообщение опубликовано в обход Producer
+	if logger.RequestIDFromContext(ctx) == "" {
+		ctx = logger.WithRequestID(ctx, kafkaMessage.RequestID)
+	}
+	log := logger.WithContext(ctx, s.logger)
+
 	// Логируем информацию о сообщении
-	s.logger.Info("Processing message",
+	log.Info("Processing message",
 		zap.String("id", kafkaMessage.ID),
 		zap.String("type", kafkaMessage.Type),
 		zap.Int64("timestamp", kafkaMessage.Timestamp))
 
+	// Сообщения с истекшим сроком жизни не обрабатываются и не считаются обработанными
+	if kafkaMessage.IsExpired(time.Now()) {
+		log.Warn("Message expired, skipping",
+			zap.String("id", kafkaMessage.ID),
+			zap.Int64("expiresAt", kafkaMessage.ExpiresAt))
+		s.metrics.MessageConsumed(kafkaMessage.Type, metrics.StatusExpired)
+		return nil
+	}
+
 	// Обрабатываем в зависимости от типа
 	if kafkaMessage.IsNotificationMessage() {
-		return s.handleNotification(kafkaMessage)
+		return s.handleNotification(ctx, kafkaMessage)
 	}
 
-	s.logger.Warn("Unknown message type", zap.String("type", kafkaMessage.Type))
+	log.Warn("Unknown message type", zap.String("type", kafkaMessage.Type))
+	s.metrics.MessageConsumed(kafkaMessage.Type, metrics.StatusSkipped)
 	return nil
 }
 
 // handleNotification обрабатывает уведомление
-func (s *KafkaService) handleNotification(message *shared.KafkaMessage) error {
+func (s *KafkaService) handleNotification(ctx context.Context, message *shared.KafkaMessage) error {
 	notification, err := message.GetNotificationPayload()
 	if err != nil {
 		return fmt.Errorf("failed to get notification payload: %w", err)
 	}
 
-	s.logger.Info("Sending notification",
-		zap.Int64("chatId", notification.ChatID),
-		zap.String("text", notification.Text))
+	logger.WithContext(ctx, s.logger).Info("Sending notification",
+		logger.ChatID("chatId", notification.ChatID),
+		zap.String("userId", notification.UserID),
+		logger.Text("text", notification.Text))
 
 	// TODO: Здесь можно добавить реальную логику отправки уведомления
 	// Пока просто логируем
-	fmt.Printf("📧 Notification to chat %d: %s\n", notification.ChatID, notification.Text)
 
+	s.metrics.MessageConsumed(message.Type, metrics.StatusProcessed)
 	return nil
 }
 
-// handleDeadLetter отправляет сообщение в dead letter topic
-func (s *KafkaService) handleDeadLetter(ctx context.Context, originalMessage kafka.Message) error {
-	deadLetterMessage := kafka.Message{
-		Key:   originalMessage.Key,
-		Value: originalMessage.Value,
-		Headers: append(originalMessage.Headers, kafka.Header{
-			Key:   "error-timestamp",
-			Value: []byte(fmt.Sprintf("%d", shared.GetCurrentTimestamp())),
-		}),
+// headerValue возвращает значение заголовка Kafka сообщения или пустую строку
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
 	}
-
-	return s.deadLetterWriter.WriteMessages(ctx, deadLetterMessage)
+	return ""
 }
 
 // Close закрывает соединения с Kafka
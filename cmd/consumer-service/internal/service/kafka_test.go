@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func TestKafkaService_ProcessMessage_SkipsExpired(t *testing.T) {
+	m := metrics.New("consumer-service")
+	service := &KafkaService{metrics: m, logger: zap.NewNop()}
+
+	send := func(expiresAt time.Time) {
+		message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+			shared.NotificationMessage{ChatID: 123, Text: "Your code is 123456"})
+		message.ExpiresAt = expiresAt.UnixMilli()
+		value, err := message.ToJSON()
+		if err != nil {
+			t.Fatalf("Failed to marshal message: %v", err)
+		}
+		if err := service.processMessage(context.Background(), kafka.Message{Value: value}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	send(time.Now().Add(-time.Minute))
+	send(time.Now().Add(time.Minute))
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	for _, line := range []string{
+		`notification_system_messages_consumed_total{service="consumer-service",status="expired",type="notification"} 1`,
+		`notification_system_messages_consumed_total{service="consumer-service",status="processed",type="notification"} 1`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
@@ -12,7 +12,10 @@ import (
 	"kafka-notification-system/cmd/consumer-service/internal/handler"
 	"kafka-notification-system/cmd/consumer-service/internal/service"
 	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -29,14 +32,35 @@ func main() {
 	if appConfig.Port == "3000" {
 		appConfig.Port = "3001" // Устанавливаем порт по умолчанию для consumer
 	}
+	accessLogConfig := config.LoadAccessLogConfig()
 	kafkaConfig := config.LoadKafkaConfig("consumer-service", "notification-group")
+	adminConfig := config.LoadAdminConfig()
+	tracingConfig := config.LoadTracingConfig()
+	healthConfig := config.LoadHealthConfig()
+	if adminConfig.Port == "" {
+		adminConfig.Port = "3101" // Порт административного API consumer по умолчанию
+	}
 
 
 	logger.InitLogger(appConfig.Environment)
 	log := logger.GetLogger()
 
 
-	kafkaService := service.NewKafkaService(kafkaConfig)
+	// Метрики Prometheus помечаются именем сервиса из конфигурации Kafka
+	serviceMetrics := metrics.New(kafkaConfig.ClientID)
+
+	// Трассировка OpenTelemetry, спаны отправляются при остановке сервиса
+	shutdownTracing, err := tracing.Init(kafkaConfig.ClientID, tracingConfig)
+	if err != nil {
+		log.Fatal("Failed to init tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	kafkaService := service.NewKafkaService(kafkaConfig, serviceMetrics)
 	defer func() {
 		if err := kafkaService.Close(); err != nil {
 			log.Error("Failed to close Kafka service", zap.Error(err))
@@ -45,34 +69,46 @@ func main() {
 
 
 	consumerHandler := handler.NewConsumerHandler(log)
+	controlHandler := handler.NewControlHandler(kafkaService.Control(), log)
+
+	// Проверки готовности: брокеры и топики, consumer group и цикл чтения
+	checker := health.NewChecker(healthConfig.CheckTimeout)
+	checker.Add("kafka", health.KafkaCheck(kafkaConfig.Brokers, kafkaConfig.NotificationTopics()))
+	checker.Add("consumer-group", health.GroupCheck(kafkaConfig.Brokers, kafkaConfig.GroupID))
+	checker.Add("poll", health.PollCheck(kafkaService.Poll(), kafkaService.Control(), healthConfig.PollTimeout))
 
 	
 	if appConfig.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
-
-	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	}))
-
+	// Один журнал запросов и один recovery: gin.Default добавил бы свои
+	router := gin.New()
+	router.Use(logger.RequestID())
+	router.Use(logger.AccessLog(log, accessLogConfig))
 	router.Use(gin.Recovery())
+	router.Use(serviceMetrics.Middleware())
+	router.Use(tracing.Middleware())
 
 	// Настраиваем маршруты
 	v1 := router.Group("/")
 	{
 		v1.GET("/health", consumerHandler.Health)
+		v1.GET("/livez", checker.Livez)
+		v1.GET("/readyz", checker.Readyz)
+	}
+	router.GET("/metrics", gin.WrapH(serviceMetrics.Handler()))
+
+	// Административное API слушает отдельный порт
+	adminRouter := gin.New()
+	adminRouter.Use(logger.RequestID(), logger.AccessLog(log, accessLogConfig), gin.Recovery())
+	admin := adminRouter.Group("/")
+	{
+		admin.GET("/consumer", controlHandler.Status)
+		admin.POST("/consumer/pause", controlHandler.Pause)
+		admin.POST("/consumer/resume", controlHandler.Resume)
+		admin.GET("/log-level", logger.Levels().GetLevel)
+		admin.PUT("/log-level", logger.Levels().PutLevel)
 	}
 
 
@@ -80,6 +116,10 @@ func main() {
 		Addr:    ":" + appConfig.Port,
 		Handler: router,
 	}
+	adminSrv := &http.Server{
+		Addr:    ":" + adminConfig.Port,
+		Handler: adminRouter,
+	}
 
 	
 	ctx, cancel := context.WithCancel(context.Background())
@@ -88,7 +128,7 @@ func main() {
 
 	go func() {
 		log.Info("Starting Kafka consumer",
-			zap.String("topic", kafkaConfig.NotificationsTopic),
+			zap.Strings("topics", kafkaConfig.NotificationTopics()),
 			zap.String("groupId", kafkaConfig.GroupID))
 
 		if err := kafkaService.StartConsuming(ctx); err != nil && err != context.Canceled {
@@ -110,19 +150,34 @@ func main() {
 	}()
 
 
+	go func() {
+		log.Info("Starting Consumer Service admin API", zap.String("port", adminConfig.Port))
+
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start admin server", zap.Error(err))
+		}
+	}()
+
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 
 	log.Info("Shutting down Consumer Service...")
 
-	
-	cancel()
-
 
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Прекращаем чтение и дожидаемся обработки текущего сообщения
+	if err := kafkaService.Drain(shutdownCtx); err != nil {
+		log.Error("Kafka consumer drain did not complete", zap.Error(err))
+	}
+	cancel()
+
+	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Admin server forced to shutdown", zap.Error(err))
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatal("Server forced to shutdown", zap.Error(err))
 	}
@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"net/http"
+
+	"kafka-notification-system/cmd/fanout-service/internal/service"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ProgressStoreInterface определяет интерфейс чтения состояния рассылок
+type ProgressStoreInterface interface {
+	Get(id string) (*service.BroadcastProgress, bool)
+	List() []service.BroadcastProgress
+}
+
+// FanoutHandler обрабатывает HTTP запросы для Fan-out Service
+type FanoutHandler struct {
+	progress ProgressStoreInterface
+	logger   *zap.Logger
+}
+
+// NewFanoutHandler создает новый экземпляр FanoutHandler
+func NewFanoutHandler(progress ProgressStoreInterface, logger *zap.Logger) *FanoutHandler {
+	return &FanoutHandler{
+		progress: progress,
+		logger:   logger,
+	}
+}
+
+// ListBroadcasts godoc
+// @Summary List broadcasts
+// @Description Get progress and counts of all broadcasts
+// @Tags Broadcasts
+// @Produce json
+// @Success 200 {array} service.BroadcastProgress
+// @Router /broadcasts [get]
+func (h *FanoutHandler) ListBroadcasts(c *gin.Context) {
+	c.JSON(http.StatusOK, h.progress.List())
+}
+
+// GetBroadcast godoc
+// @Summary Get broadcast progress
+// @Description Get progress and counts of a broadcast by its message ID
+// @Tags Broadcasts
+// @Produce json
+// @Param id path string true "Broadcast message ID"
+// @Success 200 {object} service.BroadcastProgress
+// @Failure 404 {object} map[string]string
+// @Router /broadcasts/{id} [get]
+func (h *FanoutHandler) GetBroadcast(c *gin.Context) {
+	progress, ok := h.progress.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Broadcast not found"})
+		return
+	}
+	c.JSON(http.StatusOK, progress.Summary())
+}
+
+// Health godoc
+// @Summary Health check
+// @Description Get the health status of the fan-out service
+// @Tags Health
+// @Produce json
+// @Success 200 {object} shared.HealthResponse
+// @Router /health [get]
+func (h *FanoutHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, shared.HealthResponse{Status: "ok"})
+}
@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/deadletter"
+	"kafka-notification-system/pkg/health"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// AudienceResolver определяет интерфейс получения аудитории из реестра
+type AudienceResolver interface {
+	GetAudience(ctx context.Context, name string) (*shared.Audience, error)
+}
+
+// MessageWriter определяет интерфейс публикации сообщений в Kafka
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// broadcastJob описывает рассылку, полученную из Kafka, вместе с атрибутами
+// конверта, которые наследуют уведомления участникам
+type broadcastJob struct {
+	ID        string
+	Priority  string
+	ExpiresAt int64
+	RequestID string
+	Broadcast *shared.BroadcastMessage
+}
+
+// KafkaService разворачивает рассылки на аудиторию в отдельные уведомления
+type KafkaService struct {
+	reader           *kafka.Reader
+	writer           MessageWriter
+	deadLetterWriter *deadletter.Writer
+	audiences        AudienceResolver
+	progress         *ProgressStore
+	poll             *health.PollTracker
+	batchSize        int
+	config           *config.KafkaConfig
+	logger           *zap.Logger
+}
+
+// NewKafkaService создает новый экземпляр KafkaService
+func NewKafkaService(kafkaConfig *config.KafkaConfig, fanoutConfig *config.FanoutConfig,
+	audiences AudienceResolver, progress *ProgressStore) *KafkaService {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:  kafkaConfig.Brokers,
+		Topic:    kafkaConfig.BroadcastsTopic,
+		GroupID:  kafkaConfig.GroupID,
+		MinBytes: 10e3, // 10KB
+		MaxBytes: 10e6, // 10MB
+	})
+
+	// Топик задается для каждого сообщения по приоритету рассылки. Партиция
+	// выбирается по ключу получателя, как и у Producer Service: уведомления одному
+	// получателю обрабатываются по порядку, а повторно опубликованное после сбоя
+	// уведомление попадает в ту же партицию, что и первое, и Notification Service
+	// распознает повтор
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kafkaConfig.Brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		Async:        false,
+	}
+
+	return &KafkaService{
+		reader:           reader,
+		writer:           writer,
+		deadLetterWriter: deadletter.NewWriter(kafkaConfig, "fanout-service"),
+		audiences:        audiences,
+		progress:         progress,
+		poll:             health.NewPollTracker(),
+		batchSize:        fanoutConfig.BatchSize,
+		config:           kafkaConfig,
+		logger:           logger.GetLogger(),
+	}
+}
+
+// StartConsuming начинает потребление рассылок из Kafka. Offset фиксируется
+// только после полной обработки рассылки, поэтому после перезапуска рассылка
+// будет получена повторно и продолжена с сохраненной позиции
+func (s *KafkaService) StartConsuming(ctx context.Context) error {
+	s.logger.Info("Starting Fan-out Service Kafka consumer",
+		zap.String("topic", s.config.BroadcastsTopic),
+		zap.String("groupId", s.config.GroupID))
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.Info("Stopping Fan-out Service Kafka consumer")
+			return ctx.Err()
+		default:
+			s.poll.Waiting()
+			message, err := s.reader.FetchMessage(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				s.logger.Error("Failed to read message", zap.Error(err))
+				continue
+			}
+			s.poll.Polled()
+
+			// Логи рассылки и уведомления участникам наследуют идентификатор запроса
+			messageCtx := logger.WithRequestID(ctx, headerValue(message.Headers, shared.HeaderRequestID))
+			if err := s.processMessage(messageCtx, message); err != nil {
+				if ctx.Err() != nil {
+					// Остановка посреди рассылки: offset не фиксируем, позиция сохранена
+					return ctx.Err()
+				}
+				log := logger.WithContext(messageCtx, s.logger)
+				log.Error("Failed to process broadcast", zap.Error(err))
+				if sendErr := s.deadLetterWriter.Send(ctx, message, err); sendErr != nil {
+					log.Error("Failed to send message to dead letter topic", zap.Error(sendErr))
+				}
+			}
+
+			if err := s.reader.CommitMessages(ctx, message); err != nil {
+				s.logger.Error("Failed to commit message", zap.Error(err))
+			}
+		}
+	}
+}
+
+// Poll возвращает отметки цикла чтения для проверки готовности
+func (s *KafkaService) Poll() *health.PollTracker {
+	return s.poll
+}
+
+// processMessage обрабатывает полученную рассылку
+func (s *KafkaService) processMessage(ctx context.Context, message kafka.Message) error {
+	if len(message.Value) == 0 {
+		return fmt.Errorf("empty message value")
+	}
+
+	// Парсим сообщение
+	var rawMessage map[string]interface{}
+	if err := json.Unmarshal(message.Value, &rawMessage); err != nil {
+		return fmt.Errorf("failed to unmarshal message: %w", err)
+	}
+
+	// Проверяем валидность структуры
+	if !shared.IsValidKafkaMessage(rawMessage) {
+		return fmt.Errorf("invalid message format")
+	}
+
+	kafkaMessage, err := shared.FromJSON(message.Value)
+	if err != nil {
+		return fmt.Errorf("failed to parse kafka message: %w", err)
+	}
+
+	// Заголовок мог не дойти, если сообщение опубликовано в обход Producer
+	if logger.RequestIDFromContext(ctx) == "" {
+		ctx = logger.WithRequestID(ctx, kafkaMessage.RequestID)
+	}
+
+	if !kafkaMessage.IsBroadcastMessage() {
+		logger.WithContext(ctx, s.logger).Warn("Received non-broadcast message", zap.String("type", kafkaMessage.Type))
+		return nil
+	}
+
+	// Рассылку с истекшим сроком жизни не разворачиваем
+	if kafkaMessage.IsExpired(time.Now()) {
+		logger.WithContext(ctx, s.logger).Warn("Broadcast expired, skipping",
+			zap.String("broadcastId", kafkaMessage.ID),
+			zap.Int64("expiresAt", kafkaMessage.ExpiresAt))
+		return nil
+	}
+
+	broadcast, err := kafkaMessage.GetBroadcastPayload()
+	if err != nil {
+		return fmt.Errorf("failed to get broadcast payload: %w", err)
+	}
+
+	return s.fanOut(ctx, &broadcastJob{
+		ID:        kafkaMessage.ID,
+		Priority:  headerValue(message.Headers, shared.HeaderPriority),
+		ExpiresAt: kafkaMessage.ExpiresAt,
+		RequestID: logger.RequestIDFromContext(ctx),
+		Broadcast: broadcast,
+	})
+}
+
+// fanOut публикует уведомление каждому участнику аудитории пакетами,
+// сохраняя позицию после каждого пакета. Уведомления уходят в топик приоритета
+// рассылки и наследуют ее срок жизни
+func (s *KafkaService) fanOut(ctx context.Context, job *broadcastJob) error {
+	broadcastID, broadcast := job.ID, job.Broadcast
+	progress, err := s.begin(ctx, broadcastID, broadcast)
+	if err != nil {
+		return err
+	}
+	if progress.Status == BroadcastCompleted {
+		logger.WithContext(ctx, s.logger).Info("Broadcast already completed, skipping",
+			zap.String("broadcastId", broadcastID),
+			zap.Int("published", progress.Published))
+		return nil
+	}
+
+	for progress.Published < progress.Total {
+		end := progress.Published + s.batchSize
+		if end > progress.Total {
+			end = progress.Total
+		}
+
+		batch := make([]kafka.Message, 0, end-progress.Published)
+		for _, userID := range progress.Members[progress.Published:end] {
+			message, err := s.buildNotification(job, userID)
+			if err != nil {
+				return s.fail(broadcastID, err)
+			}
+			batch = append(batch, message)
+		}
+
+		if err := s.writer.WriteMessages(ctx, batch...); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return s.fail(broadcastID, fmt.Errorf("failed to publish batch: %w", err))
+		}
+
+		progress, err = s.progress.Advance(broadcastID, len(batch))
+		if err != nil {
+			return fmt.Errorf("failed to save broadcast progress: %w", err)
+		}
+
+		logger.WithContext(ctx, s.logger).Info("Broadcast progress",
+			zap.String("broadcastId", broadcastID),
+			zap.String("audience", broadcast.Audience),
+			zap.Int("published", progress.Published),
+			zap.Int("total", progress.Total))
+	}
+
+	if _, err := s.progress.Complete(broadcastID); err != nil {
+		return fmt.Errorf("failed to save broadcast progress: %w", err)
+	}
+
+	logger.WithContext(ctx, s.logger).Info("Broadcast completed",
+		zap.String("broadcastId", broadcastID),
+		zap.String("audience", broadcast.Audience),
+		zap.Int("published", progress.Published))
+	return nil
+}
+
+// begin возвращает сохраненное состояние рассылки или начинает новую,
+// фиксируя текущий состав аудитории
+func (s *KafkaService) begin(ctx context.Context, broadcastID string, broadcast *shared.BroadcastMessage) (*BroadcastProgress, error) {
+	if progress, ok := s.progress.Get(broadcastID); ok {
+		if progress.Status == BroadcastCompleted {
+			return progress, nil
+		}
+
+		logger.WithContext(ctx, s.logger).Info("Resuming broadcast",
+			zap.String("broadcastId", broadcastID),
+			zap.Int("published", progress.Published),
+			zap.Int("total", progress.Total))
+		return s.progress.Resume(broadcastID)
+	}
+
+	audience, err := s.audiences.GetAudience(ctx, broadcast.Audience)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve audience: %w", err)
+	}
+
+	logger.WithContext(ctx, s.logger).Info("Starting broadcast",
+		zap.String("broadcastId", broadcastID),
+		zap.String("audience", audience.Name),
+		zap.Int("members", len(audience.Members)))
+
+	return s.progress.Start(broadcastID, audience.Name, uniqueMembers(audience.Members))
+}
+
+// fail сохраняет ошибку рассылки и возвращает ее
+func (s *KafkaService) fail(broadcastID string, cause error) error {
+	if _, err := s.progress.Fail(broadcastID, cause); err != nil {
+		s.logger.Error("Failed to save broadcast progress", zap.Error(err))
+	}
+	return cause
+}
+
+// buildNotification создает уведомление участнику рассылки. Идентификатор
+// детерминирован, поэтому повторная публикация дает то же сообщение
+func (s *KafkaService) buildNotification(job *broadcastJob, userID string) (kafka.Message, error) {
+	notification := shared.NotificationMessage{
+		UserID:   userID,
+		Category: job.Broadcast.Category,
+		Text:     job.Broadcast.Text,
+		Channels: job.Broadcast.Channels,
+	}
+	message := &shared.KafkaMessage{
+		BaseKafkaMessage: shared.BaseKafkaMessage{
+			ID:        RecipientMessageID(job.ID, userID),
+			Type:      shared.MessageTypeNotification,
+			Payload:   notification,
+			Timestamp: shared.GetCurrentTimestamp(),
+			ExpiresAt: job.ExpiresAt,
+			RequestID: job.RequestID,
+		},
+	}
+
+	value, err := message.ToJSON()
+	if err != nil {
+		return kafka.Message{}, fmt.Errorf("failed to marshal notification: %w", err)
+	}
+
+	priority := job.Priority
+	if priority == "" {
+		priority = shared.PriorityNormal
+	}
+
+	headers := []kafka.Header{
+		{Key: shared.HeaderMessageType, Value: []byte(shared.MessageTypeNotification)},
+		{Key: shared.HeaderBroadcastID, Value: []byte(job.ID)},
+		{Key: shared.HeaderPriority, Value: []byte(priority)},
+	}
+	if job.RequestID != "" {
+		headers = append(headers, kafka.Header{Key: shared.HeaderRequestID, Value: []byte(job.RequestID)})
+	}
+
+	return kafka.Message{
+		Topic:   s.config.PriorityTopic(priority),
+		Key:     []byte(notification.Recipient()),
+		Value:   value,
+		Headers: headers,
+	}, nil
+}
+
+// headerValue возвращает значение заголовка Kafka сообщения или пустую строку
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// RecipientMessageID вычисляет идентификатор уведомления участнику рассылки
+func RecipientMessageID(broadcastID, userID string) string {
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(broadcastID+"/"+userID)).String()
+}
+
+// uniqueMembers убирает повторы участников, сохраняя порядок
+func uniqueMembers(members []string) []string {
+	seen := make(map[string]bool, len(members))
+	result := make([]string, 0, len(members))
+	for _, m := range members {
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		result = append(result, m)
+	}
+	return result
+}
+
+// Close закрывает соединения с Kafka
+func (s *KafkaService) Close() error {
+	if err := s.reader.Close(); err != nil {
+		s.logger.Error("Failed to close reader", zap.Error(err))
+	}
+	if err := s.writer.Close(); err != nil {
+		s.logger.Error("Failed to close writer", zap.Error(err))
+	}
+	if err := s.deadLetterWriter.Close(); err != nil {
+		s.logger.Error("Failed to close dead letter writer", zap.Error(err))
+	}
+	return nil
+}
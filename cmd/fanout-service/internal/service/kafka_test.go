@@ -0,0 +1,211 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// mockWriter запоминает опубликованные сообщения и может падать после заданного числа пакетов
+type mockWriter struct {
+	messages  []kafka.Message
+	batches   int
+	failAfter int
+}
+
+func (m *mockWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if m.failAfter > 0 && m.batches >= m.failAfter {
+		return errors.New("broker unavailable")
+	}
+	m.batches++
+	m.messages = append(m.messages, msgs...)
+	return nil
+}
+
+func (m *mockWriter) Close() error {
+	return nil
+}
+
+// mockAudiences возвращает аудитории из фиксированного набора
+type mockAudiences map[string]*shared.Audience
+
+func (m mockAudiences) GetAudience(ctx context.Context, name string) (*shared.Audience, error) {
+	a, ok := m[name]
+	if !ok {
+		return nil, errors.New("audience not found")
+	}
+	return a, nil
+}
+
+func newTestService(t *testing.T, writer *mockWriter, audiences mockAudiences) *KafkaService {
+	progress, err := NewProgressStore(filepath.Join(t.TempDir(), "fanout.json"))
+	if err != nil {
+		t.Fatalf("Failed to create progress store: %v", err)
+	}
+
+	return &KafkaService{
+		writer:    writer,
+		audiences: audiences,
+		progress:  progress,
+		batchSize: 2,
+		config: &config.KafkaConfig{
+			NotificationsTopic: "notifications",
+			HighPriorityTopic:  "notifications-high",
+			LowPriorityTopic:   "notifications-low",
+		},
+		logger: zap.NewNop(),
+	}
+}
+
+func TestKafkaService_FanOut(t *testing.T) {
+	writer := &mockWriter{}
+	audiences := mockAudiences{"oncall": {Name: "oncall", Members: []string{"u1", "u2", "u3", "u2", "u4", "u5"}}}
+	service := newTestService(t, writer, audiences)
+
+	broadcast := &shared.BroadcastMessage{Audience: "oncall", Text: "Deploy started"}
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-1", Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(writer.messages) != 5 {
+		t.Errorf("Expected 5 messages (duplicates removed), got %d", len(writer.messages))
+	}
+
+	progress, ok := service.progress.Get("b-1")
+	if !ok {
+		t.Fatal("Expected progress to be recorded")
+	}
+	if progress.Status != BroadcastCompleted || progress.Published != 5 || progress.Total != 5 {
+		t.Errorf("Unexpected progress: %+v", progress)
+	}
+
+	// Повторная доставка той же рассылки не должна публиковать сообщения заново
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-1", Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(writer.messages) != 5 {
+		t.Errorf("Expected no duplicate sends, got %d messages", len(writer.messages))
+	}
+}
+
+func TestKafkaService_FanOut_ResumesAfterFailure(t *testing.T) {
+	writer := &mockWriter{failAfter: 1}
+	audiences := mockAudiences{"all": {Name: "all", Members: []string{"u1", "u2", "u3", "u4", "u5"}}}
+	service := newTestService(t, writer, audiences)
+
+	broadcast := &shared.BroadcastMessage{Audience: "all", Text: "Hello"}
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-2", Broadcast: broadcast}); err == nil {
+		t.Fatal("Expected error from failing writer")
+	}
+
+	progress, _ := service.progress.Get("b-2")
+	if progress.Status != BroadcastFailed || progress.Published != 2 {
+		t.Fatalf("Unexpected progress after failure: %+v", progress)
+	}
+
+	// Аудитория изменилась, но возобновление использует зафиксированный состав
+	audiences["all"].Members = []string{"u9"}
+	writer.failAfter = 0
+
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-2", Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error on resume: %v", err)
+	}
+
+	if len(writer.messages) != 5 {
+		t.Fatalf("Expected 5 messages in total, got %d", len(writer.messages))
+	}
+
+	seen := make(map[string]bool)
+	for _, m := range writer.messages {
+		message, err := shared.FromJSON(m.Value)
+		if err != nil {
+			t.Fatalf("Failed to decode message: %v", err)
+		}
+		if seen[message.ID] {
+			t.Errorf("Duplicate message %s", message.ID)
+		}
+		seen[message.ID] = true
+		// Партиция выбирается по получателю, как у Producer Service
+		notification, err := message.GetNotificationPayload()
+		if err != nil {
+			t.Fatalf("Failed to decode notification: %v", err)
+		}
+		if string(m.Key) != notification.Recipient() {
+			t.Errorf("Expected recipient key for %s, got %s", message.ID, m.Key)
+		}
+	}
+
+	if !seen[RecipientMessageID("b-2", "u5")] {
+		t.Error("Expected message for the last member")
+	}
+}
+
+func TestKafkaService_FanOut_Priority(t *testing.T) {
+	writer := &mockWriter{}
+	audiences := mockAudiences{"oncall": {Name: "oncall", Members: []string{"u1", "u2", "u3"}}}
+	service := newTestService(t, writer, audiences)
+
+	broadcast := &shared.BroadcastMessage{Audience: "oncall", Text: "Incident declared"}
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-3", Priority: shared.PriorityHigh, Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, m := range writer.messages {
+		if m.Topic != "notifications-high" {
+			t.Errorf("Expected high priority topic, got %s", m.Topic)
+		}
+	}
+}
+
+func TestKafkaService_FanOut_InheritsExpiry(t *testing.T) {
+	writer := &mockWriter{}
+	audiences := mockAudiences{"oncall": {Name: "oncall", Members: []string{"u1", "u2"}}}
+	service := newTestService(t, writer, audiences)
+
+	expiresAt := shared.GetCurrentTimestamp() + 60_000
+	broadcast := &shared.BroadcastMessage{Audience: "oncall", Text: "Maintenance in 1 minute"}
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-4", ExpiresAt: expiresAt, Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, m := range writer.messages {
+		message, err := shared.FromJSON(m.Value)
+		if err != nil {
+			t.Fatalf("Failed to parse notification: %v", err)
+		}
+		if message.ExpiresAt != expiresAt {
+			t.Errorf("Expected expiresAt %d, got %d", expiresAt, message.ExpiresAt)
+		}
+	}
+}
+
+func TestKafkaService_FanOut_InheritsRequestID(t *testing.T) {
+	writer := &mockWriter{}
+	audiences := mockAudiences{"oncall": {Name: "oncall", Members: []string{"u1", "u2"}}}
+	service := newTestService(t, writer, audiences)
+
+	broadcast := &shared.BroadcastMessage{Audience: "oncall", Text: "Incident resolved"}
+	if err := service.fanOut(context.Background(), &broadcastJob{ID: "b-5", RequestID: "req-42", Broadcast: broadcast}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, m := range writer.messages {
+		if got := headerValue(m.Headers, shared.HeaderRequestID); got != "req-42" {
+			t.Errorf("Expected request-id header req-42, got %q", got)
+		}
+		message, err := shared.FromJSON(m.Value)
+		if err != nil {
+			t.Fatalf("Failed to parse notification: %v", err)
+		}
+		if message.RequestID != "req-42" {
+			t.Errorf("Expected requestId req-42, got %q", message.RequestID)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"sort"
+	"sync"
+
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+)
+
+// Статусы рассылки
+const (
+	BroadcastRunning   = "running"
+	BroadcastCompleted = "completed"
+	BroadcastFailed    = "failed"
+)
+
+// BroadcastProgress хранит состояние развертывания одной рассылки.
+// Members фиксирует состав аудитории на момент старта, чтобы перезапуск
+// продолжал с того же места даже после изменения аудитории
+type BroadcastProgress struct {
+	ID          string   `json:"id"`
+	Audience    string   `json:"audience"`
+	Status      string   `json:"status"`
+	Total       int      `json:"total"`
+	Published   int      `json:"published"`
+	Error       string   `json:"error,omitempty"`
+	Members     []string `json:"members,omitempty"`
+	StartedAt   int64    `json:"startedAt"`
+	UpdatedAt   int64    `json:"updatedAt"`
+	CompletedAt int64    `json:"completedAt,omitempty"`
+}
+
+// Summary возвращает копию состояния без списка участников
+func (p *BroadcastProgress) Summary() BroadcastProgress {
+	summary := *p
+	summary.Members = nil
+	return summary
+}
+
+// ProgressStore хранит состояние рассылок и сохраняет его на диск
+// после каждого опубликованного пакета
+type ProgressStore struct {
+	mu         sync.RWMutex
+	broadcasts map[string]*BroadcastProgress
+	file       *storage.JSONFile
+}
+
+// NewProgressStore создает новый экземпляр ProgressStore и загружает сохраненное состояние
+func NewProgressStore(path string) (*ProgressStore, error) {
+	s := &ProgressStore{
+		broadcasts: make(map[string]*BroadcastProgress),
+		file:       storage.NewJSONFile(path),
+	}
+
+	if err := s.file.Load(&s.broadcasts); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Get возвращает копию состояния рассылки
+func (s *ProgressStore) Get(id string) (*BroadcastProgress, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.broadcasts[id]
+	if !ok {
+		return nil, false
+	}
+	copied := *p
+	return &copied, true
+}
+
+// List возвращает сводки всех рассылок, начиная с самых новых
+func (s *ProgressStore) List() []BroadcastProgress {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]BroadcastProgress, 0, len(s.broadcasts))
+	for _, p := range s.broadcasts {
+		result = append(result, p.Summary())
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt > result[j].StartedAt })
+	return result
+}
+
+// Start регистрирует новую рассылку с зафиксированным составом участников
+func (s *ProgressStore) Start(id, audience string, members []string) (*BroadcastProgress, error) {
+	now := shared.GetCurrentTimestamp()
+	return s.update(id, func(p *BroadcastProgress) {
+		*p = BroadcastProgress{
+			ID:        id,
+			Audience:  audience,
+			Status:    BroadcastRunning,
+			Total:     len(members),
+			Members:   members,
+			StartedAt: now,
+			UpdatedAt: now,
+		}
+	})
+}
+
+// Resume переводит ранее прерванную или неудачную рассылку в статус running
+func (s *ProgressStore) Resume(id string) (*BroadcastProgress, error) {
+	return s.update(id, func(p *BroadcastProgress) {
+		p.Status = BroadcastRunning
+		p.Error = ""
+		p.UpdatedAt = shared.GetCurrentTimestamp()
+	})
+}
+
+// Advance увеличивает счетчик опубликованных сообщений на n
+func (s *ProgressStore) Advance(id string, n int) (*BroadcastProgress, error) {
+	return s.update(id, func(p *BroadcastProgress) {
+		p.Published += n
+		p.UpdatedAt = shared.GetCurrentTimestamp()
+	})
+}
+
+// Complete отмечает рассылку завершенной и освобождает список участников
+func (s *ProgressStore) Complete(id string) (*BroadcastProgress, error) {
+	return s.update(id, func(p *BroadcastProgress) {
+		now := shared.GetCurrentTimestamp()
+		p.Status = BroadcastCompleted
+		p.Members = nil
+		p.UpdatedAt = now
+		p.CompletedAt = now
+	})
+}
+
+// Fail отмечает рассылку неудачной, сохраняя позицию для возобновления
+func (s *ProgressStore) Fail(id string, cause error) (*BroadcastProgress, error) {
+	return s.update(id, func(p *BroadcastProgress) {
+		p.Status = BroadcastFailed
+		p.Error = cause.Error()
+		p.UpdatedAt = shared.GetCurrentTimestamp()
+	})
+}
+
+// update применяет изменение к рассылке и сохраняет состояние на диск
+func (s *ProgressStore) update(id string, apply func(p *BroadcastProgress)) (*BroadcastProgress, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.broadcasts[id]
+	next := &BroadcastProgress{}
+	if existed {
+		*next = *previous
+	}
+	apply(next)
+	s.broadcasts[id] = next
+
+	if err := s.file.Save(s.broadcasts); err != nil {
+		if existed {
+			s.broadcasts[id] = previous
+		} else {
+			delete(s.broadcasts, id)
+		}
+		return nil, err
+	}
+
+	copied := *next
+	return &copied, nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"kafka-notification-system/cmd/fanout-service/internal/handler"
+	"kafka-notification-system/cmd/fanout-service/internal/service"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/recipient"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @title Fan-out Service API
+// @version 1.0
+// @description The broadcast fan-out stage of Kafka Notification System
+// @host localhost:3004
+// @BasePath /
+func main() {
+	// Загружаем конфигурацию
+	appConfig := config.LoadAppConfig()
+	if appConfig.Port == "3000" {
+		appConfig.Port = "3004" // Устанавливаем порт по умолчанию для fan-out
+	}
+	accessLogConfig := config.LoadAccessLogConfig()
+	adminConfig := config.LoadAdminConfig()
+	if adminConfig.Port == "" {
+		adminConfig.Port = "3104" // Порт административного API fan-out по умолчанию
+	}
+	kafkaConfig := config.LoadKafkaConfig("fanout-service", "fanout-group")
+	fanoutConfig := config.LoadFanoutConfig()
+	healthConfig := config.LoadHealthConfig()
+
+	// Инициализируем логгер
+	logger.InitLogger(appConfig.Environment)
+	log := logger.GetLogger()
+
+	// Загружаем состояние рассылок
+	progress, err := service.NewProgressStore(filepath.Join(appConfig.DataDir, "fanout.json"))
+	if err != nil {
+		log.Fatal("Failed to load broadcast progress", zap.Error(err))
+	}
+
+	// Создаем Kafka сервис
+	kafkaService := service.NewKafkaService(kafkaConfig, fanoutConfig,
+		recipient.NewClient(appConfig.RecipientServiceURL), progress)
+	defer func() {
+		if err := kafkaService.Close(); err != nil {
+			log.Error("Failed to close Kafka service", zap.Error(err))
+		}
+	}()
+
+	// Создаем обработчики
+	fanoutHandler := handler.NewFanoutHandler(progress, log)
+
+	// Проверки готовности: брокеры и топики, consumer group и цикл чтения рассылок
+	checker := health.NewChecker(healthConfig.CheckTimeout)
+	checker.Add("kafka", health.KafkaCheck(kafkaConfig.Brokers,
+		append(kafkaConfig.NotificationTopics(), kafkaConfig.BroadcastsTopic)))
+	checker.Add("consumer-group", health.GroupCheck(kafkaConfig.Brokers, kafkaConfig.GroupID))
+	checker.Add("poll", health.PollCheck(kafkaService.Poll(), nil, healthConfig.PollTimeout))
+
+	// Настраиваем Gin
+	if appConfig.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Один журнал запросов и один recovery: gin.Default добавил бы свои
+	router := gin.New()
+	router.Use(logger.RequestID())
+	router.Use(logger.AccessLog(log, accessLogConfig))
+	router.Use(gin.Recovery())
+
+	// Настраиваем маршруты
+	v1 := router.Group("/")
+	{
+		v1.GET("/broadcasts", fanoutHandler.ListBroadcasts)
+		v1.GET("/broadcasts/:id", fanoutHandler.GetBroadcast)
+		v1.GET("/health", fanoutHandler.Health)
+		v1.GET("/livez", checker.Livez)
+		v1.GET("/readyz", checker.Readyz)
+	}
+
+	// Административное API слушает отдельный порт
+	adminRouter := gin.New()
+	adminRouter.Use(logger.RequestID(), logger.AccessLog(log, accessLogConfig), gin.Recovery())
+	admin := adminRouter.Group("/")
+	{
+		admin.GET("/log-level", logger.Levels().GetLevel)
+		admin.PUT("/log-level", logger.Levels().PutLevel)
+	}
+
+	// Создаем HTTP серверы
+	srv := &http.Server{
+		Addr:    ":" + appConfig.Port,
+		Handler: router,
+	}
+	adminSrv := &http.Server{
+		Addr:    ":" + adminConfig.Port,
+		Handler: adminRouter,
+	}
+
+	// Создаем контекст для graceful shutdown
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Запускаем Kafka consumer в горутине
+	go func() {
+		log.Info("Starting Fan-out Service Kafka consumer",
+			zap.String("topic", kafkaConfig.BroadcastsTopic),
+			zap.String("groupId", kafkaConfig.GroupID))
+
+		if err := kafkaService.StartConsuming(ctx); err != nil && err != context.Canceled {
+			log.Error("Kafka consumer error", zap.Error(err))
+		}
+	}()
+
+	// Запускаем HTTP сервер в горутине
+	go func() {
+		log.Info("Starting Fan-out Service",
+			zap.String("port", appConfig.Port),
+			zap.Strings("kafka_brokers", kafkaConfig.Brokers))
+
+		fmt.Printf("📣 Fan-out Service running on port %s\n", appConfig.Port)
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start server", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		log.Info("Starting Fan-out Service admin API", zap.String("port", adminConfig.Port))
+
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start admin server", zap.Error(err))
+		}
+	}()
+
+	// Ожидаем сигнал завершения
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Shutting down Fan-out Service...")
+
+	// Отменяем контекст для остановки Kafka consumer
+	cancel()
+
+	// Graceful shutdown HTTP сервера
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer shutdownCancel()
+
+	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Admin server forced to shutdown", zap.Error(err))
+	}
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Fatal("Server forced to shutdown", zap.Error(err))
+	}
+
+	log.Info("Fan-out Service stopped")
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"net/http"
+
+	"kafka-notification-system/pkg/consumer"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ConsumerControlInterface определяет интерфейс управления потреблением сообщений
+type ConsumerControlInterface interface {
+	Pause(actor, reason string) consumer.Status
+	Resume() consumer.Status
+	Status() consumer.Status
+}
+
+// ControlHandler обрабатывает запросы административного API к consumer
+type ControlHandler struct {
+	control ConsumerControlInterface
+	logger  *zap.Logger
+}
+
+// NewControlHandler создает новый экземпляр ControlHandler
+func NewControlHandler(control ConsumerControlInterface, logger *zap.Logger) *ControlHandler {
+	return &ControlHandler{
+		control: control,
+		logger:  logger,
+	}
+}
+
+// Status godoc
+// @Summary Consumer state
+// @Description Get the consumer state (running, paused, draining) and the number of messages in flight
+// @Tags Consumer
+// @Produce json
+// @Success 200 {object} consumer.Status
+// @Router /consumer [get]
+func (h *ControlHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, h.control.Status())
+}
+
+// Pause godoc
+// @Summary Pause consumption
+// @Description Stop taking new messages without leaving the consumer group. Messages in flight are finished
+// @Tags Consumer
+// @Accept json
+// @Produce json
+// @Param request body consumer.PauseRequest false "Who pauses and why"
+// @Success 200 {object} consumer.Status
+// @Failure 400 {object} map[string]string
+// @Router /consumer/pause [post]
+func (h *ControlHandler) Pause(c *gin.Context) {
+	var req consumer.PauseRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid pause request"})
+			return
+		}
+	}
+	if req.Actor == "" {
+		req.Actor = c.ClientIP()
+	}
+
+	status := h.control.Pause(req.Actor, req.Reason)
+	logger.WithContext(c.Request.Context(), h.logger).Warn("Consumer paused",
+		zap.String("actor", req.Actor),
+		zap.String("reason", req.Reason),
+		zap.String("state", status.State))
+	c.JSON(http.StatusOK, status)
+}
+
+// Resume godoc
+// @Summary Resume consumption
+// @Description Resume a paused consumer
+// @Tags Consumer
+// @Produce json
+// @Success 200 {object} consumer.Status
+// @Router /consumer/resume [post]
+func (h *ControlHandler) Resume(c *gin.Context) {
+	status := h.control.Resume()
+	logger.WithContext(c.Request.Context(), h.logger).Info("Consumer resumed", zap.String("actor", c.ClientIP()), zap.String("state", status.State))
+	c.JSON(http.StatusOK, status)
+}
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"kafka-notification-system/cmd/notification-service/internal/service"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// DeadLetterIndexInterface определяет интерфейс чтения сообщений dead letter topic
+type DeadLetterIndexInterface interface {
+	Get(id string) (*service.DeadLetterEntry, bool)
+	List(filter service.DeadLetterFilter) []*service.DeadLetterEntry
+}
+
+// ReplayerInterface определяет интерфейс повторной публикации сообщений dead letter topic
+type ReplayerInterface interface {
+	Replay(ctx context.Context, req *service.ReplayRequest) (*service.ReplayResult, error)
+	Audit(limit int) ([]service.ReplayAuditRecord, error)
+}
+
+// DeadLetterHandler обрабатывает запросы административного API dead letter topic
+type DeadLetterHandler struct {
+	index    DeadLetterIndexInterface
+	replayer ReplayerInterface
+	logger   *zap.Logger
+}
+
+// NewDeadLetterHandler создает новый экземпляр DeadLetterHandler
+func NewDeadLetterHandler(index DeadLetterIndexInterface, replayer ReplayerInterface, logger *zap.Logger) *DeadLetterHandler {
+	return &DeadLetterHandler{
+		index:    index,
+		replayer: replayer,
+		logger:   logger,
+	}
+}
+
+// ListDeadLetters godoc
+// @Summary List dead-letter messages
+// @Description List dead-letter messages, newest first, filtered by type, error class, service and time range
+// @Tags DeadLetters
+// @Produce json
+// @Param type query string false "Message type"
+// @Param errorClass query string false "Error class"
+// @Param service query string false "Service that dead-lettered the message"
+// @Param from query string false "Failed at or after (RFC3339)"
+// @Param to query string false "Failed before (RFC3339)"
+// @Param limit query int false "Maximum number of messages" default(100)
+// @Success 200 {array} service.DeadLetterEntry
+// @Failure 400 {object} map[string]string
+// @Router /dead-letters [get]
+func (h *DeadLetterHandler) ListDeadLetters(c *gin.Context) {
+	filter, err := parseFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, h.index.List(*filter))
+}
+
+// GetDeadLetter godoc
+// @Summary Get dead-letter message
+// @Description Get a dead-letter message with its headers and original value
+// @Tags DeadLetters
+// @Produce json
+// @Param id path string true "Dead-letter message ID (partition-offset)"
+// @Success 200 {object} service.DeadLetterEntry
+// @Failure 404 {object} map[string]string
+// @Router /dead-letters/{id} [get]
+func (h *DeadLetterHandler) GetDeadLetter(c *gin.Context) {
+	entry, ok := h.index.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Dead-letter message not found"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// ReplayDeadLetters godoc
+// @Summary Replay dead-letter messages
+// @Description Publish selected or filtered dead-letter messages back to their topics, optionally with edited payloads
+// @Tags DeadLetters
+// @Accept json
+// @Produce json
+// @Param request body service.ReplayRequest true "Messages to replay"
+// @Success 200 {object} service.ReplayResult
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /dead-letters/replay [post]
+func (h *DeadLetterHandler) ReplayDeadLetters(c *gin.Context) {
+	var req service.ReplayRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid replay request"})
+		return
+	}
+	if req.Actor == "" {
+		req.Actor = c.ClientIP()
+	}
+
+	result, err := h.replayer.Replay(c.Request.Context(), &req)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, result)
+	case errors.Is(err, service.ErrNothingToReplay):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrReplaySelection), errors.Is(err, service.ErrEditNotSelected),
+		errors.Is(err, service.ErrInvalidEdit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Failed to replay dead-letter messages", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to replay messages"})
+	}
+}
+
+// ReplayAudit godoc
+// @Summary Replay audit log
+// @Description Get replay operations, newest first
+// @Tags DeadLetters
+// @Produce json
+// @Param limit query int false "Maximum number of records" default(100)
+// @Success 200 {array} service.ReplayAuditRecord
+// @Failure 500 {object} map[string]string
+// @Router /dead-letters/audit [get]
+func (h *DeadLetterHandler) ReplayAudit(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+		return
+	}
+
+	records, err := h.replayer.Audit(limit)
+	if err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Failed to read replay audit", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// parseFilter разбирает фильтр dead letter сообщений из параметров запроса
+func parseFilter(c *gin.Context) (*service.DeadLetterFilter, error) {
+	filter := &service.DeadLetterFilter{
+		Type:       c.Query("type"),
+		ErrorClass: c.Query("errorClass"),
+		Service:    c.Query("service"),
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil {
+		return nil, errors.New("invalid limit")
+	}
+	filter.Limit = limit
+
+	if from := c.Query("from"); from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return nil, errors.New("invalid from: expected RFC3339 time")
+		}
+	}
+	if to := c.Query("to"); to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return nil, errors.New("invalid to: expected RFC3339 time")
+		}
+	}
+	return filter, nil
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"kafka-notification-system/cmd/notification-service/internal/service"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// PolicyStoreInterface определяет интерфейс хранилища политик эскалации
+type PolicyStoreInterface interface {
+	List() []*shared.EscalationPolicy
+	Get(name string) (*shared.EscalationPolicy, error)
+	Put(name string, p *shared.EscalationPolicy) (*shared.EscalationPolicy, error)
+	Delete(name string) error
+}
+
+// EscalatorInterface определяет интерфейс чтения и подтверждения эскалаций
+type EscalatorInterface interface {
+	List() []service.Escalation
+	Get(messageID string) (*service.Escalation, error)
+	Ack(messageID, actor string) (*service.Escalation, error)
+}
+
+// AckRequest представляет подтверждение получения уведомления
+type AckRequest struct {
+	Actor string `json:"actor,omitempty" example:"jane"`
+}
+
+// EscalationHandler обрабатывает запросы к политикам эскалации и подтверждениям уведомлений
+type EscalationHandler struct {
+	policies  PolicyStoreInterface
+	escalator EscalatorInterface
+	logger    *zap.Logger
+}
+
+// NewEscalationHandler создает новый экземпляр EscalationHandler
+func NewEscalationHandler(policies PolicyStoreInterface, escalator EscalatorInterface, logger *zap.Logger) *EscalationHandler {
+	return &EscalationHandler{
+		policies:  policies,
+		escalator: escalator,
+		logger:    logger,
+	}
+}
+
+// ListPolicies godoc
+// @Summary List escalation policies
+// @Description Get all escalation policies
+// @Tags Escalation
+// @Produce json
+// @Success 200 {array} shared.EscalationPolicy
+// @Router /escalation-policies [get]
+func (h *EscalationHandler) ListPolicies(c *gin.Context) {
+	c.JSON(http.StatusOK, h.policies.List())
+}
+
+// GetPolicy godoc
+// @Summary Get escalation policy
+// @Description Get an escalation policy with its steps
+// @Tags Escalation
+// @Produce json
+// @Param name path string true "Policy name"
+// @Success 200 {object} shared.EscalationPolicy
+// @Failure 404 {object} map[string]string
+// @Router /escalation-policies/{name} [get]
+func (h *EscalationHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.policies.Get(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, policy)
+}
+
+// PutPolicy godoc
+// @Summary Create or replace escalation policy
+// @Description Define ordered escalation steps: channel, optional contacts and acknowledgment timeout
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param name path string true "Policy name"
+// @Param policy body shared.EscalationPolicy true "Escalation policy"
+// @Success 200 {object} shared.EscalationPolicy
+// @Failure 400 {object} map[string]string
+// @Router /escalation-policies/{name} [put]
+func (h *EscalationHandler) PutPolicy(c *gin.Context) {
+	var req shared.EscalationPolicy
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid escalation policy format"})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	policy, err := h.policies.Put(c.Param("name"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Escalation policy saved",
+		zap.String("policy", policy.Name),
+		zap.Int("steps", len(policy.Steps)))
+	c.JSON(http.StatusOK, policy)
+}
+
+// DeletePolicy godoc
+// @Summary Delete escalation policy
+// @Description Remove an escalation policy, active escalations using it end as exhausted at their next step
+// @Tags Escalation
+// @Param name path string true "Policy name"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /escalation-policies/{name} [delete]
+func (h *EscalationHandler) DeletePolicy(c *gin.Context) {
+	if err := h.policies.Delete(c.Param("name")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Escalation policy deleted", zap.String("policy", c.Param("name")))
+	c.Status(http.StatusNoContent)
+}
+
+// ListEscalations godoc
+// @Summary List escalations
+// @Description List escalated notifications with their state and delivery attempts, newest first
+// @Tags Escalation
+// @Produce json
+// @Success 200 {array} service.Escalation
+// @Router /escalations [get]
+func (h *EscalationHandler) ListEscalations(c *gin.Context) {
+	c.JSON(http.StatusOK, h.escalator.List())
+}
+
+// GetEscalation godoc
+// @Summary Get escalation
+// @Description Get escalation state and delivery attempts of a notification
+// @Tags Escalation
+// @Produce json
+// @Param id path string true "Message ID"
+// @Success 200 {object} service.Escalation
+// @Failure 404 {object} map[string]string
+// @Router /escalations/{id} [get]
+func (h *EscalationHandler) GetEscalation(c *gin.Context) {
+	escalation, err := h.escalator.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, escalation)
+}
+
+// Ack godoc
+// @Summary Acknowledge notification
+// @Description Acknowledge an escalated notification and stop further escalation steps
+// @Tags Escalation
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Param request body AckRequest false "Who acknowledged the notification"
+// @Success 200 {object} service.Escalation
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /notifications/{id}/ack [post]
+func (h *EscalationHandler) Ack(c *gin.Context) {
+	var req AckRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid acknowledgment format"})
+			return
+		}
+	}
+	if req.Actor == "" {
+		req.Actor = c.ClientIP()
+	}
+
+	escalation, err := h.escalator.Ack(c.Param("id"), req.Actor)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, escalation)
+}
+
+// respondError преобразует ошибку хранилища в HTTP ответ
+func (h *EscalationHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrPolicyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalation policy not found"})
+	case errors.Is(err, service.ErrEscalationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Escalation not found"})
+	case errors.Is(err, service.ErrEscalationNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Escalation store error", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
+	}
+}
@@ -3,31 +3,81 @@ package handler
 import (
 	"net/http"
 
-	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/cmd/notification-service/internal/service"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
+// StatsProviderInterface определяет интерфейс получения счетчиков обработки уведомлений
+type StatsProviderInterface interface {
+	Snapshot() map[string]int64
+}
+
+// BreakerProviderInterface определяет интерфейс получения состояния circuit breaker каналов
+type BreakerProviderInterface interface {
+	Breakers() []service.BreakerStatus
+}
+
+// HealthResponse представляет ответ health check Notification Service.
+// Статус degraded означает, что часть каналов доставки отключена circuit breaker
+type HealthResponse struct {
+	Status   string                  `json:"status"`
+	Breakers []service.BreakerStatus `json:"breakers"`
+}
+
 // NotificationHandler обрабатывает HTTP запросы для Notification Service
 type NotificationHandler struct {
-	logger *zap.Logger
+	stats    StatsProviderInterface
+	breakers BreakerProviderInterface
+	logger   *zap.Logger
 }
 
 // NewNotificationHandler создает новый экземпляр NotificationHandler
-func NewNotificationHandler(logger *zap.Logger) *NotificationHandler {
+func NewNotificationHandler(stats StatsProviderInterface, breakers BreakerProviderInterface, logger *zap.Logger) *NotificationHandler {
 	return &NotificationHandler{
-		logger: logger,
+		stats:    stats,
+		breakers: breakers,
+		logger:   logger,
 	}
 }
 
+// Stats godoc
+// @Summary Delivery statistics
+// @Description Get notification counters by status: delivered, suppressed, deferred, digested, escalated, expired, retried, failed
+// @Tags Notifications
+// @Produce json
+// @Success 200 {object} map[string]int64
+// @Router /stats [get]
+func (h *NotificationHandler) Stats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.stats.Snapshot())
+}
+
+// Breakers godoc
+// @Summary Circuit breaker state
+// @Description Get circuit breaker state of every delivery channel with open and rejection counters
+// @Tags Notifications
+// @Produce json
+// @Success 200 {array} service.BreakerStatus
+// @Router /breakers [get]
+func (h *NotificationHandler) Breakers(c *gin.Context) {
+	c.JSON(http.StatusOK, h.breakers.Breakers())
+}
+
 // Health godoc
 // @Summary Health check
-// @Description Get the health status of the notification service
+// @Description Get the health status of the notification service and its delivery channels
 // @Tags Health
 // @Produce json
-// @Success 200 {object} shared.HealthResponse
+// @Success 200 {object} HealthResponse
 // @Router /health [get]
 func (h *NotificationHandler) Health(c *gin.Context) {
-	c.JSON(http.StatusOK, shared.HealthResponse{Status: "ok"})
+	response := HealthResponse{Status: "ok", Breakers: h.breakers.Breakers()}
+	for _, breaker := range response.Breakers {
+		if breaker.State != service.BreakerClosed {
+			response.Status = "degraded"
+			break
+		}
+	}
+	c.JSON(http.StatusOK, response)
 }
@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+
+	"kafka-notification-system/cmd/notification-service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// ScheduledListerInterface определяет интерфейс чтения отложенных уведомлений
+type ScheduledListerInterface interface {
+	List() []service.ScheduledItem
+}
+
+// SuppressedListerInterface определяет интерфейс чтения подавленных уведомлений
+type SuppressedListerInterface interface {
+	List() []service.SuppressedItem
+}
+
+// QueueHandler обрабатывает запросы административного API к очередям доставки
+type QueueHandler struct {
+	scheduled  ScheduledListerInterface
+	suppressed SuppressedListerInterface
+	logger     *zap.Logger
+}
+
+// NewQueueHandler создает новый экземпляр QueueHandler
+func NewQueueHandler(scheduled ScheduledListerInterface, suppressed SuppressedListerInterface, logger *zap.Logger) *QueueHandler {
+	return &QueueHandler{
+		scheduled:  scheduled,
+		suppressed: suppressed,
+		logger:     logger,
+	}
+}
+
+// ListScheduled godoc
+// @Summary List deferred notifications
+// @Description List notifications deferred by recipient quiet hours, ordered by due time
+// @Tags Queues
+// @Produce json
+// @Success 200 {array} service.ScheduledItem
+// @Router /scheduled [get]
+func (h *QueueHandler) ListScheduled(c *gin.Context) {
+	c.JSON(http.StatusOK, h.scheduled.List())
+}
+
+// ListSuppressed godoc
+// @Summary List suppressed notifications
+// @Description List recently suppressed notifications, newest first. The list is kept in memory
+// @Tags Queues
+// @Produce json
+// @Success 200 {array} service.SuppressedItem
+// @Router /suppressed [get]
+func (h *QueueHandler) ListSuppressed(c *gin.Context) {
+	c.JSON(http.StatusOK, h.suppressed.List())
+}
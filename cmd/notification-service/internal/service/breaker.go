@@ -0,0 +1,266 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+// Состояния circuit breaker канала доставки
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+// breakerStateValues сопоставляет состояния значениям метрики состояния
+var breakerStateValues = map[string]float64{
+	BreakerClosed:   metrics.BreakerStateClosed,
+	BreakerHalfOpen: metrics.BreakerStateHalfOpen,
+	BreakerOpen:     metrics.BreakerStateOpen,
+}
+
+// ErrCircuitOpen возвращается, если канал доставки отключен circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError описывает отключенный канал и момент следующей пробной отправки
+type CircuitOpenError struct {
+	Channel string
+	RetryAt time.Time
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("%s channel: %v until %s", e.Channel, ErrCircuitOpen, e.RetryAt.Format(time.RFC3339))
+}
+
+// Unwrap позволяет проверять ошибку через errors.Is(err, ErrCircuitOpen)
+func (e *CircuitOpenError) Unwrap() error {
+	return ErrCircuitOpen
+}
+
+// BreakerStatus описывает состояние circuit breaker канала
+type BreakerStatus struct {
+	Channel  string `json:"channel"`
+	State    string `json:"state"`
+	Failures int    `json:"failures"`
+	OpenedAt int64  `json:"openedAt,omitempty"`
+	RetryAt  int64  `json:"retryAt,omitempty"`
+	Opens    int64  `json:"opens"`
+	Rejected int64  `json:"rejected"`
+}
+
+// CircuitBreaker отключает канал доставки после серии ошибок подряд. Через
+// OpenTimeout канал переходит в half-open и пропускает пробные отправки:
+// их успех включает канал, ошибка снова отключает. Состояние, отключения и
+// отклоненные отправки экспортируются в метрики, а смены состояния передаются
+// наблюдателю, заданному через Watch
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	channel   string
+	state     string
+	failures  int
+	successes int
+	probes    int
+	openedAt  time.Time
+	timer     *time.Timer
+	watch     func(channel, state string)
+	opens     int64
+	rejected  int64
+	config    config.BreakerConfig
+	now       func() time.Time
+	metrics   *metrics.Metrics
+	logger    *zap.Logger
+}
+
+// NewCircuitBreaker создает новый экземпляр CircuitBreaker для канала
+func NewCircuitBreaker(channel string, cfg *config.BreakerConfig, m *metrics.Metrics) *CircuitBreaker {
+	b := &CircuitBreaker{
+		channel: channel,
+		config:  *cfg,
+		now:     time.Now,
+		metrics: m,
+		logger:  logger.GetLogger(),
+	}
+	b.setState(BreakerClosed)
+	return b
+}
+
+// Allow разрешает отправку или возвращает *CircuitOpenError. Каждая
+// разрешенная отправка должна завершиться вызовом Record
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	b.halfOpen(now)
+
+	switch b.state {
+	case BreakerClosed:
+		return nil
+	case BreakerHalfOpen:
+		if b.probes < b.config.HalfOpenRequests-b.successes {
+			b.probes++
+			return nil
+		}
+	}
+
+	b.rejected++
+	b.metrics.BreakerRejected(b.channel)
+	retryAt := b.openedAt.Add(b.config.OpenTimeout)
+	if b.state == BreakerHalfOpen {
+		// Пробные отправки уже идут: при их неудаче канал снова отключится
+		// на OpenTimeout, поэтому раньше повторять нет смысла
+		retryAt = now.Add(b.config.OpenTimeout)
+	}
+	return &CircuitOpenError{Channel: b.channel, RetryAt: retryAt}
+}
+
+// halfOpen переводит отключенный канал в half-open, если OpenTimeout истек.
+// Вызывается под блокировкой
+func (b *CircuitBreaker) halfOpen(now time.Time) {
+	if b.state != BreakerOpen || now.Before(b.openedAt.Add(b.config.OpenTimeout)) {
+		return
+	}
+	b.setState(BreakerHalfOpen)
+	b.successes = 0
+	b.probes = 0
+	b.logger.Info("Circuit breaker half-open", zap.String("channel", b.channel))
+}
+
+// Watch задает наблюдателя смены состояния. Наблюдатель вызывается под
+// блокировкой circuit breaker и не должен обращаться к нему
+func (b *CircuitBreaker) Watch(watch func(channel, state string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.watch = watch
+}
+
+// Record учитывает результат отправки, разрешенной Allow. Ошибкой канала
+// считаются только сбои транспорта, ответы 5xx и таймауты: отмена контекста
+// и отказ конкретному получателю (ErrRecipientRejected) показывают, что канал
+// работает, и учитываются как успех
+func (b *CircuitBreaker) Record(err error) {
+	if errors.Is(err, context.Canceled) || errors.Is(err, ErrRecipientRejected) {
+		err = nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerClosed:
+		if err == nil {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.config.FailureThreshold {
+			b.trip(err)
+		}
+	case BreakerHalfOpen:
+		b.probes--
+		if err != nil {
+			b.failures++
+			b.trip(err)
+			return
+		}
+		b.successes++
+		if b.successes >= b.config.HalfOpenRequests {
+			b.setState(BreakerClosed)
+			b.failures = 0
+			b.logger.Info("Circuit breaker closed", zap.String("channel", b.channel))
+		}
+	}
+}
+
+// trip отключает канал; вызывается под блокировкой
+func (b *CircuitBreaker) trip(cause error) {
+	b.setState(BreakerOpen)
+	b.openedAt = b.now()
+	b.opens++
+	// Канал переходит в half-open по таймеру, даже если отправок нет,
+	// например пока потребление приостановлено из-за отключенного канала
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	b.timer = time.AfterFunc(b.config.OpenTimeout, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.halfOpen(b.now())
+	})
+	b.metrics.BreakerOpened(b.channel)
+	b.logger.Warn("Circuit breaker opened",
+		zap.String("channel", b.channel),
+		zap.Int("failures", b.failures),
+		zap.Duration("openTimeout", b.config.OpenTimeout),
+		zap.Error(cause))
+}
+
+// setState меняет состояние и метрику состояния и сообщает наблюдателю;
+// вызывается под блокировкой
+func (b *CircuitBreaker) setState(state string) {
+	changed := b.state != state
+	b.state = state
+	b.metrics.SetBreakerState(b.channel, breakerStateValues[state])
+	if changed && b.watch != nil {
+		b.watch(b.channel, state)
+	}
+}
+
+// Status возвращает текущее состояние circuit breaker
+func (b *CircuitBreaker) Status() BreakerStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	status := BreakerStatus{
+		Channel:  b.channel,
+		State:    b.state,
+		Failures: b.failures,
+		Opens:    b.opens,
+		Rejected: b.rejected,
+	}
+	if b.state != BreakerClosed {
+		status.OpenedAt = b.openedAt.UnixMilli()
+		status.RetryAt = b.openedAt.Add(b.config.OpenTimeout).UnixMilli()
+	}
+	return status
+}
+
+// BreakerNotifier оборачивает канал доставки в circuit breaker
+type BreakerNotifier struct {
+	Notifier
+	breaker *CircuitBreaker
+}
+
+// NewBreakerNotifier создает новый экземпляр BreakerNotifier
+func NewBreakerNotifier(notifier Notifier, cfg *config.BreakerConfig, m *metrics.Metrics) *BreakerNotifier {
+	return &BreakerNotifier{
+		Notifier: notifier,
+		breaker:  NewCircuitBreaker(notifier.Channel(), cfg, m),
+	}
+}
+
+// Notify отправляет уведомление, если канал не отключен
+func (n *BreakerNotifier) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	if err := n.breaker.Allow(); err != nil {
+		return err
+	}
+
+	err := n.Notifier.Notify(ctx, contacts, text)
+	n.breaker.Record(err)
+	return err
+}
+
+// Breaker возвращает circuit breaker канала
+func (n *BreakerNotifier) Breaker() *CircuitBreaker {
+	return n.breaker
+}
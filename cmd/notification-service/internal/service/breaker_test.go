@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/consumer"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/shared"
+)
+
+// newTestBreakerNotifier оборачивает канал в circuit breaker с управляемыми часами
+func newTestBreakerNotifier(notifier Notifier, now *time.Time) *BreakerNotifier {
+	n := NewBreakerNotifier(notifier, &config.BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, nil)
+	n.breaker.now = func() time.Time { return *now }
+	return n
+}
+
+func TestCircuitBreaker_Transitions(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	telegram := &mockNotifier{channel: shared.ChannelTelegram, err: errors.New("telegram is down")}
+	notifier := newTestBreakerNotifier(telegram, &now)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := notifier.Notify(ctx, nil, "hello"); errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("Expected breaker to stay closed on failure %d", i+1)
+		}
+	}
+	if state := notifier.Breaker().Status().State; state != BreakerOpen {
+		t.Fatalf("Expected breaker to open after threshold, got %s", state)
+	}
+
+	var circuitErr *CircuitOpenError
+	if err := notifier.Notify(ctx, nil, "hello"); !errors.As(err, &circuitErr) {
+		t.Fatalf("Expected circuit open error, got %v", err)
+	}
+	if !circuitErr.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected retry at %v, got %v", now.Add(time.Minute), circuitErr.RetryAt)
+	}
+
+	// Неудачная пробная отправка снова отключает канал
+	now = now.Add(time.Minute)
+	if err := notifier.Notify(ctx, nil, "hello"); errors.Is(err, ErrCircuitOpen) {
+		t.Fatal("Expected half-open breaker to allow a probe")
+	}
+	if state := notifier.Breaker().Status().State; state != BreakerOpen {
+		t.Fatalf("Expected failed probe to reopen breaker, got %s", state)
+	}
+
+	// Успешная пробная отправка включает канал
+	now = now.Add(time.Minute)
+	telegram.err = nil
+	if err := notifier.Notify(ctx, nil, "hello"); err != nil {
+		t.Fatalf("Unexpected probe error: %v", err)
+	}
+
+	status := notifier.Breaker().Status()
+	if status.State != BreakerClosed {
+		t.Errorf("Expected breaker to close after successful probe, got %s", status.State)
+	}
+	if status.Opens != 2 || status.Rejected != 1 {
+		t.Errorf("Expected 2 opens and 1 rejection, got %d and %d", status.Opens, status.Rejected)
+	}
+}
+
+func TestCircuitBreaker_HalfOpenLimitsProbes(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	breaker := NewCircuitBreaker(shared.ChannelTelegram, &config.BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, nil)
+	breaker.now = func() time.Time { return now }
+
+	breaker.Record(errors.New("timeout"))
+	now = now.Add(time.Minute)
+
+	if err := breaker.Allow(); err != nil {
+		t.Fatalf("Expected first probe to be allowed, got %v", err)
+	}
+	var circuitErr *CircuitOpenError
+	if err := breaker.Allow(); !errors.As(err, &circuitErr) {
+		t.Fatalf("Expected concurrent probe to be rejected, got %v", err)
+	}
+	// Отклоненная отправка откладывается на OpenTimeout, а не на текущий момент
+	if !circuitErr.RetryAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("Expected retry at %v, got %v", now.Add(time.Minute), circuitErr.RetryAt)
+	}
+}
+
+func TestKafkaService_PausesWhileCircuitOpen(t *testing.T) {
+	telegram := NewBreakerNotifier(&mockNotifier{channel: shared.ChannelTelegram}, &config.BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      20 * time.Millisecond,
+		HalfOpenRequests: 1,
+	}, nil)
+	service := newTestKafkaService(telegram)
+	service.dispatcher.WatchBreakers(service.circuitChanged)
+
+	telegram.Breaker().Record(errors.New("telegram is down"))
+	status := service.Control().Status()
+	if status.State != consumer.StatePaused || len(status.Holds) != 1 {
+		t.Fatalf("Expected consumption to be held while circuit is open, got %+v", status)
+	}
+
+	// По истечении OpenTimeout канал переходит в half-open без отправок,
+	// и чтение возобновляется для пробных отправок
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := service.Control().Wait(ctx); err != nil {
+		t.Fatalf("Expected consumption to resume on half-open, got %v", err)
+	}
+	if state := telegram.Breaker().Status().State; state != BreakerHalfOpen {
+		t.Errorf("Expected half-open breaker, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_IgnoresRecipientErrors(t *testing.T) {
+	breaker := NewCircuitBreaker(shared.ChannelWebhook, &config.BreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, nil)
+
+	for i := 0; i < 3; i++ {
+		breaker.Record(&RecipientError{Err: errors.New("webhook returned status 404")})
+	}
+	if state := breaker.Status().State; state != BreakerClosed {
+		t.Errorf("Expected recipient errors to keep breaker closed, got %s", state)
+	}
+}
+
+func TestCircuitBreaker_ExportsMetrics(t *testing.T) {
+	m := metrics.New("notification-service")
+	breaker := NewCircuitBreaker(shared.ChannelTelegram, &config.BreakerConfig{
+		FailureThreshold: 1,
+		OpenTimeout:      time.Minute,
+		HalfOpenRequests: 1,
+	}, m)
+
+	breaker.Record(errors.New("timeout"))
+	breaker.Allow()
+
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	for _, line := range []string{
+		`notification_system_circuit_breaker_state{channel="telegram",service="notification-service"} 2`,
+		`notification_system_circuit_breaker_opened_total{channel="telegram",service="notification-service"} 1`,
+		`notification_system_circuit_breaker_rejected_total{channel="telegram",service="notification-service"} 1`,
+	} {
+		if !strings.Contains(string(body), line) {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
+
+func TestWebhookService_Notify_ClassifiesRecipientErrors(t *testing.T) {
+	tests := []struct {
+		status    int
+		recipient bool
+	}{
+		{http.StatusNotFound, true},
+		{http.StatusGone, true},
+		{http.StatusTooManyRequests, false},
+		{http.StatusServiceUnavailable, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookService(time.Second).Notify(context.Background(),
+				&shared.ContactPoints{WebhookURL: server.URL}, "hello")
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if errors.Is(err, ErrRecipientRejected) != tt.recipient {
+				t.Errorf("Expected recipient error %v, got %v", tt.recipient, err)
+			}
+		})
+	}
+}
+
+func TestDispatcher_Dispatch_FallsBackFromOpenChannel(t *testing.T) {
+	now := time.Now()
+	telegram := newTestBreakerNotifier(&mockNotifier{channel: shared.ChannelTelegram, err: errors.New("down")}, &now)
+	telegram.Breaker().Record(errors.New("down"))
+	telegram.Breaker().Record(errors.New("down"))
+	email := &mockNotifier{channel: shared.ChannelEmail}
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {
+			ID:       "user-1",
+			Contacts: shared.ContactPoints{TelegramChatID: 123, Email: "user@example.com"},
+			Channels: []string{shared.ChannelTelegram, shared.ChannelEmail},
+		},
+	}}
+	dispatcher := NewDispatcher(resolver, nil, telegram, email)
+
+	result, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{UserID: "user-1", Text: "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Channel != shared.ChannelEmail || len(email.sent) != 1 {
+		t.Errorf("Expected delivery through email, got %s", result.Channel)
+	}
+
+	breakers := dispatcher.Breakers()
+	if len(breakers) != 1 || breakers[0].State != BreakerOpen {
+		t.Errorf("Expected open telegram breaker in status, got %+v", breakers)
+	}
+}
+
+func TestKafkaService_ProcessNotification_DefersWhenCircuitOpen(t *testing.T) {
+	now := time.Now()
+	telegram := newTestBreakerNotifier(&mockNotifier{channel: shared.ChannelTelegram}, &now)
+	telegram.Breaker().Record(errors.New("down"))
+	telegram.Breaker().Record(errors.New("down"))
+
+	scheduler, err := NewScheduler(filepath.Join(t.TempDir(), "scheduled.json"), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	service := newTestKafkaService(telegram)
+	service.scheduler = scheduler
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "hello"})
+	if err := service.processNotification(context.Background(), message); err != nil {
+		t.Fatalf("Expected notification to be deferred without error, got %v", err)
+	}
+
+	items := scheduler.List()
+	if len(items) != 1 || items[0].Message.ID != message.ID {
+		t.Fatalf("Expected notification to be scheduled, got %+v", items)
+	}
+	if items[0].DueAt != now.Add(time.Minute).UnixMilli() {
+		t.Errorf("Expected due at breaker retry time, got %d", items[0].DueAt)
+	}
+	if count := service.Stats().Snapshot()[StatusDeferred]; count != 1 {
+		t.Errorf("Expected 1 deferred notification, got %d", count)
+	}
+}
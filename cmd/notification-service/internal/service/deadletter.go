@@ -0,0 +1,336 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// DeadLetterEntry представляет сообщение из dead letter topic.
+// Идентификатор записи составлен из партиции и offset: "0-42"
+type DeadLetterEntry struct {
+	ID           string            `json:"id"`
+	Partition    int               `json:"partition"`
+	Offset       int64             `json:"offset"`
+	Type         string            `json:"type,omitempty"`
+	ErrorClass   string            `json:"errorClass,omitempty"`
+	ErrorMessage string            `json:"errorMessage,omitempty"`
+	Service      string            `json:"service,omitempty"`
+	Attempts     int               `json:"attempts,omitempty"`
+	ReplayCount  int               `json:"replayCount,omitempty"`
+	FailedAt     int64             `json:"failedAt"`
+	Key          string            `json:"key,omitempty"`
+	Headers      map[string]string `json:"headers,omitempty"`
+	Message      json.RawMessage   `json:"message,omitempty"`
+	RawValue     string            `json:"rawValue,omitempty"`
+
+	headers []kafka.Header
+	value   []byte
+}
+
+// DeadLetterFilter задает условия отбора сообщений dead letter topic.
+// Пустые поля не ограничивают выборку
+type DeadLetterFilter struct {
+	Type       string    `json:"type,omitempty"`
+	ErrorClass string    `json:"errorClass,omitempty"`
+	Service    string    `json:"service,omitempty"`
+	From       time.Time `json:"from,omitempty"`
+	To         time.Time `json:"to,omitempty"`
+	Limit      int       `json:"limit,omitempty"`
+}
+
+// Match проверяет, что запись удовлетворяет фильтру
+func (f *DeadLetterFilter) Match(e *DeadLetterEntry) bool {
+	if f.Type != "" && e.Type != f.Type {
+		return false
+	}
+	if f.ErrorClass != "" && e.ErrorClass != f.ErrorClass {
+		return false
+	}
+	if f.Service != "" && e.Service != f.Service {
+		return false
+	}
+	failedAt := time.UnixMilli(e.FailedAt)
+	if !f.From.IsZero() && failedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !failedAt.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// IsEmpty проверяет, что фильтр не задает ни одного условия отбора
+func (f *DeadLetterFilter) IsEmpty() bool {
+	return f.Type == "" && f.ErrorClass == "" && f.Service == "" && f.From.IsZero() && f.To.IsZero()
+}
+
+// DeadLetterIndex хранит в памяти сообщения dead letter topic для просмотра и повторной публикации.
+// Для каждой партиции хранятся не больше maxEntries последних сообщений не старше maxAge;
+// нулевое значение не ограничивает
+type DeadLetterIndex struct {
+	mu         sync.RWMutex
+	entries    map[string]*DeadLetterEntry
+	partitions map[int][]*DeadLetterEntry
+	maxEntries int
+	maxAge     time.Duration
+	now        func() time.Time
+}
+
+// NewDeadLetterIndex создает новый экземпляр DeadLetterIndex
+func NewDeadLetterIndex(maxEntries int, maxAge time.Duration) *DeadLetterIndex {
+	return &DeadLetterIndex{
+		entries:    make(map[string]*DeadLetterEntry),
+		partitions: make(map[int][]*DeadLetterEntry),
+		maxEntries: maxEntries,
+		maxAge:     maxAge,
+		now:        time.Now,
+	}
+}
+
+// Add добавляет сообщение dead letter topic в индекс и вытесняет старые записи его партиции
+func (idx *DeadLetterIndex) Add(message kafka.Message) *DeadLetterEntry {
+	entry := newDeadLetterEntry(message)
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if _, ok := idx.entries[entry.ID]; !ok {
+		idx.partitions[entry.Partition] = append(idx.partitions[entry.Partition], entry)
+	}
+	idx.entries[entry.ID] = entry
+	idx.evict(entry.Partition)
+	return entry
+}
+
+// evict удаляет записи партиции сверх maxEntries и старше maxAge. Записи
+// партиции хранятся в порядке offset, поэтому вытесняются с начала.
+// Вызывается под блокировкой
+func (idx *DeadLetterIndex) evict(partition int) {
+	entries := idx.partitions[partition]
+	var cutoff int64
+	if idx.maxAge > 0 {
+		cutoff = idx.now().Add(-idx.maxAge).UnixMilli()
+	}
+
+	evict := 0
+	for evict < len(entries) {
+		entry := entries[evict]
+		if (idx.maxEntries <= 0 || len(entries)-evict <= idx.maxEntries) && entry.FailedAt >= cutoff {
+			break
+		}
+		delete(idx.entries, entry.ID)
+		evict++
+	}
+	idx.partitions[partition] = entries[evict:]
+}
+
+// Get возвращает запись по идентификатору
+func (idx *DeadLetterIndex) Get(id string) (*DeadLetterEntry, bool) {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+
+	entry, ok := idx.entries[id]
+	return entry, ok
+}
+
+// List возвращает записи, удовлетворяющие фильтру, начиная с самых новых
+func (idx *DeadLetterIndex) List(filter DeadLetterFilter) []*DeadLetterEntry {
+	idx.mu.RLock()
+	result := make([]*DeadLetterEntry, 0)
+	for _, entry := range idx.entries {
+		if filter.Match(entry) {
+			result = append(result, entry)
+		}
+	}
+	idx.mu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].FailedAt != result[j].FailedAt {
+			return result[i].FailedAt > result[j].FailedAt
+		}
+		return result[i].ID > result[j].ID
+	})
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result
+}
+
+// newDeadLetterEntry разбирает сообщение dead letter topic: метаданные ошибки
+// берутся из заголовков, тип — из заголовка или конверта сообщения
+func newDeadLetterEntry(message kafka.Message) *DeadLetterEntry {
+	entry := &DeadLetterEntry{
+		ID:           fmt.Sprintf("%d-%d", message.Partition, message.Offset),
+		Partition:    message.Partition,
+		Offset:       message.Offset,
+		Type:         headerValue(message.Headers, shared.HeaderMessageType),
+		ErrorClass:   headerValue(message.Headers, shared.HeaderErrorClass),
+		ErrorMessage: headerValue(message.Headers, shared.HeaderErrorMessage),
+		Service:      headerValue(message.Headers, shared.HeaderErrorService),
+		Key:          string(message.Key),
+		Headers:      make(map[string]string, len(message.Headers)),
+		headers:      message.Headers,
+		value:        message.Value,
+	}
+
+	for _, h := range message.Headers {
+		entry.Headers[h.Key] = string(h.Value)
+	}
+	entry.Attempts, _ = strconv.Atoi(entry.Headers[shared.HeaderErrorAttempts])
+	entry.ReplayCount, _ = strconv.Atoi(entry.Headers[shared.HeaderReplayCount])
+
+	entry.FailedAt = message.Time.UnixMilli()
+	if ts, err := strconv.ParseInt(entry.Headers[shared.HeaderErrorTimestamp], 10, 64); err == nil {
+		entry.FailedAt = ts
+	}
+
+	if json.Valid(message.Value) {
+		entry.Message = json.RawMessage(message.Value)
+		if entry.Type == "" {
+			if envelope, err := shared.FromJSON(message.Value); err == nil {
+				entry.Type = envelope.Type
+			}
+		}
+	} else {
+		entry.RawValue = string(message.Value)
+	}
+	return entry
+}
+
+// DeadLetterConsumer читает хвост dead letter topic в пределах ограничений индекса
+// и наполняет индекс. Consumer group не используется: каждая копия сервиса видит все сообщения
+type DeadLetterConsumer struct {
+	brokers []string
+	topic   string
+	index   *DeadLetterIndex
+	logger  *zap.Logger
+}
+
+// NewDeadLetterConsumer создает новый экземпляр DeadLetterConsumer
+func NewDeadLetterConsumer(brokers []string, topic string, index *DeadLetterIndex) *DeadLetterConsumer {
+	return &DeadLetterConsumer{
+		brokers: brokers,
+		topic:   topic,
+		index:   index,
+		logger:  logger.GetLogger(),
+	}
+}
+
+// Run читает все партиции dead letter topic до отмены контекста
+func (c *DeadLetterConsumer) Run(ctx context.Context) {
+	partitions, err := c.partitions(ctx)
+	for err != nil {
+		c.logger.Error("Failed to read dead letter topic partitions", zap.String("topic", c.topic), zap.Error(err))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(5 * time.Second):
+		}
+		partitions, err = c.partitions(ctx)
+	}
+
+	var wg sync.WaitGroup
+	for _, partition := range partitions {
+		wg.Add(1)
+		go func(partition int) {
+			defer wg.Done()
+			c.consume(ctx, partition)
+		}(partition)
+	}
+	wg.Wait()
+}
+
+// partitions возвращает номера партиций dead letter topic
+func (c *DeadLetterConsumer) partitions(ctx context.Context) ([]int, error) {
+	conn, err := kafka.DialContext(ctx, "tcp", c.brokers[0])
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(c.topic)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]int, 0, len(partitions))
+	for _, p := range partitions {
+		result = append(result, p.ID)
+	}
+	return result, nil
+}
+
+// consume читает одну партицию, начиная с первого offset, который индекс сохранит
+func (c *DeadLetterConsumer) consume(ctx context.Context, partition int) {
+	reader := kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     c.brokers,
+		Topic:       c.topic,
+		Partition:   partition,
+		StartOffset: kafka.FirstOffset,
+		MaxBytes:    10e6, // 10MB
+	})
+	defer reader.Close()
+
+	if offset, err := c.startOffset(ctx, partition); err != nil {
+		c.logger.Warn("Failed to find dead letter start offset, reading from the beginning",
+			zap.Int("partition", partition), zap.Error(err))
+	} else if err := reader.SetOffset(offset); err != nil {
+		c.logger.Warn("Failed to set dead letter start offset", zap.Int("partition", partition), zap.Error(err))
+	}
+
+	for {
+		message, err := reader.ReadMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Error("Failed to read dead letter message", zap.Int("partition", partition), zap.Error(err))
+			continue
+		}
+		c.index.Add(message)
+	}
+}
+
+// startOffset возвращает offset, с которого партиция укладывается в ограничения
+// индекса: не больше maxEntries последних сообщений и не старше maxAge
+func (c *DeadLetterConsumer) startOffset(ctx context.Context, partition int) (int64, error) {
+	conn, err := kafka.DialLeader(ctx, "tcp", c.brokers[0], c.topic, partition)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, err
+	}
+
+	start := first
+	if c.index.maxEntries > 0 && last-int64(c.index.maxEntries) > start {
+		start = last - int64(c.index.maxEntries)
+	}
+	if c.index.maxAge > 0 {
+		offset, err := conn.ReadOffset(time.Now().Add(-c.index.maxAge))
+		if err != nil {
+			return 0, err
+		}
+		// Kafka возвращает -1, если в партиции нет сообщений новее метки
+		if offset < 0 {
+			offset = last
+		}
+		if offset > start {
+			start = offset
+		}
+	}
+	return start, nil
+}
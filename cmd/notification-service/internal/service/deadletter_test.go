@@ -0,0 +1,128 @@
+package service
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// deadLetterMessage создает сообщение dead letter topic с метаданными ошибки
+func deadLetterMessage(offset int64, messageType, errorClass string, failedAt time.Time, value string) kafka.Message {
+	return kafka.Message{
+		Topic:     "dead-letter",
+		Partition: 0,
+		Offset:    offset,
+		Key:       []byte("key-" + strconv.FormatInt(offset, 10)),
+		Value:     []byte(value),
+		Headers: []kafka.Header{
+			{Key: shared.HeaderMessageType, Value: []byte(messageType)},
+			{Key: shared.HeaderErrorClass, Value: []byte(errorClass)},
+			{Key: shared.HeaderErrorMessage, Value: []byte("delivery failed")},
+			{Key: shared.HeaderErrorService, Value: []byte("notification-service")},
+			{Key: shared.HeaderErrorAttempts, Value: []byte("4")},
+			{Key: shared.HeaderErrorTimestamp, Value: []byte(strconv.FormatInt(failedAt.UnixMilli(), 10))},
+			{Key: shared.HeaderRetryAttempt, Value: []byte("4")},
+		},
+	}
+}
+
+func TestDeadLetterIndex_AddParsesMetadata(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+	failedAt := time.Now().Truncate(time.Millisecond)
+
+	entry := index.Add(deadLetterMessage(7, "notification", "url.Error", failedAt, `{"id":"1"}`))
+
+	if entry.ID != "0-7" {
+		t.Errorf("Expected id 0-7, got %s", entry.ID)
+	}
+	if entry.Type != "notification" || entry.ErrorClass != "url.Error" || entry.Service != "notification-service" {
+		t.Errorf("Unexpected metadata: %+v", entry)
+	}
+	if entry.Attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", entry.Attempts)
+	}
+	if entry.FailedAt != failedAt.UnixMilli() {
+		t.Errorf("Expected failedAt %d, got %d", failedAt.UnixMilli(), entry.FailedAt)
+	}
+	if string(entry.Message) != `{"id":"1"}` || entry.RawValue != "" {
+		t.Errorf("Expected JSON message, got %+v", entry)
+	}
+}
+
+func TestDeadLetterIndex_AddKeepsInvalidValueAsRaw(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+
+	entry := index.Add(deadLetterMessage(1, "", "errors.errorString", time.Now(), "not json"))
+
+	if entry.Message != nil || entry.RawValue != "not json" {
+		t.Errorf("Expected raw value for invalid JSON, got %+v", entry)
+	}
+}
+
+func TestDeadLetterIndex_ListFilters(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+	now := time.Now()
+
+	index.Add(deadLetterMessage(1, "notification", "url.Error", now.Add(-2*time.Hour), "{}"))
+	index.Add(deadLetterMessage(2, "notification", "json.SyntaxError", now.Add(-time.Hour), "{}"))
+	index.Add(deadLetterMessage(3, "broadcast", "url.Error", now, "{}"))
+
+	tests := []struct {
+		name     string
+		filter   DeadLetterFilter
+		expected []string
+	}{
+		{"all newest first", DeadLetterFilter{}, []string{"0-3", "0-2", "0-1"}},
+		{"by type", DeadLetterFilter{Type: "notification"}, []string{"0-2", "0-1"}},
+		{"by error class", DeadLetterFilter{ErrorClass: "url.Error"}, []string{"0-3", "0-1"}},
+		{"by time range", DeadLetterFilter{From: now.Add(-90 * time.Minute), To: now.Add(-time.Minute)}, []string{"0-2"}},
+		{"limit", DeadLetterFilter{Limit: 1}, []string{"0-3"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := index.List(tt.filter)
+			if len(entries) != len(tt.expected) {
+				t.Fatalf("Expected %d entries, got %d", len(tt.expected), len(entries))
+			}
+			for i, id := range tt.expected {
+				if entries[i].ID != id {
+					t.Errorf("Expected entry %d to be %s, got %s", i, id, entries[i].ID)
+				}
+			}
+		})
+	}
+}
+
+func TestDeadLetterIndex_EvictsOldEntries(t *testing.T) {
+	index := NewDeadLetterIndex(2, time.Hour)
+	now := time.Now()
+	index.now = func() time.Time { return now }
+
+	index.Add(deadLetterMessage(1, "notification", "url.Error", now.Add(-2*time.Hour), "{}"))
+	if _, ok := index.Get("0-1"); ok {
+		t.Error("Expected entry older than max age to be evicted")
+	}
+
+	for offset := int64(2); offset <= 4; offset++ {
+		index.Add(deadLetterMessage(offset, "notification", "url.Error", now, "{}"))
+	}
+	if _, ok := index.Get("0-2"); ok {
+		t.Error("Expected oldest entry over max entries to be evicted")
+	}
+	if entries := index.List(DeadLetterFilter{}); len(entries) != 2 {
+		t.Errorf("Expected 2 entries, got %d", len(entries))
+	}
+
+	// Лимит действует на каждую партицию отдельно
+	other := deadLetterMessage(1, "notification", "url.Error", now, "{}")
+	other.Partition = 1
+	index.Add(other)
+	if entries := index.List(DeadLetterFilter{}); len(entries) != 3 {
+		t.Errorf("Expected 3 entries across partitions, got %d", len(entries))
+	}
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/storage"
+)
+
+// Deduplicator помнит идентификаторы обработанных уведомлений в течение окна
+// window, но не больше capacity последних. Повторно опубликованное уведомление,
+// например пакет рассылки после перезапуска Fan-out Service, не доставляется дважды.
+//
+// Идентификаторы дописываются в журнал на диске и загружаются при запуске,
+// поэтому переживают перезапуск сервиса. Журнал локален для экземпляра: повтор
+// распознается, если попадает в ту же партицию и ее читает тот же экземпляр
+type Deduplicator struct {
+	mu       sync.Mutex
+	seen     map[string]time.Time
+	order    []dedupeEntry
+	window   time.Duration
+	capacity int
+	now      func() time.Time
+
+	// log — журнал идентификаторов; nil, если состояние хранится только в памяти
+	log *storage.JSONLines
+	// appended — число записей, дописанных в журнал после последнего сжатия
+	appended int
+}
+
+// dedupeEntry — идентификатор в порядке обработки для вытеснения старых записей
+type dedupeEntry struct {
+	ID string    `json:"id"`
+	At time.Time `json:"at"`
+}
+
+// NewDeduplicator создает новый экземпляр Deduplicator и загружает журнал path.
+// Пустой path хранит идентификаторы только в памяти
+func NewDeduplicator(path string, window time.Duration, capacity int) (*Deduplicator, error) {
+	d := &Deduplicator{
+		seen:     make(map[string]time.Time),
+		window:   window,
+		capacity: capacity,
+		now:      time.Now,
+	}
+	if path == "" {
+		return d, nil
+	}
+
+	d.log = storage.NewJSONLines(path)
+	err := d.log.ReadAll(func(line []byte) error {
+		var entry dedupeEntry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return err
+		}
+		d.seen[entry.ID] = entry.At
+		d.order = append(d.order, entry)
+		d.appended++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	d.evict(d.now())
+	return d, nil
+}
+
+// Seen сообщает, обработано ли уведомление с идентификатором id в пределах окна
+func (d *Deduplicator) Seen(id string) bool {
+	if d == nil || id == "" {
+		return false
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	at, ok := d.seen[id]
+	return ok && d.now().Sub(at) < d.window
+}
+
+// Mark запоминает уведомление с идентификатором id как обработанное и дописывает
+// его в журнал. Ошибка записи журнала не отменяет отметку в памяти
+func (d *Deduplicator) Mark(id string) error {
+	if d == nil || id == "" || d.window <= 0 || d.capacity <= 0 {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	entry := dedupeEntry{ID: id, At: d.now()}
+	d.seen[id] = entry.At
+	d.order = append(d.order, entry)
+	d.evict(entry.At)
+
+	if d.log == nil {
+		return nil
+	}
+	// Журнал сжимается до актуальных записей, когда вырастает вдвое
+	if d.appended++; d.appended > d.capacity {
+		return d.compact()
+	}
+	return d.log.Append(entry)
+}
+
+// evict удаляет записи старше окна и сверх capacity. Вызывается под блокировкой
+func (d *Deduplicator) evict(now time.Time) {
+	evict := 0
+	for evict < len(d.order) && (len(d.order)-evict > d.capacity || now.Sub(d.order[evict].At) >= d.window) {
+		entry := d.order[evict]
+		// Идентификатор мог быть отмечен повторно позже
+		if d.seen[entry.ID].Equal(entry.At) {
+			delete(d.seen, entry.ID)
+		}
+		evict++
+	}
+	// append переносит оставшиеся записи в новый массив при росте, поэтому
+	// вытесненные освобождаются без копирования на каждом вызове
+	d.order = d.order[evict:]
+}
+
+// compact перезаписывает журнал актуальными записями. Вызывается под блокировкой
+func (d *Deduplicator) compact() error {
+	values := make([]interface{}, len(d.order))
+	for i, entry := range d.order {
+		values[i] = entry
+	}
+	if err := d.log.Rewrite(values); err != nil {
+		return err
+	}
+	d.appended = 0
+	return nil
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestDeduplicator(t *testing.T) {
+	now := time.Now()
+	dedupe, _ := NewDeduplicator("", time.Hour, 2)
+	dedupe.now = func() time.Time { return now }
+
+	dedupe.Mark("a")
+	if !dedupe.Seen("a") || dedupe.Seen("b") {
+		t.Fatal("Expected only marked id to be seen")
+	}
+
+	// Сверх capacity вытесняются самые старые
+	dedupe.Mark("b")
+	dedupe.Mark("c")
+	if dedupe.Seen("a") || !dedupe.Seen("b") || !dedupe.Seen("c") {
+		t.Error("Expected oldest id to be evicted over capacity")
+	}
+
+	// Записи старше окна не учитываются
+	now = now.Add(time.Hour)
+	if dedupe.Seen("c") {
+		t.Error("Expected id outside window to be forgotten")
+	}
+	dedupe.Mark("d")
+	if len(dedupe.seen) != 1 {
+		t.Errorf("Expected expired entries to be evicted, got %d", len(dedupe.seen))
+	}
+}
+
+func TestDeduplicator_PersistsAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "dedupe.jsonl")
+	dedupe, err := NewDeduplicator(path, time.Hour, 2)
+	if err != nil {
+		t.Fatalf("Failed to create deduplicator: %v", err)
+	}
+	for _, id := range []string{"a", "b", "c", "d"} {
+		if err := dedupe.Mark(id); err != nil {
+			t.Fatalf("Failed to mark %s: %v", id, err)
+		}
+	}
+
+	// После перезапуска помнятся последние capacity идентификаторов
+	restarted, err := NewDeduplicator(path, time.Hour, 2)
+	if err != nil {
+		t.Fatalf("Failed to load deduplicator: %v", err)
+	}
+	if restarted.Seen("b") || !restarted.Seen("c") || !restarted.Seen("d") {
+		t.Error("Expected last ids to survive restart")
+	}
+
+	// Журнал сжимается и не растет без ограничений
+	lines := 0
+	if err := storage.NewJSONLines(path).ReadAll(func([]byte) error { lines++; return nil }); err != nil {
+		t.Fatalf("Failed to read journal: %v", err)
+	}
+	if lines > 4 {
+		t.Errorf("Expected journal to be compacted, got %d lines", lines)
+	}
+
+	// Записи старше окна при загрузке не учитываются
+	restarted.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	if restarted.Seen("d") {
+		t.Error("Expected id outside window to be forgotten")
+	}
+}
+
+func TestKafkaService_ProcessMessage_SkipsDuplicate(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	service := newTestKafkaService(telegram)
+	service.dedupe, _ = NewDeduplicator("", time.Hour, 100)
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "Broadcast"})
+	value, err := message.ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := service.processMessage(context.Background(), kafka.Message{Value: value}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if len(telegram.sent) != 1 {
+		t.Errorf("Expected duplicate to be skipped, got %d messages sent", len(telegram.sent))
+	}
+	if count := service.Stats().Snapshot()[StatusDuplicate]; count != 1 {
+		t.Errorf("Expected 1 duplicate, got %d", count)
+	}
+}
+
+func TestKafkaService_ProcessMessage_RetryOfDeferredIsNotDuplicate(t *testing.T) {
+	now := time.Now()
+	telegramMock := &mockNotifier{channel: shared.ChannelTelegram}
+	telegram := newTestBreakerNotifier(telegramMock, &now)
+	telegram.Breaker().Record(errors.New("down"))
+	telegram.Breaker().Record(errors.New("down"))
+
+	service, retries, deadLetters := newRetryTestService()
+	service.dispatcher = NewDispatcher(&mockResolver{}, nil, telegram)
+	service.dedupe, _ = NewDeduplicator("", time.Hour, 100)
+	service.config = &config.KafkaConfig{NotificationsTopic: "notifications"}
+	scheduler, err := NewScheduler(filepath.Join(t.TempDir(), "scheduled.json"), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create scheduler: %v", err)
+	}
+	service.scheduler = scheduler
+	ctx := context.Background()
+
+	// Канал отключен: уведомление откладывается
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "hello"})
+	value, _ := message.ToJSON()
+	if err := service.processMessage(ctx, kafka.Message{Topic: "notifications", Value: value}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	items := scheduler.List()
+	if len(items) != 1 {
+		t.Fatalf("Expected deferred notification, got %d", len(items))
+	}
+
+	// Пробная отправка отложенного уведомления не удалась: оно уходит на повторную обработку
+	now = now.Add(time.Minute)
+	telegramMock.err = errors.New("still down")
+	if err := service.ProcessDeferred(ctx, items[0].Message); err != nil {
+		t.Fatalf("Expected notification to be handed off, got %v", err)
+	}
+	if len(retries.messages) != 1 {
+		t.Fatalf("Expected retry message, got %d", len(retries.messages))
+	}
+
+	// Повторная попытка с тем же идентификатором доставляется, а не пропускается как дубликат
+	now = now.Add(time.Minute)
+	telegramMock.err = nil
+	if err := service.processMessage(ctx, retries.messages[0]); err != nil {
+		t.Fatalf("Unexpected retry error: %v", err)
+	}
+	if len(telegramMock.sent) != 1 {
+		t.Errorf("Expected retried notification to be delivered, got %v", telegramMock.sent)
+	}
+	if count := service.Stats().Snapshot()[StatusDuplicate]; count != 0 || len(deadLetters.messages) != 0 {
+		t.Errorf("Expected no duplicates and dead letters, got %d and %d", count, len(deadLetters.messages))
+	}
+}
@@ -0,0 +1,317 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// DigestItem представляет одно уведомление, накопленное в сводке
+type DigestItem struct {
+	MessageID string `json:"messageId"`
+	Text      string `json:"text"`
+	Timestamp int64  `json:"timestamp"`
+	ExpiresAt int64  `json:"expiresAt,omitempty"`
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// DigestBucket накапливает уведомления одного получателя одной категории
+type DigestBucket struct {
+	Key      string       `json:"key"`
+	ChatID   int64        `json:"chatId,omitempty"`
+	UserID   string       `json:"userId,omitempty"`
+	Category string       `json:"category,omitempty"`
+	Channels []string     `json:"channels,omitempty"`
+	Items    []DigestItem `json:"items"`
+	OpenedAt int64        `json:"openedAt"`
+	// flushing отмечает сводку, которая сейчас отправляется, чтобы ее не отправили дважды
+	flushing bool
+}
+
+// DigestFlush отправляет сводку. Сводка удаляется из буфера, только если
+// отправка вернула nil, иначе остается в буфере до следующей попытки
+type DigestFlush func(ctx context.Context, message *shared.KafkaMessage) error
+
+// DigestBuffer группирует уведомления с флагом digest по получателю и категории
+// и выпускает одну сводку по истечении окна или при достижении лимита.
+// Состояние сохраняется на диск после каждого изменения
+type DigestBuffer struct {
+	mu       sync.Mutex
+	buckets  map[string]*DigestBucket
+	file     *storage.JSONFile
+	window   time.Duration
+	maxCount int
+	logger   *zap.Logger
+}
+
+// NewDigestBuffer создает новый экземпляр DigestBuffer и загружает сохраненные сводки
+func NewDigestBuffer(path string, window time.Duration, maxCount int) (*DigestBuffer, error) {
+	b := &DigestBuffer{
+		buckets:  make(map[string]*DigestBucket),
+		file:     storage.NewJSONFile(path),
+		window:   window,
+		maxCount: maxCount,
+		logger:   logger.GetLogger(),
+	}
+
+	if err := b.file.Load(&b.buckets); err != nil {
+		return nil, err
+	}
+
+	b.logger.Info("Digest buffer loaded", zap.String("path", path), zap.Int("buckets", len(b.buckets)))
+	return b, nil
+}
+
+// Add добавляет уведомление message с payload notification в сводку. Если сводка
+// достигла лимита, возвращается ее копия для немедленной отправки через Flush;
+// сама сводка остается в буфере до успешной отправки
+func (b *DigestBuffer) Add(message *shared.KafkaMessage, notification *shared.NotificationMessage) (*DigestBucket, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	key := digestKey(notification)
+	bucket, ok := b.buckets[key]
+	if !ok {
+		bucket = &DigestBucket{
+			Key:      key,
+			ChatID:   notification.ChatID,
+			UserID:   notification.UserID,
+			Category: notification.Category,
+			Channels: notification.Channels,
+			OpenedAt: shared.GetCurrentTimestamp(),
+		}
+		b.buckets[key] = bucket
+	}
+
+	bucket.Items = append(bucket.Items, DigestItem{
+		MessageID: message.ID,
+		Text:      notification.Text,
+		Timestamp: shared.GetCurrentTimestamp(),
+		ExpiresAt: message.ExpiresAt,
+		RequestID: message.RequestID,
+	})
+
+	if err := b.file.Save(b.buckets); err != nil {
+		// Откатываем добавление, чтобы сообщение ушло на повторную обработку
+		bucket.Items = bucket.Items[:len(bucket.Items)-1]
+		if len(bucket.Items) == 0 {
+			delete(b.buckets, key)
+		}
+		return nil, err
+	}
+
+	if len(bucket.Items) >= b.maxCount && !bucket.flushing {
+		return b.claim(bucket), nil
+	}
+	return nil, nil
+}
+
+// Run периодически выпускает сводки, окно которых истекло, до отмены контекста
+func (b *DigestBuffer) Run(ctx context.Context, flush DigestFlush) {
+	interval := b.window / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cutoff := time.Now().Add(-b.window).UnixMilli()
+			for _, bucket := range b.take(func(bucket *DigestBucket) bool { return bucket.OpenedAt <= cutoff }) {
+				b.Flush(ctx, bucket, flush)
+			}
+		}
+	}
+}
+
+// FlushAll выпускает все накопленные сводки, например при остановке сервиса
+func (b *DigestBuffer) FlushAll(ctx context.Context, flush DigestFlush) {
+	buckets := b.take(func(*DigestBucket) bool { return true })
+	flushed := 0
+	for _, bucket := range buckets {
+		if b.Flush(ctx, bucket, flush) {
+			flushed++
+		}
+	}
+
+	if len(buckets) > 0 {
+		b.logger.Info("Digest buffer flushed", zap.Int("digests", flushed), zap.Int("kept", len(buckets)-flushed))
+	}
+}
+
+// Flush отправляет копию сводки, полученную из Add, и удаляет отправленные
+// уведомления из буфера. При ошибке сводка остается в буфере. Сводка, все
+// уведомления которой истекли, удаляется без отправки. Возвращает true, если
+// сводка отправлена или удалена
+func (b *DigestBuffer) Flush(ctx context.Context, bucket *DigestBucket, flush DigestFlush) bool {
+	message := bucket.Message(time.Now())
+	if message == nil {
+		b.logger.Info("Digest expired, dropping it",
+			zap.String("category", bucket.Category),
+			zap.Int("items", len(bucket.Items)),
+			zap.Strings("requestIds", bucket.RequestIDs()))
+		b.release(bucket, true)
+		return true
+	}
+
+	// Сводка объединяет уведомления разных запросов: их идентификаторы
+	// связывают ее с логами исходных запросов
+	log := logger.ForRequest(b.logger, message.RequestID).With(
+		zap.String("messageId", message.ID),
+		zap.Strings("requestIds", bucket.RequestIDs()))
+	log.Info("Flushing digest", zap.String("category", bucket.Category))
+
+	err := flush(ctx, message)
+	if err != nil {
+		log.Error("Failed to flush digest, keeping it buffered",
+			zap.String("category", bucket.Category),
+			zap.Int("items", len(bucket.Items)),
+			zap.Error(err))
+	}
+	b.release(bucket, err == nil)
+	return err == nil
+}
+
+// take отмечает сводки, удовлетворяющие условию, как отправляемые и возвращает их копии
+func (b *DigestBuffer) take(match func(bucket *DigestBucket) bool) []*DigestBucket {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var taken []*DigestBucket
+	for _, bucket := range b.buckets {
+		if !bucket.flushing && match(bucket) {
+			taken = append(taken, b.claim(bucket))
+		}
+	}
+
+	sort.Slice(taken, func(i, j int) bool { return taken[i].OpenedAt < taken[j].OpenedAt })
+	return taken
+}
+
+// claim отмечает сводку как отправляемую и возвращает ее копию. Вызывается под блокировкой
+func (b *DigestBuffer) claim(bucket *DigestBucket) *DigestBucket {
+	bucket.flushing = true
+	copied := *bucket
+	copied.Items = append([]DigestItem(nil), bucket.Items...)
+	return &copied
+}
+
+// release снимает отметку отправки. Если сводка отправлена, из буфера удаляются
+// вошедшие в нее уведомления; добавленные во время отправки остаются в новой сводке
+func (b *DigestBuffer) release(sent *DigestBucket, delivered bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bucket, ok := b.buckets[sent.Key]
+	if !ok {
+		return
+	}
+	bucket.flushing = false
+	if !delivered {
+		return
+	}
+
+	bucket.Items = bucket.Items[len(sent.Items):]
+	if len(bucket.Items) == 0 {
+		delete(b.buckets, sent.Key)
+	} else {
+		bucket.OpenedAt = shared.GetCurrentTimestamp()
+	}
+
+	// Если состояние не сохранилось, после перезапуска сводка будет отправлена повторно
+	if err := b.file.Save(b.buckets); err != nil {
+		b.logger.Error("Failed to persist digest buffer", zap.Error(err))
+	}
+}
+
+// Message формирует из сводки обычное уведомление с объединенным текстом.
+// Уведомления, истекшие к моменту now, в сводку не входят, а срок жизни сводки —
+// самый ранний из сроков вошедших уведомлений. Возвращает nil, если истекли все
+func (bucket *DigestBucket) Message(now time.Time) *shared.KafkaMessage {
+	live := *bucket
+	live.Items = nil
+	for _, item := range bucket.Items {
+		if item.ExpiresAt > 0 && now.UnixMilli() >= item.ExpiresAt {
+			continue
+		}
+		live.Items = append(live.Items, item)
+	}
+	if len(live.Items) == 0 {
+		return nil
+	}
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification, shared.NotificationMessage{
+		ChatID:   bucket.ChatID,
+		UserID:   bucket.UserID,
+		Category: bucket.Category,
+		Text:     live.Render(),
+		Channels: bucket.Channels,
+	})
+	for _, item := range live.Items {
+		if item.ExpiresAt > 0 && (message.ExpiresAt == 0 || item.ExpiresAt < message.ExpiresAt) {
+			message.ExpiresAt = item.ExpiresAt
+		}
+	}
+	// Если все уведомления сводки из одного запроса, она продолжает его логи
+	if requestIDs := live.RequestIDs(); len(requestIDs) == 1 {
+		message.RequestID = requestIDs[0]
+	}
+	return message
+}
+
+// RequestIDs возвращает идентификаторы запросов уведомлений сводки без повторов
+func (bucket *DigestBucket) RequestIDs() []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, item := range bucket.Items {
+		if item.RequestID != "" && !seen[item.RequestID] {
+			seen[item.RequestID] = true
+			ids = append(ids, item.RequestID)
+		}
+	}
+	return ids
+}
+
+// Render собирает текст сводки из накопленных уведомлений
+func (bucket *DigestBucket) Render() string {
+	var sb strings.Builder
+
+	if bucket.Category != "" {
+		fmt.Fprintf(&sb, "📬 %d notifications (%s):\n", len(bucket.Items), bucket.Category)
+	} else {
+		fmt.Fprintf(&sb, "📬 %d notifications:\n", len(bucket.Items))
+	}
+
+	for _, item := range bucket.Items {
+		sb.WriteString("\n• ")
+		sb.WriteString(item.Text)
+	}
+	return sb.String()
+}
+
+// digestKey вычисляет ключ сводки по получателю и категории
+func digestKey(notification *shared.NotificationMessage) string {
+	recipient := fmt.Sprintf("chat:%d", notification.ChatID)
+	if notification.UserID != "" {
+		recipient = "user:" + notification.UserID
+	}
+	key := recipient + "|" + notification.Category
+	// Уведомления с разными ограничениями каналов не объединяются в одну сводку
+	if len(notification.Channels) > 0 {
+		key += "|" + strings.Join(notification.Channels, ",")
+	}
+	return key
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+func TestDigestBuffer_AddReturnsBucketAtMaxCount(t *testing.T) {
+	buffer, err := NewDigestBuffer(filepath.Join(t.TempDir(), "digest.json"), time.Minute, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	notification := &shared.NotificationMessage{UserID: "user-1", Category: "comments", Text: "first"}
+	ready, err := buffer.Add(digestMessage("msg-1"), notification)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ready != nil {
+		t.Fatal("Expected digest to stay buffered")
+	}
+
+	notification.Text = "second"
+	ready, err = buffer.Add(digestMessage("msg-2"), notification)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ready == nil || len(ready.Items) != 2 {
+		t.Fatalf("Expected full digest with 2 items, got %+v", ready)
+	}
+
+	sent := func(ctx context.Context, message *shared.KafkaMessage) error { return nil }
+	if !buffer.Flush(context.Background(), ready, sent) {
+		t.Fatal("Expected digest to be flushed")
+	}
+
+	var flushed int
+	buffer.FlushAll(context.Background(), func(ctx context.Context, message *shared.KafkaMessage) error { flushed++; return nil })
+	if flushed != 0 {
+		t.Errorf("Expected empty buffer after full digest, flushed %d", flushed)
+	}
+}
+
+func TestDigestBuffer_KeepsDigestOnFailedFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.json")
+	buffer, err := NewDigestBuffer(path, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buffer.Add(digestMessage("msg-1"), &shared.NotificationMessage{UserID: "user-1", Text: "first"})
+	ready, _ := buffer.Add(digestMessage("msg-2"), &shared.NotificationMessage{UserID: "user-1", Text: "second"})
+	if ready == nil {
+		t.Fatal("Expected full digest")
+	}
+
+	failed := func(ctx context.Context, message *shared.KafkaMessage) error { return errors.New("send failed") }
+	if buffer.Flush(context.Background(), ready, failed) {
+		t.Fatal("Expected flush to fail")
+	}
+
+	// Уведомление, пришедшее во время неудачной отправки, остается в той же сводке
+	buffer.Add(digestMessage("msg-3"), &shared.NotificationMessage{UserID: "user-1", Text: "third"})
+
+	// Сводка сохранена на диске и переживает перезапуск
+	reloaded, err := NewDigestBuffer(path, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	var notification *shared.NotificationMessage
+	reloaded.FlushAll(context.Background(), func(ctx context.Context, message *shared.KafkaMessage) error {
+		notification, _ = message.GetNotificationPayload()
+		return nil
+	})
+	if notification == nil || !strings.Contains(notification.Text, "3 notifications") {
+		t.Fatalf("Expected kept digest with all items, got %+v", notification)
+	}
+
+	var flushed int
+	reloaded.FlushAll(context.Background(), func(ctx context.Context, message *shared.KafkaMessage) error { flushed++; return nil })
+	if flushed != 0 {
+		t.Errorf("Expected empty buffer after successful flush, flushed %d", flushed)
+	}
+}
+
+func TestDigestBuffer_GroupsByRecipientAndCategory(t *testing.T) {
+	buffer, err := NewDigestBuffer(filepath.Join(t.TempDir(), "digest.json"), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buffer.Add(digestMessage("1"), &shared.NotificationMessage{UserID: "user-1", Category: "comments", Text: "a"})
+	buffer.Add(digestMessage("2"), &shared.NotificationMessage{UserID: "user-1", Category: "billing", Text: "b"})
+	buffer.Add(digestMessage("3"), &shared.NotificationMessage{UserID: "user-2", Category: "comments", Text: "c"})
+	buffer.Add(digestMessage("4"), &shared.NotificationMessage{UserID: "user-1", Category: "comments", Text: "d"})
+
+	var messages []*shared.KafkaMessage
+	buffer.FlushAll(context.Background(), func(ctx context.Context, message *shared.KafkaMessage) error {
+		messages = append(messages, message)
+		return nil
+	})
+	if len(messages) != 3 {
+		t.Errorf("Expected 3 digests, got %d", len(messages))
+	}
+}
+
+func TestDigestBuffer_PersistsAcrossReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "digest.json")
+	buffer, err := NewDigestBuffer(path, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if _, err := buffer.Add(digestMessage("1"), &shared.NotificationMessage{ChatID: 42, Text: "hello"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	reloaded, err := NewDigestBuffer(path, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var messages []*shared.KafkaMessage
+	reloaded.FlushAll(context.Background(), func(ctx context.Context, message *shared.KafkaMessage) error {
+		messages = append(messages, message)
+		return nil
+	})
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 digest after reload, got %d", len(messages))
+	}
+
+	notification, err := messages[0].GetNotificationPayload()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if notification.ChatID != 42 || notification.Digest {
+		t.Errorf("Unexpected digest notification: %+v", notification)
+	}
+}
+
+func TestDigestBucket_Render(t *testing.T) {
+	bucket := &DigestBucket{
+		Category: "comments",
+		Items:    []DigestItem{{Text: "first"}, {Text: "second"}},
+	}
+
+	text := bucket.Render()
+	if !strings.Contains(text, "2 notifications (comments)") {
+		t.Errorf("Expected header with count and category, got %q", text)
+	}
+	if !strings.Contains(text, "• first") || !strings.Contains(text, "• second") {
+		t.Errorf("Expected all items in digest, got %q", text)
+	}
+}
+
+func digestMessage(id string) *shared.KafkaMessage {
+	return &shared.KafkaMessage{BaseKafkaMessage: shared.BaseKafkaMessage{ID: id}}
+}
+
+func TestDigestBucket_MessageSkipsExpiredItems(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	bucket := &DigestBucket{
+		UserID: "user-1",
+		Items: []DigestItem{
+			{Text: "stale code", ExpiresAt: now.Add(-time.Minute).UnixMilli(), RequestID: "req-1"},
+			{Text: "fresh code", ExpiresAt: now.Add(5 * time.Minute).UnixMilli(), RequestID: "req-2"},
+			{Text: "comment", ExpiresAt: now.Add(time.Hour).UnixMilli(), RequestID: "req-2"},
+		},
+	}
+
+	message := bucket.Message(now)
+	if message == nil {
+		t.Fatal("Expected digest message")
+	}
+	notification, _ := message.GetNotificationPayload()
+	if strings.Contains(notification.Text, "stale code") || !strings.Contains(notification.Text, "2 notifications") {
+		t.Errorf("Expected expired item to be skipped, got %q", notification.Text)
+	}
+	// Сводка живет не дольше самого раннего из вошедших уведомлений
+	if message.ExpiresAt != now.Add(5*time.Minute).UnixMilli() {
+		t.Errorf("Expected earliest expiry, got %d", message.ExpiresAt)
+	}
+	if message.RequestID != "req-2" {
+		t.Errorf("Expected request id of the only live request, got %q", message.RequestID)
+	}
+	if ids := bucket.RequestIDs(); len(ids) != 2 || ids[0] != "req-1" || ids[1] != "req-2" {
+		t.Errorf("Expected source request ids, got %v", ids)
+	}
+
+	if message := bucket.Message(now.Add(2 * time.Hour)); message != nil {
+		t.Errorf("Expected no message when all items expired, got %+v", message)
+	}
+}
+
+func TestDigestBuffer_FlushDropsExpiredDigest(t *testing.T) {
+	buffer, err := NewDigestBuffer(filepath.Join(t.TempDir(), "digest.json"), time.Minute, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	message := digestMessage("msg-1")
+	message.ExpiresAt = time.Now().Add(-time.Second).UnixMilli()
+	ready, _ := buffer.Add(message, &shared.NotificationMessage{UserID: "user-1", Text: "expired"})
+
+	flushed := 0
+	if !buffer.Flush(context.Background(), ready, func(context.Context, *shared.KafkaMessage) error {
+		flushed++
+		return nil
+	}) {
+		t.Fatal("Expected expired digest to be dropped")
+	}
+	if flushed != 0 {
+		t.Errorf("Expected expired digest not to be sent, got %d sends", flushed)
+	}
+	if buckets := buffer.take(func(*DigestBucket) bool { return true }); len(buckets) != 0 {
+		t.Errorf("Expected buffer to be empty, got %d buckets", len(buckets))
+	}
+}
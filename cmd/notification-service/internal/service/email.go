@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+// EmailService отправляет уведомления по электронной почте через SMTP
+type EmailService struct {
+	addr   string
+	auth   smtp.Auth
+	from   string
+	logger *zap.Logger
+}
+
+// NewEmailService создает новый экземпляр EmailService
+func NewEmailService(cfg *config.ChannelsConfig) (*EmailService, error) {
+	if cfg.SMTPHost == "" {
+		return nil, fmt.Errorf("SMTP_HOST is not set")
+	}
+	if cfg.SMTPFrom == "" {
+		return nil, fmt.Errorf("SMTP_FROM is not set")
+	}
+
+	var auth smtp.Auth
+	if cfg.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", cfg.SMTPUsername, cfg.SMTPPassword, cfg.SMTPHost)
+	}
+
+	return &EmailService{
+		addr:   net.JoinHostPort(cfg.SMTPHost, strconv.Itoa(cfg.SMTPPort)),
+		auth:   auth,
+		from:   cfg.SMTPFrom,
+		logger: logger.GetLogger(),
+	}, nil
+}
+
+// Channel возвращает название канала
+func (s *EmailService) Channel() string {
+	return shared.ChannelEmail
+}
+
+// Notify отправляет письмо получателю
+func (s *EmailService) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	var msg strings.Builder
+	msg.WriteString("From: " + s.from + "\r\n")
+	msg.WriteString("To: " + contacts.Email + "\r\n")
+	msg.WriteString("Subject: Notification\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(text)
+
+	if err := smtp.SendMail(s.addr, s.auth, s.from, []string{contacts.Email}, []byte(msg.String())); err != nil {
+		s.logger.Error("Error sending email", zap.Error(err), logger.Contact("email", contacts.Email))
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	s.logger.Info("Message sent by email", logger.Contact("email", contacts.Email))
+	return nil
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// escalationRetention задает, сколько хранятся завершенные эскалации
+const escalationRetention = 7 * 24 * time.Hour
+
+// ErrEscalationNotFound возвращается, если эскалация уведомления не найдена
+var ErrEscalationNotFound = errors.New("escalation not found")
+
+// ErrEscalationNotActive возвращается при подтверждении эскалации, которая уже
+// подтверждена, завершена или исчерпала шаги
+var ErrEscalationNotActive = errors.New("escalation is not active")
+
+// Состояния эскалации
+const (
+	EscalationActive       = "active"
+	EscalationAcknowledged = "acknowledged"
+	EscalationCompleted    = "completed"
+	EscalationExhausted    = "exhausted"
+)
+
+// EscalationAttempt описывает результат доставки на одном шаге эскалации
+type EscalationAttempt struct {
+	Step    int    `json:"step"`
+	Channel string `json:"channel"`
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	At      int64  `json:"at"`
+}
+
+// Escalation представляет уведомление, доставляемое по политике эскалации.
+// NextAt — срок подтверждения текущего шага, ноль означает, что шаг еще не доставлен
+type Escalation struct {
+	MessageID    string                     `json:"messageId"`
+	Policy       string                     `json:"policy"`
+	Notification shared.NotificationMessage `json:"notification"`
+	State        string                     `json:"state"`
+	Step         int                        `json:"step"`
+	NextAt       int64                      `json:"nextAt,omitempty"`
+	Attempts     []EscalationAttempt        `json:"attempts"`
+	AckedBy      string                     `json:"ackedBy,omitempty"`
+	AckedAt      int64                      `json:"ackedAt,omitempty"`
+	StartedAt    int64                      `json:"startedAt"`
+	UpdatedAt    int64                      `json:"updatedAt"`
+
+	// busy отмечает эскалацию, шаги которой сейчас выполняются
+	busy bool
+}
+
+// Escalator доставляет уведомления по шагам политик эскалации: при ошибке
+// доставки или без подтверждения в срок уведомление уходит на следующий шаг.
+// Состояние хранится на диске, поэтому ожидание подтверждения переживает перезапуск
+type Escalator struct {
+	mu         sync.Mutex
+	items      map[string]*Escalation
+	file       *storage.JSONFile
+	policies   *PolicyStore
+	dispatcher *Dispatcher
+	interval   time.Duration
+	now        func() time.Time
+	logger     *zap.Logger
+}
+
+// NewEscalator создает новый экземпляр Escalator и загружает сохраненные эскалации
+func NewEscalator(path string, policies *PolicyStore, dispatcher *Dispatcher, interval time.Duration) (*Escalator, error) {
+	e := &Escalator{
+		items:      make(map[string]*Escalation),
+		file:       storage.NewJSONFile(path),
+		policies:   policies,
+		dispatcher: dispatcher,
+		interval:   interval,
+		now:        time.Now,
+		logger:     logger.GetLogger(),
+	}
+
+	var saved []*Escalation
+	if err := e.file.Load(&saved); err != nil {
+		return nil, err
+	}
+	for _, item := range saved {
+		e.items[item.MessageID] = item
+	}
+
+	e.logger.Info("Escalations loaded", zap.String("path", path), zap.Int("escalations", len(e.items)))
+	return e, nil
+}
+
+// Start начинает эскалацию уведомления с первого шага политики. Повторно
+// полученное уведомление не отправляется: возвращается уже начатая эскалация
+func (e *Escalator) Start(ctx context.Context, messageID string, notification *shared.NotificationMessage) (*Escalation, error) {
+	policy, err := e.policies.Get(notification.EscalationPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s %q", errInvalidMessage, err, notification.EscalationPolicy)
+	}
+
+	e.mu.Lock()
+	if existing, ok := e.items[messageID]; ok {
+		copied := *existing
+		e.mu.Unlock()
+		return &copied, nil
+	}
+
+	now := e.now().UnixMilli()
+	e.items[messageID] = &Escalation{
+		MessageID:    messageID,
+		Policy:       policy.Name,
+		Notification: *notification,
+		State:        EscalationActive,
+		StartedAt:    now,
+		UpdatedAt:    now,
+		busy:         true,
+	}
+	if err := e.persist(); err != nil {
+		delete(e.items, messageID)
+		e.mu.Unlock()
+		return nil, err
+	}
+	e.mu.Unlock()
+
+	logger.WithContext(ctx, e.logger).Info("Escalation started",
+		zap.String("messageId", messageID),
+		zap.String("policy", policy.Name),
+		zap.Int("steps", len(policy.Steps)))
+
+	e.escalate(ctx, messageID, policy, 0)
+	return e.Get(messageID)
+}
+
+// Ack подтверждает получение уведомления и останавливает эскалацию. Эскалация,
+// которая уже не активна, не меняется: возвращается ErrEscalationNotActive
+func (e *Escalator) Ack(messageID, actor string) (*Escalation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	item, ok := e.items[messageID]
+	if !ok {
+		return nil, ErrEscalationNotFound
+	}
+
+	if item.State != EscalationActive {
+		return nil, fmt.Errorf("%w: %s", ErrEscalationNotActive, item.State)
+	}
+
+	previous := *item
+	now := e.now().UnixMilli()
+	item.State = EscalationAcknowledged
+	item.AckedBy = actor
+	item.AckedAt = now
+	item.NextAt = 0
+	item.UpdatedAt = now
+	if err := e.persist(); err != nil {
+		*item = previous
+		return nil, err
+	}
+
+	e.logger.Info("Escalation acknowledged",
+		zap.String("messageId", messageID),
+		zap.String("actor", actor),
+		zap.Int("step", item.Step))
+
+	copied := *item
+	return &copied, nil
+}
+
+// Get возвращает эскалацию уведомления
+func (e *Escalator) Get(messageID string) (*Escalation, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	item, ok := e.items[messageID]
+	if !ok {
+		return nil, ErrEscalationNotFound
+	}
+	copied := *item
+	return &copied, nil
+}
+
+// List возвращает эскалации, начиная с последней
+func (e *Escalator) List() []Escalation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	result := make([]Escalation, 0, len(e.items))
+	for _, item := range e.items {
+		result = append(result, *item)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].StartedAt > result[j].StartedAt })
+	return result
+}
+
+// Run периодически переводит на следующий шаг эскалации, не подтвержденные
+// в срок, и продолжает прерванные перезапуском до отмены контекста
+func (e *Escalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			e.advanceDue(ctx)
+		}
+	}
+}
+
+// advanceDue выполняет следующий шаг наступивших эскалаций
+func (e *Escalator) advanceDue(ctx context.Context) {
+	for _, item := range e.claimDue(e.now()) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		// Шаг без срока подтверждения не был доставлен — повторяем его
+		from := item.Step
+		if item.NextAt != 0 {
+			from++
+			e.logger.Warn("Escalation not acknowledged in time",
+				zap.String("messageId", item.MessageID),
+				zap.Int("step", item.Step))
+		}
+
+		policy, err := e.policies.Get(item.Policy)
+		if err != nil {
+			e.logger.Error("Escalation policy removed", zap.String("messageId", item.MessageID), zap.Error(err))
+			e.finish(item.MessageID, EscalationExhausted)
+			continue
+		}
+		e.escalate(ctx, item.MessageID, policy, from)
+	}
+}
+
+// claimDue отмечает выполняемыми активные эскалации со сроком не позже now
+// и эскалации, прерванные до доставки шага
+func (e *Escalator) claimDue(now time.Time) []Escalation {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var due []Escalation
+	for _, item := range e.items {
+		if item.State != EscalationActive || item.busy || item.NextAt > now.UnixMilli() {
+			continue
+		}
+		item.busy = true
+		due = append(due, *item)
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].StartedAt < due[j].StartedAt })
+	return due
+}
+
+// escalate выполняет шаги политики, начиная с from, пока доставка не удастся.
+// Остановка сервиса прерывает эскалацию без записи результата: после
+// перезапуска шаг выполняется снова
+func (e *Escalator) escalate(ctx context.Context, messageID string, policy *shared.EscalationPolicy, from int) {
+	defer e.release(messageID)
+
+	for step := from; step < len(policy.Steps); step++ {
+		item, err := e.Get(messageID)
+		if err != nil || item.State != EscalationActive {
+			return
+		}
+
+		current := policy.Steps[step]
+		err = e.dispatcher.deliverStep(ctx, &item.Notification, current)
+		if ctx.Err() != nil {
+			return
+		}
+
+		timeout, _ := current.Timeout()
+		if e.recordAttempt(messageID, step, current.Channel, err, timeout) {
+			return
+		}
+
+		e.logger.Warn("Escalation step failed",
+			zap.String("messageId", messageID),
+			zap.Int("step", step),
+			zap.String("channel", current.Channel),
+			zap.Error(err))
+	}
+
+	e.logger.Error("Escalation exhausted without acknowledgment",
+		zap.String("messageId", messageID),
+		zap.String("policy", policy.Name))
+	e.finish(messageID, EscalationExhausted)
+}
+
+// recordAttempt сохраняет результат шага и возвращает true, если эскалация
+// ждет подтверждения или завершена. Подтверждение, полученное во время
+// доставки, не перезаписывается
+func (e *Escalator) recordAttempt(messageID string, step int, channel string, cause error, timeout time.Duration) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	item, ok := e.items[messageID]
+	if !ok {
+		return true
+	}
+
+	now := e.now()
+	attempt := EscalationAttempt{Step: step, Channel: channel, Status: StatusDelivered, At: now.UnixMilli()}
+	if cause != nil {
+		attempt.Status = StatusFailed
+		attempt.Error = cause.Error()
+	}
+	item.Attempts = append(item.Attempts, attempt)
+	item.Step = step
+	item.UpdatedAt = now.UnixMilli()
+
+	if cause == nil && item.State == EscalationActive {
+		if timeout > 0 {
+			item.NextAt = now.Add(timeout).UnixMilli()
+		} else {
+			item.State = EscalationCompleted
+			item.NextAt = 0
+		}
+	}
+
+	if err := e.persist(); err != nil {
+		e.logger.Error("Failed to persist escalation", zap.String("messageId", messageID), zap.Error(err))
+	}
+	return cause == nil || item.State != EscalationActive
+}
+
+// finish завершает активную эскалацию с указанным состоянием
+func (e *Escalator) finish(messageID, state string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	item, ok := e.items[messageID]
+	if !ok || item.State != EscalationActive {
+		return
+	}
+	item.State = state
+	item.NextAt = 0
+	item.UpdatedAt = e.now().UnixMilli()
+
+	if err := e.persist(); err != nil {
+		e.logger.Error("Failed to persist escalation", zap.String("messageId", messageID), zap.Error(err))
+	}
+}
+
+// release снимает отметку выполнения с эскалации
+func (e *Escalator) release(messageID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if item, ok := e.items[messageID]; ok {
+		item.busy = false
+	}
+}
+
+// persist удаляет устаревшие завершенные эскалации и сохраняет остальные на диск.
+// Вызывается под блокировкой
+func (e *Escalator) persist() error {
+	cutoff := e.now().Add(-escalationRetention).UnixMilli()
+	snapshot := make([]*Escalation, 0, len(e.items))
+	for id, item := range e.items {
+		if item.State != EscalationActive && item.UpdatedAt < cutoff {
+			delete(e.items, id)
+			continue
+		}
+		snapshot = append(snapshot, item)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].StartedAt < snapshot[j].StartedAt })
+
+	if err := e.file.Save(snapshot); err != nil {
+		return fmt.Errorf("failed to persist escalations: %w", err)
+	}
+	return nil
+}
+
+// deliverStep отправляет уведомление через канал шага эскалации. Настройки
+// получателя не применяются: каналы и их порядок задает политика
+func (d *Dispatcher) deliverStep(ctx context.Context, notification *shared.NotificationMessage, step shared.EscalationStep) error {
+	contacts := step.Contacts
+	if contacts == nil {
+		contacts = &shared.ContactPoints{TelegramChatID: notification.ChatID}
+		if notification.UserID != "" {
+			recipient, err := d.resolver.Get(ctx, notification.UserID)
+			if err != nil {
+				return fmt.Errorf("failed to resolve recipient: %w", err)
+			}
+			contacts = &recipient.Contacts
+		}
+	}
+
+	if !contacts.HasContact(step.Channel) {
+		return fmt.Errorf("%w: no %s contact", ErrNoDeliveryChannel, step.Channel)
+	}
+	return d.send(ctx, step.Channel, contacts, notification.Text)
+}
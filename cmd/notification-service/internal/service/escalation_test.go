@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// newTestEscalator создает Escalator с политикой telegram → email → webhook дежурной смены
+func newTestEscalator(t *testing.T, now *time.Time, notifiers ...Notifier) *Escalator {
+	t.Helper()
+	dir := t.TempDir()
+
+	policies, err := NewPolicyStore(filepath.Join(dir, "escalation-policies.json"))
+	if err != nil {
+		t.Fatalf("Failed to create policy store: %v", err)
+	}
+	_, err = policies.Put("oncall-critical", &shared.EscalationPolicy{Steps: []shared.EscalationStep{
+		{Channel: shared.ChannelTelegram, AckTimeout: "5m"},
+		{Channel: shared.ChannelEmail, AckTimeout: "10m"},
+		{Channel: shared.ChannelWebhook, Contacts: &shared.ContactPoints{WebhookURL: "https://oncall.example.com/hook"}},
+	}})
+	if err != nil {
+		t.Fatalf("Failed to save policy: %v", err)
+	}
+
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {ID: "user-1", Contacts: shared.ContactPoints{TelegramChatID: 123, Email: "user@example.com"}},
+	}}
+	escalator, err := NewEscalator(filepath.Join(dir, "escalations.json"), policies,
+		NewDispatcher(resolver, nil, notifiers...), time.Minute)
+	if err != nil {
+		t.Fatalf("Failed to create escalator: %v", err)
+	}
+	escalator.now = func() time.Time { return *now }
+	return escalator
+}
+
+func criticalNotification() *shared.NotificationMessage {
+	return &shared.NotificationMessage{UserID: "user-1", Category: "security", Text: "Database is down",
+		EscalationPolicy: "oncall-critical"}
+}
+
+func TestEscalator_EscalatesWithoutAck(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	email := &mockNotifier{channel: shared.ChannelEmail}
+	webhook := &mockNotifier{channel: shared.ChannelWebhook}
+	escalator := newTestEscalator(t, &now, telegram, email, webhook)
+	ctx := context.Background()
+
+	escalation, err := escalator.Start(ctx, "msg-1", criticalNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(telegram.sent) != 1 || escalation.Step != 0 || escalation.State != EscalationActive {
+		t.Fatalf("Expected delivery through telegram awaiting ack, got %+v", escalation)
+	}
+
+	// Повторно полученное уведомление не отправляется снова
+	if _, err := escalator.Start(ctx, "msg-1", criticalNotification()); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(telegram.sent) != 1 {
+		t.Errorf("Expected redelivered notification to be ignored, got %d sends", len(telegram.sent))
+	}
+
+	escalator.advanceDue(ctx)
+	if len(email.sent) != 0 {
+		t.Fatal("Expected no escalation before ack timeout")
+	}
+
+	now = now.Add(5 * time.Minute)
+	escalator.advanceDue(ctx)
+	if len(email.sent) != 1 {
+		t.Fatalf("Expected escalation to email after ack timeout, got %d", len(email.sent))
+	}
+
+	now = now.Add(10 * time.Minute)
+	escalator.advanceDue(ctx)
+	escalation, _ = escalator.Get("msg-1")
+	if len(webhook.sent) != 1 || escalation.State != EscalationCompleted {
+		t.Errorf("Expected final delivery to on-call webhook, got %+v", escalation)
+	}
+	if len(escalation.Attempts) != 3 {
+		t.Errorf("Expected 3 attempts, got %d", len(escalation.Attempts))
+	}
+}
+
+func TestEscalator_EscalatesOnFailureAndStopsOnAck(t *testing.T) {
+	now := time.UnixMilli(1_700_000_000_000)
+	telegram := &mockNotifier{channel: shared.ChannelTelegram, err: errors.New("telegram is down")}
+	email := &mockNotifier{channel: shared.ChannelEmail}
+	webhook := &mockNotifier{channel: shared.ChannelWebhook}
+	escalator := newTestEscalator(t, &now, telegram, email, webhook)
+	ctx := context.Background()
+
+	escalation, err := escalator.Start(ctx, "msg-1", criticalNotification())
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(email.sent) != 1 || escalation.Step != 1 {
+		t.Fatalf("Expected immediate escalation to email, got %+v", escalation)
+	}
+	if escalation.Attempts[0].Status != StatusFailed {
+		t.Errorf("Expected failed telegram attempt, got %+v", escalation.Attempts[0])
+	}
+
+	escalation, err = escalator.Ack("msg-1", "jane")
+	if err != nil {
+		t.Fatalf("Unexpected ack error: %v", err)
+	}
+	if escalation.State != EscalationAcknowledged || escalation.AckedBy != "jane" {
+		t.Errorf("Expected acknowledged escalation, got %+v", escalation)
+	}
+
+	now = now.Add(time.Hour)
+	escalator.advanceDue(ctx)
+	if len(webhook.sent) != 0 {
+		t.Error("Expected no escalation after acknowledgment")
+	}
+
+	if _, err := escalator.Ack("msg-1", "john"); !errors.Is(err, ErrEscalationNotActive) {
+		t.Errorf("Expected ErrEscalationNotActive on repeated ack, got %v", err)
+	}
+	if escalation, _ := escalator.Get("msg-1"); escalation.AckedBy != "jane" {
+		t.Errorf("Expected repeated ack to keep the first actor, got %s", escalation.AckedBy)
+	}
+
+	if _, err := escalator.Ack("unknown", "jane"); !errors.Is(err, ErrEscalationNotFound) {
+		t.Errorf("Expected ErrEscalationNotFound, got %v", err)
+	}
+}
+
+func TestEscalator_UnknownPolicy(t *testing.T) {
+	now := time.Now()
+	escalator := newTestEscalator(t, &now, &mockNotifier{channel: shared.ChannelTelegram})
+
+	notification := criticalNotification()
+	notification.EscalationPolicy = "missing"
+	if _, err := escalator.Start(context.Background(), "msg-1", notification); !errors.Is(err, errInvalidMessage) {
+		t.Errorf("Expected invalid message error for unknown policy, got %v", err)
+	}
+}
@@ -3,144 +3,539 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/consumer"
+	"kafka-notification-system/pkg/deadletter"
+	"kafka-notification-system/pkg/health"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
 	"kafka-notification-system/pkg/shared"
+	"sync"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
 )
 
-// KafkaService обрабатывает получение сообщений из Kafka для отправки уведомлений
+// suppressionLogSize задает число последних подавленных уведомлений, доступных через API
+const suppressionLogSize = 200
+
+// KafkaService обрабатывает получение сообщений из Kafka для отправки уведомлений.
+// Уведомления читаются из топиков всех приоритетов, очередность обработки
+// определяет laneScheduler
 type KafkaService struct {
-	reader           *kafka.Reader
-	deadLetterWriter *kafka.Writer
-	telegramService  *TelegramService
+	lanes            []*lane
+	lanesScheduler   *laneScheduler
+	wake             chan struct{}
+	workers          int
+	offsets          *offsetTracker
+	control          *consumer.Control
+	stopped          chan struct{}
+	retryTiers       []*retryTier
+	retryWriter      MessageWriter
+	deadLetterWriter *deadletter.Writer
+	dispatcher       *Dispatcher
+	scheduler        *Scheduler
+	digest           *DigestBuffer
+	escalator        *Escalator
+	dedupe           *Deduplicator
+	stats            *DeliveryStats
+	suppressed       *SuppressionLog
+	metrics          *metrics.Metrics
 	config           *config.KafkaConfig
 	logger           *zap.Logger
 }
 
-// NewKafkaService создает новый экземпляр KafkaService
-func NewKafkaService(kafkaConfig *config.KafkaConfig, telegramService *TelegramService) *KafkaService {
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers:  kafkaConfig.Brokers,
-		Topic:    kafkaConfig.NotificationsTopic,
-		GroupID:  kafkaConfig.GroupID,
-		MinBytes: 10e3, // 10KB
-		MaxBytes: 10e6, // 10MB
-	})
+// NewKafkaService создает новый экземпляр KafkaService. Reader и writer
+// регистрируются в метриках m, если он задан
+func NewKafkaService(kafkaConfig *config.KafkaConfig, deliveryConfig *config.DeliveryConfig,
+	dispatcher *Dispatcher, scheduler *Scheduler, digest *DigestBuffer, escalator *Escalator,
+	dedupe *Deduplicator, m *metrics.Metrics) *KafkaService {
+	lanes := make([]*lane, 0, len(shared.Priorities))
+	for _, priority := range shared.Priorities {
+		topic := kafkaConfig.PriorityTopic(priority)
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  kafkaConfig.Brokers,
+			Topic:    topic,
+			GroupID:  kafkaConfig.GroupID,
+			MinBytes: 10e3, // 10KB
+			MaxBytes: 10e6, // 10MB
+		})
+		m.RegisterReader(topic, reader)
+		lanes = append(lanes, &lane{
+			priority: priority,
+			topic:    topic,
+			reader:   reader,
+			messages: make(chan kafka.Message, 1),
+			poll:     health.NewPollTracker(),
+		})
+	}
+
+	retryTiers := make([]*retryTier, 0, len(kafkaConfig.RetryTiers))
+	for _, tier := range kafkaConfig.RetryTopics() {
+		reader := kafka.NewReader(kafka.ReaderConfig{
+			Brokers:  kafkaConfig.Brokers,
+			Topic:    tier.Topic,
+			GroupID:  kafkaConfig.GroupID,
+			MinBytes: 10e3, // 10KB
+			MaxBytes: 10e6, // 10MB
+		})
+		m.RegisterReader(tier.Topic, reader)
+		retryTiers = append(retryTiers, &retryTier{RetryTier: tier, reader: reader, poll: health.NewPollTracker()})
+	}
 
-	deadLetterWriter := &kafka.Writer{
+	// Топик задается для каждого сообщения по уровню повторной обработки.
+	// Партиция выбирается по ключу получателя, как в основных топиках
+	retryWriter := &kafka.Writer{
 		Addr:         kafka.TCP(kafkaConfig.Brokers...),
-		Topic:        kafkaConfig.DeadLetterTopic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{},
 		RequiredAcks: kafka.RequireOne,
 		Async:        false,
 	}
 
-	return &KafkaService{
-		reader:           reader,
+	deadLetterWriter := deadletter.NewWriter(kafkaConfig, "notification-service")
+	m.RegisterWriter("retry", retryWriter)
+	m.RegisterWriter(kafkaConfig.DeadLetterTopic, deadLetterWriter)
+
+	stats := NewDeliveryStats()
+	stats.metrics = m
+
+	s := &KafkaService{
+		lanes:            lanes,
+		lanesScheduler:   newLaneScheduler(deliveryConfig.PriorityMode, deliveryConfig.PriorityWeights),
+		wake:             make(chan struct{}, 1),
+		workers:          deliveryConfig.Workers,
+		offsets:          newOffsetTracker(),
+		control:          consumer.NewControl(),
+		stopped:          make(chan struct{}),
+		retryTiers:       retryTiers,
+		retryWriter:      retryWriter,
 		deadLetterWriter: deadLetterWriter,
-		telegramService:  telegramService,
+		dispatcher:       dispatcher,
+		scheduler:        scheduler,
+		digest:           digest,
+		escalator:        escalator,
+		dedupe:           dedupe,
+		stats:            stats,
+		suppressed:       NewSuppressionLog(suppressionLogSize),
+		metrics:          m,
 		config:           kafkaConfig,
 		logger:           logger.GetLogger(),
 	}
+	// Пока канал отключен, новые сообщения не читаются, а не откладываются
+	// по одному в планировщик
+	if dispatcher != nil {
+		dispatcher.WatchBreakers(s.circuitChanged)
+	}
+	return s
+}
+
+// circuitChanged приостанавливает чтение новых сообщений, пока канал channel
+// отключен circuit breaker, и возобновляет его в half-open, чтобы прошли пробные
+// отправки. Сообщения, уже взятые в обработку, откладываются до пробной отправки
+func (s *KafkaService) circuitChanged(channel, state string) {
+	reason := "circuit breaker open: " + channel
+	if state == BreakerOpen {
+		s.logger.Warn("Pausing consumption while channel is disabled", zap.String("channel", channel))
+		s.control.Hold(reason)
+		return
+	}
+	s.control.Release(reason)
 }
 
-// StartConsuming начинает потребление сообщений из Kafka
+// StartConsuming начинает потребление сообщений из Kafka. Каждая очередь
+// читается в своей горутине, а планировщик очередей выбирает порядок, в котором
+// сообщения передаются пулу обработчиков. Сообщения одного получателя
+// обрабатываются одним обработчиком по порядку, а offset фиксируется только до
+// непрерывно обработанных сообщений партиции. Неудачные сообщения уходят в топики
+// повторной обработки, у каждого уровня свой consumer, поэтому повторы не
+// задерживают новые сообщения. После Drain возвращает nil, когда обработка завершена
 func (s *KafkaService) StartConsuming(ctx context.Context) error {
+	defer close(s.stopped)
+
 	s.logger.Info("Starting Notification Service Kafka consumer",
-		zap.String("topic", s.config.NotificationsTopic),
-		zap.String("groupId", s.config.GroupID))
+		zap.Strings("topics", s.topics()),
+		zap.String("groupId", s.config.GroupID),
+		zap.Int("workers", s.workers),
+		zap.Bool("strictPriority", s.lanesScheduler.strict),
+		zap.Ints("weights", s.lanesScheduler.weights))
+
+	for _, l := range s.lanes {
+		go s.fetch(ctx, l)
+	}
+
+	var retries sync.WaitGroup
+	for _, tier := range s.retryTiers {
+		retries.Add(1)
+		go func(tier *retryTier) {
+			defer retries.Done()
+			s.consumeRetries(ctx, tier)
+		}(tier)
+	}
+	defer retries.Wait()
+
+	pool := newWorkerPool(s.workers, s.handleJob)
+	pool.start(ctx)
+	// Ждем обработчики: при drain они завершают переданные им сообщения,
+	// а Close не закроет reader во время фиксации offset
+	defer pool.stop()
+
+	for {
+		l, message, err := s.nextMessage(ctx)
+		if err != nil {
+			return s.stopConsuming(err)
+		}
+
+		// Во время паузы сообщение не передается обработчику. Если пауза
+		// закончится остановкой, offset не фиксирован и сообщение будет получено снова
+		if err := s.control.Wait(ctx); err != nil {
+			return s.stopConsuming(err)
+		}
+
+		generation := s.offsets.track(message)
+		s.control.Begin()
+		if err := pool.submit(ctx, recipientKey(message), job{reader: l.reader, message: message, generation: generation}); err != nil {
+			s.control.Done()
+			return s.stopConsuming(err)
+		}
+	}
+}
+
+// stopConsuming завершает цикл потребления: остановка через drain не считается ошибкой
+func (s *KafkaService) stopConsuming(err error) error {
+	if errors.Is(err, consumer.ErrDraining) {
+		s.logger.Info("Draining Notification Service Kafka consumer", zap.Int64("inFlight", s.control.Status().InFlight))
+		return nil
+	}
+	s.logger.Info("Stopping Notification Service Kafka consumer")
+	return err
+}
+
+// Drain останавливает потребление: новые сообщения не берутся, а сообщения,
+// переданные обработчикам, обрабатываются до конца с фиксацией offset.
+// Прочитанные, но не переданные сообщения будут получены снова после перезапуска
+func (s *KafkaService) Drain(ctx context.Context) error {
+	s.control.Drain()
+
+	select {
+	case <-s.stopped:
+		s.logger.Info("Notification Service Kafka consumer drained")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Control возвращает управление потреблением: паузу, возобновление и состояние
+func (s *KafkaService) Control() *consumer.Control {
+	return s.control
+}
+
+// handleJob обрабатывает сообщение в пуле и фиксирует offset его партиции
+func (s *KafkaService) handleJob(ctx context.Context, j job) {
+	defer s.control.Done()
+	// Offset сообщения, не переданного дальше, не фиксируется, как и следующие
+	// offset партиции: после перезапуска они будут прочитаны повторно
+	if err := s.deliver(ctx, j.message); err != nil {
+		logger.ForRequest(s.logger, headerValue(j.message.Headers, shared.HeaderRequestID)).Error("Message left uncommitted",
+			zap.String("topic", j.message.Topic),
+			zap.Int("partition", j.message.Partition),
+			zap.Error(err))
+		return
+	}
 
+	if err := s.offsets.complete(ctx, j.reader, j.message, j.generation); err != nil {
+		logger.ForRequest(s.logger, headerValue(j.message.Headers, shared.HeaderRequestID)).Error("Failed to commit message",
+			zap.String("topic", j.message.Topic),
+			zap.Int("partition", j.message.Partition),
+			zap.Error(err))
+	}
+}
+
+// fetch читает сообщения очереди в ее буфер до отмены контекста
+func (s *KafkaService) fetch(ctx context.Context, l *lane) {
 	for {
+		l.poll.Waiting()
+		message, err := l.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("Failed to read message", zap.String("topic", l.topic), zap.Error(err))
+			continue
+		}
+		// Пока обработчики заняты, fetch ждет места в буфере и не читает дальше
+		l.poll.Polled()
+
 		select {
+		case l.messages <- message:
 		case <-ctx.Done():
-			s.logger.Info("Stopping Notification Service Kafka consumer")
-			return ctx.Err()
+			return
+		}
+
+		// Будим обработчик, если он ждет сообщений
+		select {
+		case s.wake <- struct{}{}:
 		default:
-			message, err := s.reader.ReadMessage(ctx)
-			if err != nil {
-				s.logger.Error("Failed to read message", zap.Error(err))
-				continue
-			}
+		}
+	}
+}
 
-			if err := s.processMessage(ctx, message); err != nil {
-				s.logger.Error("Failed to process message", zap.Error(err))
-				if err := s.handleDeadLetter(ctx, message); err != nil {
-					s.logger.Error("Failed to send message to dead letter topic", zap.Error(err))
-				}
-			}
+// nextMessage ждет готовое сообщение и возвращает его вместе с очередью,
+// выбранной планировщиком
+func (s *KafkaService) nextMessage(ctx context.Context) (*lane, kafka.Message, error) {
+	ready := make([]bool, len(s.lanes))
+	for {
+		// Буфер очереди пополняет только fetch, поэтому непустой буфер
+		// гарантирует успешное чтение
+		for i, l := range s.lanes {
+			ready[i] = len(l.messages) > 0
+		}
+		if i := s.lanesScheduler.next(ready); i >= 0 {
+			return s.lanes[i], <-s.lanes[i].messages, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, kafka.Message{}, ctx.Err()
+		case <-s.control.Draining():
+			return nil, kafka.Message{}, consumer.ErrDraining
+		case <-s.wake:
 		}
 	}
 }
 
+// Polls возвращает отметки циклов чтения очередей и уровней повторной
+// обработки по топикам для проверки готовности
+func (s *KafkaService) Polls() map[string]*health.PollTracker {
+	polls := make(map[string]*health.PollTracker, len(s.lanes)+len(s.retryTiers))
+	for _, l := range s.lanes {
+		polls[l.topic] = l.poll
+	}
+	for _, tier := range s.retryTiers {
+		polls[tier.Topic] = tier.poll
+	}
+	return polls
+}
+
+// topics возвращает топики всех очередей
+func (s *KafkaService) topics() []string {
+	topics := make([]string, 0, len(s.lanes))
+	for _, l := range s.lanes {
+		topics = append(topics, l.topic)
+	}
+	return topics
+}
+
 // processMessage обрабатывает полученное сообщение
 func (s *KafkaService) processMessage(ctx context.Context, message kafka.Message) error {
 	if len(message.Value) == 0 {
-		return fmt.Errorf("empty message value")
+		return fmt.Errorf("%w: empty message value", errInvalidMessage)
 	}
 
-	s.logger.Info("Received message", zap.ByteString("value", message.Value))
+	logger.WithContext(ctx, s.logger).Info("Received message", logger.Payload("value", message.Value))
 
 	// Парсим сообщение
 	var rawMessage map[string]interface{}
 	if err := json.Unmarshal(message.Value, &rawMessage); err != nil {
-		return fmt.Errorf("failed to unmarshal message: %w", err)
+		return fmt.Errorf("%w: failed to unmarshal message: %v", errInvalidMessage, err)
 	}
 
 	// Проверяем валидность структуры
 	if !shared.IsValidKafkaMessage(rawMessage) {
-		return fmt.Errorf("invalid message format")
+		return fmt.Errorf("%w: invalid message format", errInvalidMessage)
 	}
 
 	// Конвертируем в типизированное сообщение
 	kafkaMessage, err := shared.FromJSON(message.Value)
 	if err != nil {
-		return fmt.Errorf("failed to parse kafka message: %w", err)
+		return fmt.Errorf("%w: failed to parse kafka message: %v", errInvalidMessage, err)
 	}
 
 	// Обрабатываем только уведомления
 	if kafkaMessage.IsNotificationMessage() {
-		return s.processNotification(kafkaMessage)
+		// Fan-out Service повторно публикует пакет рассылки, если упал до сохранения
+		// позиции, поэтому уведомления с уже обработанным идентификатором пропускаются.
+		// Повторная обработка и повторная публикация из dead letter topic сохраняют
+		// идентификатор, но не являются дубликатами: уведомление, отложенное и затем
+		// не доставленное, иначе было бы потеряно
+		if !isReentry(message) && s.dedupe.Seen(kafkaMessage.ID) {
+			logger.WithContext(ctx, s.logger).Info("Duplicate notification, skipping",
+				zap.String("messageId", kafkaMessage.ID),
+				zap.String("status", StatusDuplicate))
+			s.stats.Inc(StatusDuplicate)
+			return nil
+		}
+		if err := s.processNotification(ctx, kafkaMessage); err != nil {
+			return err
+		}
+		if err := s.dedupe.Mark(kafkaMessage.ID); err != nil {
+			logger.WithContext(ctx, s.logger).Warn("Failed to save processed notification id",
+				zap.String("messageId", kafkaMessage.ID), zap.Error(err))
+		}
+		return nil
 	}
 
-	s.logger.Warn("Received non-notification message", zap.String("type", kafkaMessage.Type))
+	logger.WithContext(ctx, s.logger).Warn("Received non-notification message", zap.String("type", kafkaMessage.Type))
 	return nil
 }
 
+// isReentry проверяет, что сообщение — повторная попытка из топика повторной
+// обработки или повторная публикация из dead letter topic
+func isReentry(message kafka.Message) bool {
+	return retryAttempt(message) > 0 || headerValue(message.Headers, shared.HeaderReplayCount) != ""
+}
+
 // processNotification обрабатывает уведомление
-func (s *KafkaService) processNotification(message *shared.KafkaMessage) error {
-	s.logger.Info("Processing notification", zap.String("messageId", message.ID))
+func (s *KafkaService) processNotification(ctx context.Context, message *shared.KafkaMessage) error {
+	// Заголовка нет у отложенных уведомлений и сообщений, опубликованных в обход Producer
+	if logger.RequestIDFromContext(ctx) == "" {
+		ctx = logger.WithRequestID(ctx, message.RequestID)
+	}
+	logger.WithContext(ctx, s.logger).Info("Processing notification", zap.String("messageId", message.ID))
+
+	// Устаревшие уведомления (например, одноразовые коды после простоя)
+	// не отправляются и не попадают в dead letter topic
+	if message.IsExpired(time.Now()) {
+		logger.WithContext(ctx, s.logger).Warn("Notification expired, skipping",
+			zap.String("messageId", message.ID),
+			zap.String("status", StatusExpired),
+			zap.Int64("timestamp", message.Timestamp),
+			zap.Int64("expiresAt", message.ExpiresAt))
+		s.stats.Inc(StatusExpired)
+		return nil
+	}
 
 	notification, err := message.GetNotificationPayload()
 	if err != nil {
 		return fmt.Errorf("failed to get notification payload: %w", err)
 	}
 
-	// Отправляем сообщение в Telegram
-	return s.telegramService.SendMessage(notification.ChatID, notification.Text)
+	// Уведомления с политикой эскалации доставляются по ее шагам до подтверждения
+	if notification.EscalationPolicy != "" {
+		escalation, err := s.escalator.Start(ctx, message.ID, notification)
+		if err != nil {
+			return fmt.Errorf("failed to start escalation: %w", err)
+		}
+		logger.WithContext(ctx, s.logger).Info("Notification escalated",
+			zap.String("messageId", message.ID),
+			zap.String("policy", escalation.Policy),
+			zap.String("state", escalation.State),
+			zap.Int("step", escalation.Step))
+		s.stats.Inc(StatusEscalated)
+		return nil
+	}
+
+	// Уведомления с флагом digest накапливаются и отправляются одной сводкой
+	if notification.Digest {
+		ready, err := s.digest.Add(message, notification)
+		if err != nil {
+			return fmt.Errorf("failed to buffer digest notification: %w", err)
+		}
+		s.stats.Inc(StatusDigested)
+		// Уведомление уже сохранено в буфере: если сводку отправить не удалось,
+		// она останется в буфере и уйдет при следующем выпуске
+		if ready != nil {
+			s.digest.Flush(ctx, ready, s.ProcessDeferred)
+		}
+		return nil
+	}
+
+	// Отправляем сообщение через канал получателя с учетом его настроек
+	result, err := s.dispatcher.Dispatch(ctx, notification)
+	if err != nil {
+		// Канал отключен circuit breaker: откладываем до пробной отправки,
+		// не расходуя попытки повторной обработки и не отправляя в dead letter topic
+		var circuitErr *CircuitOpenError
+		if errors.As(err, &circuitErr) {
+			s.stats.Inc(StatusDeferred)
+			return s.scheduler.Schedule(message, circuitErr.RetryAt, circuitErr.Error())
+		}
+		return err
+	}
+
+	s.stats.Inc(result.Status)
+	switch result.Status {
+	case StatusDeferred:
+		return s.scheduler.Schedule(message, result.DeferUntil, result.Reason)
+	case StatusSuppressed:
+		logger.WithContext(ctx, s.logger).Info("Notification suppressed by recipient preferences",
+			zap.String("messageId", message.ID),
+			zap.String("userId", notification.UserID),
+			zap.String("category", notification.Category),
+			zap.String("reason", result.Reason))
+		s.suppressed.Add(SuppressedItem{
+			MessageID: message.ID,
+			UserID:    notification.UserID,
+			Category:  notification.Category,
+			Reason:    result.Reason,
+		})
+	}
+	return nil
 }
 
-// handleDeadLetter отправляет сообщение в dead letter topic
-func (s *KafkaService) handleDeadLetter(ctx context.Context, originalMessage kafka.Message) error {
-	deadLetterMessage := kafka.Message{
-		Key:   originalMessage.Key,
-		Value: originalMessage.Value,
-		Headers: append(originalMessage.Headers, kafka.Header{
-			Key:   "error-timestamp",
-			Value: []byte(fmt.Sprintf("%d", shared.GetCurrentTimestamp())),
-		}),
+// ProcessDeferred обрабатывает уведомление вне потока Kafka: отложенное
+// планировщиком или собранную сводку. При ошибке уведомление уходит на повторную
+// обработку. Ошибка возвращается, только если уведомление не удалось ни обработать,
+// ни передать на повторную обработку, — тогда вызывающий должен сохранить его у себя
+func (s *KafkaService) ProcessDeferred(ctx context.Context, message *shared.KafkaMessage) error {
+	ctx = logger.WithRequestID(ctx, message.RequestID)
+	err := s.processNotification(ctx, message)
+	if err == nil {
+		return nil
 	}
+	logger.WithContext(ctx, s.logger).Error("Failed to process deferred notification",
+		zap.String("messageId", message.ID),
+		zap.Error(err))
 
-	return s.deadLetterWriter.WriteMessages(ctx, deadLetterMessage)
+	value, marshalErr := message.ToJSON()
+	if marshalErr != nil {
+		return fmt.Errorf("failed to marshal deferred notification: %w", marshalErr)
+	}
+
+	// Сообщение обрабатывается вне Kafka, поэтому позиции нет — указываем только исходный топик
+	original := kafka.Message{
+		Key:   []byte(message.ID),
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: shared.HeaderMessageType, Value: []byte(message.Type)},
+			{Key: shared.HeaderRetryOriginTopic, Value: []byte(s.config.NotificationsTopic)},
+		},
+	}
+	if message.RequestID != "" {
+		original.Headers = append(original.Headers, kafka.Header{Key: shared.HeaderRequestID, Value: []byte(message.RequestID)})
+	}
+	// Ключ получателя, как у сообщений основных топиков; идентификатор — запасной
+	original.Key = []byte(recipientKey(original))
+	return s.handleFailure(ctx, original, err)
+}
+
+// Suppressed возвращает журнал последних подавленных уведомлений
+func (s *KafkaService) Suppressed() *SuppressionLog {
+	return s.suppressed
+}
+
+// Stats возвращает счетчики обработки уведомлений
+func (s *KafkaService) Stats() *DeliveryStats {
+	return s.stats
 }
 
 // Close закрывает соединения с Kafka
 func (s *KafkaService) Close() error {
-	if err := s.reader.Close(); err != nil {
-		s.logger.Error("Failed to close reader", zap.Error(err))
+	for _, l := range s.lanes {
+		if err := l.reader.Close(); err != nil {
+			s.logger.Error("Failed to close reader", zap.String("topic", l.topic), zap.Error(err))
+		}
+	}
+	for _, tier := range s.retryTiers {
+		if err := tier.reader.Close(); err != nil {
+			s.logger.Error("Failed to close retry reader", zap.String("topic", tier.Topic), zap.Error(err))
+		}
+	}
+	if err := s.retryWriter.Close(); err != nil {
+		s.logger.Error("Failed to close retry writer", zap.Error(err))
 	}
 	if err := s.deadLetterWriter.Close(); err != nil {
 		s.logger.Error("Failed to close dead letter writer", zap.Error(err))
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/consumer"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+func newTestKafkaService(notifiers ...Notifier) *KafkaService {
+	return &KafkaService{
+		dispatcher: NewDispatcher(&mockResolver{}, nil, notifiers...),
+		stats:      NewDeliveryStats(),
+		suppressed: NewSuppressionLog(suppressionLogSize),
+		control:    consumer.NewControl(),
+		stopped:    make(chan struct{}),
+		logger:     zap.NewNop(),
+	}
+}
+
+func TestKafkaService_ProcessNotification_SkipsExpired(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	service := newTestKafkaService(telegram)
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "Your code is 123456"})
+	message.ExpiresAt = time.Now().Add(-time.Minute).UnixMilli()
+
+	if err := service.processNotification(context.Background(), message); err != nil {
+		t.Fatalf("Expected expired notification to be skipped without error, got %v", err)
+	}
+
+	if len(telegram.sent) != 0 {
+		t.Errorf("Expected no messages sent, got %d", len(telegram.sent))
+	}
+	if count := service.Stats().Snapshot()[StatusExpired]; count != 1 {
+		t.Errorf("Expected 1 expired notification, got %d", count)
+	}
+}
+
+func TestKafkaService_ProcessNotification_DeliversBeforeExpiry(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	service := newTestKafkaService(telegram)
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "Your code is 123456"})
+	message.ExpiresAt = time.Now().Add(time.Minute).UnixMilli()
+
+	if err := service.processNotification(context.Background(), message); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(telegram.sent) != 1 {
+		t.Errorf("Expected 1 message sent, got %d", len(telegram.sent))
+	}
+	if count := service.Stats().Snapshot()[StatusDelivered]; count != 1 {
+		t.Errorf("Expected 1 delivered notification, got %d", count)
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Notifier определяет интерфейс канала доставки уведомлений
+type Notifier interface {
+	Channel() string
+	Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error
+}
+
+// Pinger определяет интерфейс проверки доступности канала доставки без отправки
+// уведомления. Возвращает описание для ответа /readyz
+type Pinger interface {
+	Ping(ctx context.Context) (string, error)
+}
+
+// InstrumentedNotifier учитывает длительность и результат отправки через канал
+// в метриках и записывает отправку дочерним спаном трассировки
+type InstrumentedNotifier struct {
+	Notifier
+	metrics *metrics.Metrics
+}
+
+// NewInstrumentedNotifier создает новый экземпляр InstrumentedNotifier
+func NewInstrumentedNotifier(notifier Notifier, m *metrics.Metrics) *InstrumentedNotifier {
+	return &InstrumentedNotifier{Notifier: notifier, metrics: m}
+}
+
+// Notify отправляет уведомление и учитывает длительность отправки
+func (n *InstrumentedNotifier) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	ctx, span := tracing.Tracer().Start(ctx, "send "+n.Channel(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("notification.channel", n.Channel())))
+
+	start := time.Now()
+	err := n.Notifier.Notify(ctx, contacts, text)
+	n.metrics.ObserveChannelSend(n.Channel(), err, time.Since(start))
+	tracing.End(span, err)
+	return err
+}
+
+// RecipientResolver определяет интерфейс поиска получателя в реестре
+type RecipientResolver interface {
+	Get(ctx context.Context, userID string) (*shared.Recipient, error)
+}
+
+// ErrNoDeliveryChannel возвращается, если у получателя нет ни одного доступного канала
+var ErrNoDeliveryChannel = errors.New("no delivery channel available for recipient")
+
+// ErrRecipientRejected означает, что канал доступен, но отказал в доставке
+// конкретному получателю: webhook ответил 4xx, Telegram не нашел чат.
+// Такие ошибки не отключают канал circuit breaker
+var ErrRecipientRejected = errors.New("recipient rejected by channel")
+
+// RecipientError оборачивает ошибку канала, вызванную получателем. Текст и
+// класс ошибки для dead letter topic остаются прежними, а
+// errors.Is(err, ErrRecipientRejected) возвращает true
+type RecipientError struct {
+	Err error
+}
+
+func (e *RecipientError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap возвращает исходную ошибку канала
+func (e *RecipientError) Unwrap() error {
+	return e.Err
+}
+
+// Is позволяет проверять ошибку через errors.Is(err, ErrRecipientRejected)
+func (e *RecipientError) Is(target error) bool {
+	return target == ErrRecipientRejected
+}
+
+// Статусы доставки уведомления
+const (
+	StatusDelivered  = "delivered"
+	StatusSuppressed = "suppressed"
+	StatusDeferred   = "deferred"
+)
+
+// DeliveryResult описывает итог обработки уведомления диспетчером
+type DeliveryResult struct {
+	Status     string
+	Channel    string
+	Reason     string
+	DeferUntil time.Time
+}
+
+// Dispatcher выбирает канал доставки для уведомления и отправляет его
+type Dispatcher struct {
+	notifiers map[string]Notifier
+	resolver  RecipientResolver
+	critical  map[string]bool
+	now       func() time.Time
+	logger    *zap.Logger
+}
+
+// NewDispatcher создает новый экземпляр Dispatcher. Уведомления критических
+// категорий доставляются даже во время окна тишины получателя
+func NewDispatcher(resolver RecipientResolver, criticalCategories []string, notifiers ...Notifier) *Dispatcher {
+	registry := make(map[string]Notifier, len(notifiers))
+	for _, n := range notifiers {
+		registry[n.Channel()] = n
+	}
+
+	critical := make(map[string]bool, len(criticalCategories))
+	for _, category := range criticalCategories {
+		critical[category] = true
+	}
+
+	return &Dispatcher{
+		notifiers: registry,
+		resolver:  resolver,
+		critical:  critical,
+		now:       time.Now,
+		logger:    logger.GetLogger(),
+	}
+}
+
+// Dispatch доставляет уведомление адресату. Если задан UserID, получатель
+// разрешается через реестр, применяются его настройки и используется первый
+// доступный канал по приоритету. Каналы вне notification.Channels не используются
+func (d *Dispatcher) Dispatch(ctx context.Context, notification *shared.NotificationMessage) (*DeliveryResult, error) {
+	if notification.UserID == "" {
+		if !shared.AllowsChannel(notification.Channels, shared.ChannelTelegram) {
+			// Идентификатор чата не попадает в текст ошибки: он уходит в логи,
+			// заголовки повторной обработки и dead-letter в обход скрытия данных
+			return nil, fmt.Errorf("%w: telegram is not allowed for chat", ErrNoDeliveryChannel)
+		}
+		contacts := &shared.ContactPoints{TelegramChatID: notification.ChatID}
+		if err := d.send(ctx, shared.ChannelTelegram, contacts, notification.Text); err != nil {
+			return nil, err
+		}
+		return &DeliveryResult{Status: StatusDelivered, Channel: shared.ChannelTelegram}, nil
+	}
+
+	recipient, err := d.resolver.Get(ctx, notification.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve recipient: %w", err)
+	}
+
+	decision, channels := EvaluatePreferences(recipient, notification.Category, d.critical[notification.Category], d.now())
+	if decision.Status != StatusDelivered {
+		return decision, nil
+	}
+
+	// Канал, отключенный circuit breaker, пропускается в пользу следующего по приоритету
+	var circuitErr error
+	for _, channel := range channels {
+		if _, ok := d.notifiers[channel]; !ok || !shared.AllowsChannel(notification.Channels, channel) {
+			continue
+		}
+
+		logger.WithContext(ctx, d.logger).Info("Resolved delivery channel",
+			zap.String("userId", recipient.ID),
+			zap.String("category", notification.Category),
+			zap.String("channel", channel))
+
+		if err := d.send(ctx, channel, &recipient.Contacts, notification.Text); err != nil {
+			if errors.Is(err, ErrCircuitOpen) {
+				if circuitErr == nil {
+					circuitErr = err
+				}
+				continue
+			}
+			return nil, err
+		}
+		return &DeliveryResult{Status: StatusDelivered, Channel: channel}, nil
+	}
+
+	if circuitErr != nil {
+		return nil, circuitErr
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoDeliveryChannel, notification.UserID)
+}
+
+// Breakers возвращает состояние circuit breaker каналов, упорядоченное по имени канала
+func (d *Dispatcher) Breakers() []BreakerStatus {
+	statuses := make([]BreakerStatus, 0, len(d.notifiers))
+	for _, notifier := range d.notifiers {
+		if n, ok := notifier.(*BreakerNotifier); ok {
+			statuses = append(statuses, n.Breaker().Status())
+		}
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Channel < statuses[j].Channel })
+	return statuses
+}
+
+// WatchBreakers задает наблюдателя смены состояния circuit breaker всех каналов
+func (d *Dispatcher) WatchBreakers(watch func(channel, state string)) {
+	for _, notifier := range d.notifiers {
+		if n, ok := notifier.(*BreakerNotifier); ok {
+			n.Breaker().Watch(watch)
+		}
+	}
+}
+
+// send отправляет текст через указанный канал
+func (d *Dispatcher) send(ctx context.Context, channel string, contacts *shared.ContactPoints, text string) error {
+	notifier, ok := d.notifiers[channel]
+	if !ok {
+		return fmt.Errorf("channel %s is not configured", channel)
+	}
+	return notifier.Notify(ctx, contacts, text)
+}
@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"kafka-notification-system/pkg/recipient"
+	"kafka-notification-system/pkg/shared"
+)
+
+// mockNotifier запоминает отправленные через канал сообщения
+type mockNotifier struct {
+	channel string
+	sent    []string
+	err     error
+}
+
+func (m *mockNotifier) Channel() string {
+	return m.channel
+}
+
+func (m *mockNotifier) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.sent = append(m.sent, text)
+	return nil
+}
+
+// mockResolver возвращает получателей из фиксированного набора
+type mockResolver struct {
+	recipients map[string]*shared.Recipient
+}
+
+func (m *mockResolver) Get(ctx context.Context, userID string) (*shared.Recipient, error) {
+	r, ok := m.recipients[userID]
+	if !ok {
+		return nil, recipient.ErrNotFound
+	}
+	return r, nil
+}
+
+func TestDispatcher_Dispatch_ChatID(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	dispatcher := NewDispatcher(&mockResolver{}, nil, telegram)
+
+	_, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{ChatID: 123, Text: "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(telegram.sent) != 1 {
+		t.Errorf("Expected 1 telegram message, got %d", len(telegram.sent))
+	}
+}
+
+func TestDispatcher_Dispatch_UserIDUsesChannelPriority(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	email := &mockNotifier{channel: shared.ChannelEmail}
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {
+			ID:       "user-1",
+			Contacts: shared.ContactPoints{TelegramChatID: 123, Email: "user@example.com"},
+			Channels: []string{shared.ChannelEmail, shared.ChannelTelegram},
+		},
+	}}
+	dispatcher := NewDispatcher(resolver, nil, telegram, email)
+
+	_, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{UserID: "user-1", Text: "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(email.sent) != 1 {
+		t.Errorf("Expected 1 email message, got %d", len(email.sent))
+	}
+	if len(telegram.sent) != 0 {
+		t.Errorf("Expected no telegram messages, got %d", len(telegram.sent))
+	}
+}
+
+func TestDispatcher_Dispatch_SkipsUnconfiguredChannels(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {
+			ID:       "user-1",
+			Contacts: shared.ContactPoints{TelegramChatID: 123, SlackUserID: "U1"},
+			Channels: []string{shared.ChannelSlack, shared.ChannelTelegram},
+		},
+	}}
+	dispatcher := NewDispatcher(resolver, nil, telegram)
+
+	_, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{UserID: "user-1", Text: "hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(telegram.sent) != 1 {
+		t.Errorf("Expected fallback to telegram, got %d messages", len(telegram.sent))
+	}
+}
+
+func TestDispatcher_Dispatch_RestrictedChannels(t *testing.T) {
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	email := &mockNotifier{channel: shared.ChannelEmail}
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {
+			ID:       "user-1",
+			Contacts: shared.ContactPoints{TelegramChatID: 123, Email: "user@example.com"},
+		},
+	}}
+	dispatcher := NewDispatcher(resolver, nil, telegram, email)
+
+	result, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{
+		UserID:   "user-1",
+		Text:     "hello",
+		Channels: []string{shared.ChannelEmail},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Channel != shared.ChannelEmail || len(telegram.sent) != 0 {
+		t.Errorf("Expected delivery by email only, got %s and %d telegram messages", result.Channel, len(telegram.sent))
+	}
+
+	_, err = dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{
+		ChatID:   123,
+		Text:     "hello",
+		Channels: []string{shared.ChannelEmail},
+	})
+	if !errors.Is(err, ErrNoDeliveryChannel) {
+		t.Errorf("Expected ErrNoDeliveryChannel for chat without telegram, got %v", err)
+	}
+	// Текст ошибки попадает в заголовки dead-letter, поэтому не содержит идентификатор чата
+	if err != nil && strings.Contains(err.Error(), "123") {
+		t.Errorf("Expected error without chat id, got %v", err)
+	}
+}
+
+func TestDispatcher_Dispatch_UnknownUser(t *testing.T) {
+	dispatcher := NewDispatcher(&mockResolver{}, nil, &mockNotifier{channel: shared.ChannelTelegram})
+
+	_, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{UserID: "missing", Text: "hello"})
+	if !errors.Is(err, recipient.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDispatcher_Dispatch_NoChannel(t *testing.T) {
+	resolver := &mockResolver{recipients: map[string]*shared.Recipient{
+		"user-1": {ID: "user-1", Contacts: shared.ContactPoints{Email: "user@example.com"}},
+	}}
+	dispatcher := NewDispatcher(resolver, nil, &mockNotifier{channel: shared.ChannelTelegram})
+
+	_, err := dispatcher.Dispatch(context.Background(), &shared.NotificationMessage{UserID: "user-1", Text: "hello"})
+	if !errors.Is(err, ErrNoDeliveryChannel) {
+		t.Errorf("Expected ErrNoDeliveryChannel, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// ErrPolicyNotFound возвращается, если политика эскалации не найдена
+var ErrPolicyNotFound = errors.New("escalation policy not found")
+
+// PolicyStore хранит политики эскалации в памяти и сохраняет их на диск
+type PolicyStore struct {
+	mu       sync.RWMutex
+	policies map[string]*shared.EscalationPolicy
+	file     *storage.JSONFile
+	logger   *zap.Logger
+}
+
+// NewPolicyStore создает новый экземпляр PolicyStore и загружает сохраненные политики
+func NewPolicyStore(path string) (*PolicyStore, error) {
+	s := &PolicyStore{
+		policies: make(map[string]*shared.EscalationPolicy),
+		file:     storage.NewJSONFile(path),
+		logger:   logger.GetLogger(),
+	}
+
+	var saved []*shared.EscalationPolicy
+	if err := s.file.Load(&saved); err != nil {
+		return nil, err
+	}
+	for _, p := range saved {
+		s.policies[p.Name] = p
+	}
+
+	s.logger.Info("Escalation policies loaded",
+		zap.String("path", path),
+		zap.Int("policies", len(s.policies)))
+
+	return s, nil
+}
+
+// List возвращает все политики, отсортированные по названию
+func (s *PolicyStore) List() []*shared.EscalationPolicy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*shared.EscalationPolicy, 0, len(s.policies))
+	for _, p := range s.policies {
+		copied := *p
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Get возвращает политику по названию
+func (s *PolicyStore) Get(name string) (*shared.EscalationPolicy, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.policies[name]
+	if !ok {
+		return nil, ErrPolicyNotFound
+	}
+	copied := *p
+	return &copied, nil
+}
+
+// Put создает или заменяет политику. Эскалации, начатые по прежней версии,
+// продолжаются по новым шагам
+func (s *PolicyStore) Put(name string, p *shared.EscalationPolicy) (*shared.EscalationPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := shared.GetCurrentTimestamp()
+	existing, ok := s.policies[name]
+	p.Name = name
+	p.CreatedAt = now
+	if ok {
+		p.CreatedAt = existing.CreatedAt
+	}
+	p.UpdatedAt = now
+	s.policies[name] = p
+
+	if err := s.persist(); err != nil {
+		if ok {
+			s.policies[name] = existing
+		} else {
+			delete(s.policies, name)
+		}
+		return nil, err
+	}
+
+	copied := *p
+	return &copied, nil
+}
+
+// Delete удаляет политику
+func (s *PolicyStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.policies[name]
+	if !ok {
+		return ErrPolicyNotFound
+	}
+	delete(s.policies, name)
+
+	if err := s.persist(); err != nil {
+		s.policies[name] = existing
+		return err
+	}
+	return nil
+}
+
+// persist сохраняет политики на диск. Вызывается под блокировкой
+func (s *PolicyStore) persist() error {
+	snapshot := make([]*shared.EscalationPolicy, 0, len(s.policies))
+	for _, p := range s.policies {
+		snapshot = append(snapshot, p)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+
+	if err := s.file.Save(snapshot); err != nil {
+		s.logger.Error("Failed to persist escalation policies", zap.Error(err))
+		return fmt.Errorf("failed to persist escalation policies: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// EvaluatePreferences применяет настройки получателя к уведомлению указанной
+// категории. Возвращает решение о доставке и каналы в порядке приоритета.
+// Отказ от рассылки соблюдается всегда, критические категории игнорируют
+// только окно тишины
+func EvaluatePreferences(r *shared.Recipient, category string, critical bool, now time.Time) (*DeliveryResult, []string) {
+	prefs := r.Preferences
+
+	if prefs.OptOut {
+		return &DeliveryResult{Status: StatusSuppressed, Reason: "recipient opted out"}, nil
+	}
+
+	channels := r.DeliveryChannels()
+	if pref, ok := prefs.Categories[category]; ok && category != "" {
+		if pref.Muted {
+			return &DeliveryResult{Status: StatusSuppressed, Reason: "category " + category + " is muted"}, nil
+		}
+		if len(pref.Channels) > 0 {
+			channels = filterChannels(pref.Channels, &r.Contacts)
+		}
+	}
+
+	if prefs.QuietHours != nil && !critical {
+		if until, active := prefs.QuietHours.ActiveUntil(now); active {
+			return &DeliveryResult{
+				Status:     StatusDeferred,
+				Reason:     "quiet hours",
+				DeferUntil: until,
+			}, nil
+		}
+	}
+
+	return &DeliveryResult{Status: StatusDelivered}, channels
+}
+
+// filterChannels оставляет только каналы, для которых у получателя есть контакт
+func filterChannels(channels []string, contacts *shared.ContactPoints) []string {
+	result := make([]string, 0, len(channels))
+	for _, channel := range channels {
+		if contacts.HasContact(channel) {
+			result = append(result, channel)
+		}
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+func newTestRecipient(prefs shared.Preferences) *shared.Recipient {
+	return &shared.Recipient{
+		ID:          "user-1",
+		Contacts:    shared.ContactPoints{TelegramChatID: 123, Email: "user@example.com"},
+		Preferences: prefs,
+	}
+}
+
+func TestEvaluatePreferences_OptOut(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{OptOut: true})
+
+	result, _ := EvaluatePreferences(r, "security", true, time.Now())
+	if result.Status != StatusSuppressed {
+		t.Errorf("Expected status %s, got %s", StatusSuppressed, result.Status)
+	}
+}
+
+func TestEvaluatePreferences_MutedCategory(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{
+		Categories: map[string]shared.CategoryPreference{"marketing": {Muted: true}},
+	})
+
+	result, _ := EvaluatePreferences(r, "marketing", false, time.Now())
+	if result.Status != StatusSuppressed {
+		t.Errorf("Expected status %s, got %s", StatusSuppressed, result.Status)
+	}
+
+	result, _ = EvaluatePreferences(r, "billing", false, time.Now())
+	if result.Status != StatusDelivered {
+		t.Errorf("Expected status %s for other category, got %s", StatusDelivered, result.Status)
+	}
+}
+
+func TestEvaluatePreferences_CategoryChannels(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{
+		Categories: map[string]shared.CategoryPreference{
+			"billing": {Channels: []string{shared.ChannelEmail, shared.ChannelSlack}},
+		},
+	})
+
+	_, channels := EvaluatePreferences(r, "billing", false, time.Now())
+	if len(channels) != 1 || channels[0] != shared.ChannelEmail {
+		t.Errorf("Expected [email], got %v", channels)
+	}
+
+	_, channels = EvaluatePreferences(r, "", false, time.Now())
+	if len(channels) != 2 || channels[0] != shared.ChannelTelegram {
+		t.Errorf("Expected default channel priority, got %v", channels)
+	}
+}
+
+func TestEvaluatePreferences_QuietHours(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{
+		QuietHours: &shared.QuietHours{Start: "22:00", End: "08:00", TimeZone: "UTC"},
+	})
+
+	night := time.Date(2024, 1, 10, 23, 30, 0, 0, time.UTC)
+	result, _ := EvaluatePreferences(r, "marketing", false, night)
+	if result.Status != StatusDeferred {
+		t.Fatalf("Expected status %s, got %s", StatusDeferred, result.Status)
+	}
+
+	expected := time.Date(2024, 1, 11, 8, 0, 0, 0, time.UTC)
+	if !result.DeferUntil.Equal(expected) {
+		t.Errorf("Expected defer until %v, got %v", expected, result.DeferUntil)
+	}
+
+	earlyMorning := time.Date(2024, 1, 11, 6, 0, 0, 0, time.UTC)
+	result, _ = EvaluatePreferences(r, "marketing", false, earlyMorning)
+	if result.Status != StatusDeferred || !result.DeferUntil.Equal(expected) {
+		t.Errorf("Expected deferral until %v, got %s %v", expected, result.Status, result.DeferUntil)
+	}
+
+	day := time.Date(2024, 1, 11, 12, 0, 0, 0, time.UTC)
+	result, _ = EvaluatePreferences(r, "marketing", false, day)
+	if result.Status != StatusDelivered {
+		t.Errorf("Expected status %s outside quiet hours, got %s", StatusDelivered, result.Status)
+	}
+}
+
+func TestEvaluatePreferences_QuietHoursTimeZone(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{
+		QuietHours: &shared.QuietHours{Start: "22:00", End: "08:00", TimeZone: "Asia/Tokyo"},
+	})
+
+	// 14:00 UTC = 23:00 в Токио
+	now := time.Date(2024, 1, 10, 14, 0, 0, 0, time.UTC)
+	result, _ := EvaluatePreferences(r, "", false, now)
+	if result.Status != StatusDeferred {
+		t.Fatalf("Expected status %s, got %s", StatusDeferred, result.Status)
+	}
+
+	// 08:00 в Токио = 23:00 UTC
+	expected := time.Date(2024, 1, 10, 23, 0, 0, 0, time.UTC)
+	if !result.DeferUntil.Equal(expected) {
+		t.Errorf("Expected defer until %v, got %v", expected, result.DeferUntil.UTC())
+	}
+}
+
+func TestEvaluatePreferences_CriticalBypassesQuietHours(t *testing.T) {
+	r := newTestRecipient(shared.Preferences{
+		QuietHours: &shared.QuietHours{Start: "00:00", End: "23:59", TimeZone: "UTC"},
+	})
+
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	result, _ := EvaluatePreferences(r, "security", true, now)
+	if result.Status != StatusDelivered {
+		t.Errorf("Expected critical notification to be delivered, got %s", result.Status)
+	}
+}
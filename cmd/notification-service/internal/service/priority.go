@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageReader определяет интерфейс чтения сообщений одного топика с ручной фиксацией offset
+type MessageReader interface {
+	FetchMessage(ctx context.Context) (kafka.Message, error)
+	CommitMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// lane представляет очередь одного приоритета: отдельный reader и буфер
+// уже прочитанных, но еще не обработанных сообщений
+type lane struct {
+	priority string
+	topic    string
+	reader   MessageReader
+	messages chan kafka.Message
+	poll     *health.PollTracker
+}
+
+// laneScheduler выбирает очередь, из которой обрабатывается следующее сообщение.
+// Очереди упорядочены по убыванию приоритета. В режиме strict всегда выбирается
+// самая приоритетная непустая очередь. В режиме weighted очереди получают доли
+// согласно весам: за раунд очередь i обрабатывает до weights[i] сообщений,
+// а свободная доля пустой очереди отдается остальным
+type laneScheduler struct {
+	strict  bool
+	weights []int
+	credits []int
+}
+
+// newLaneScheduler создает планировщик очередей для режима и весов из конфигурации
+func newLaneScheduler(mode string, weights []int) *laneScheduler {
+	return &laneScheduler{
+		strict:  mode == config.PriorityModeStrict,
+		weights: weights,
+		credits: append([]int(nil), weights...),
+	}
+}
+
+// next возвращает индекс очереди для следующего сообщения или -1,
+// если ни в одной очереди нет готовых сообщений
+func (s *laneScheduler) next(ready []bool) int {
+	if s.strict {
+		for i, ok := range ready {
+			if ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	empty := true
+	for _, ok := range ready {
+		if ok {
+			empty = false
+			break
+		}
+	}
+	if empty {
+		return -1
+	}
+
+	for round := 0; round < 2; round++ {
+		for i, ok := range ready {
+			if ok && s.credits[i] > 0 {
+				s.credits[i]--
+				return i
+			}
+		}
+		// У непустых очередей закончились доли — начинаем новый раунд
+		copy(s.credits, s.weights)
+	}
+	return -1
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/consumer"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+func TestLaneScheduler_Strict(t *testing.T) {
+	scheduler := newLaneScheduler(config.PriorityModeStrict, []int{6, 3, 1})
+
+	for i := 0; i < 10; i++ {
+		if lane := scheduler.next([]bool{true, true, true}); lane != 0 {
+			t.Fatalf("Expected high priority lane, got %d", lane)
+		}
+	}
+	if lane := scheduler.next([]bool{false, false, true}); lane != 2 {
+		t.Errorf("Expected low priority lane, got %d", lane)
+	}
+	if lane := scheduler.next([]bool{false, false, false}); lane != -1 {
+		t.Errorf("Expected no lane, got %d", lane)
+	}
+}
+
+func TestLaneScheduler_Weighted(t *testing.T) {
+	scheduler := newLaneScheduler(config.PriorityModeWeighted, []int{6, 3, 1})
+
+	counts := make([]int, 3)
+	for i := 0; i < 100; i++ {
+		counts[scheduler.next([]bool{true, true, true})]++
+	}
+
+	if counts[0] != 60 || counts[1] != 30 || counts[2] != 10 {
+		t.Errorf("Expected 60/30/10 split, got %v", counts)
+	}
+}
+
+func TestLaneScheduler_WeightedGivesIdleShareToOthers(t *testing.T) {
+	scheduler := newLaneScheduler(config.PriorityModeWeighted, []int{6, 3, 1})
+
+	for i := 0; i < 20; i++ {
+		if lane := scheduler.next([]bool{false, false, true}); lane != 2 {
+			t.Fatalf("Expected low priority lane when others are empty, got %d", lane)
+		}
+	}
+}
+
+// fakeReader отдает сообщения из фиксированного списка и запоминает зафиксированные
+type fakeReader struct {
+	messages  chan kafka.Message
+	mu        sync.Mutex
+	committed []kafka.Message
+}
+
+func newFakeReader(values ...string) *fakeReader {
+	r := &fakeReader{messages: make(chan kafka.Message, len(values))}
+	for _, v := range values {
+		r.messages <- kafka.Message{Value: []byte(v)}
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case m := <-r.messages:
+		return m, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.committed = append(r.committed, msgs...)
+	return nil
+}
+
+// committedOffsets возвращает зафиксированные offset
+func (r *fakeReader) committedOffsets() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	offsets := make([]int64, 0, len(r.committed))
+	for _, m := range r.committed {
+		offsets = append(offsets, m.Offset)
+	}
+	return offsets
+}
+
+func (r *fakeReader) Close() error {
+	return nil
+}
+
+func TestKafkaService_NextMessage_PrefersHighPriority(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	high := newFakeReader("h1")
+	low := newFakeReader("l1", "l2")
+	service := &KafkaService{
+		lanes: []*lane{
+			{priority: "high", reader: high, messages: make(chan kafka.Message, 1)},
+			{priority: "low", reader: low, messages: make(chan kafka.Message, 1)},
+		},
+		lanesScheduler: newLaneScheduler(config.PriorityModeStrict, []int{1, 1}),
+		wake:           make(chan struct{}, 1),
+		control:        consumer.NewControl(),
+		logger:         zap.NewNop(),
+	}
+
+	// Заполняем буферы очередей до запуска обработки
+	for _, l := range service.lanes {
+		m, _ := l.reader.FetchMessage(ctx)
+		l.messages <- m
+	}
+	for _, l := range service.lanes {
+		go service.fetch(ctx, l)
+	}
+
+	var order []string
+	for i := 0; i < 3; i++ {
+		_, message, err := service.nextMessage(ctx)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		order = append(order, string(message.Value))
+	}
+
+	if order[0] != "h1" || order[1] != "l1" || order[2] != "l2" {
+		t.Errorf("Expected high priority message first, got %v", order)
+	}
+}
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"github.com/segmentio/kafka-go"
+	"go.uber.org/zap"
+)
+
+// Ошибки повторной публикации
+var (
+	ErrReplaySelection = errors.New("either ids or a non-empty filter is required")
+	ErrEditNotSelected = errors.New("payload edits are allowed only for messages listed in ids")
+	ErrInvalidEdit     = errors.New("invalid edited payload")
+	ErrNothingToReplay = errors.New("no dead-letter messages match the request")
+)
+
+// ReplayRequest описывает повторную публикацию сообщений из dead letter topic:
+// выбранных по идентификаторам или по фильтру. Edits заменяет payload выбранных
+// сообщений перед публикацией
+type ReplayRequest struct {
+	IDs    []string                   `json:"ids,omitempty"`
+	Filter *DeadLetterFilter          `json:"filter,omitempty"`
+	Edits  map[string]json.RawMessage `json:"edits,omitempty"`
+	Force  bool                       `json:"force,omitempty"`
+	Actor  string                     `json:"actor,omitempty"`
+	Reason string                     `json:"reason,omitempty"`
+}
+
+// ReplaySkip описывает сообщение, пропущенное при повторной публикации
+type ReplaySkip struct {
+	ID     string `json:"id"`
+	Reason string `json:"reason"`
+}
+
+// ReplayResult содержит итог повторной публикации
+type ReplayResult struct {
+	Replayed []string     `json:"replayed"`
+	Skipped  []ReplaySkip `json:"skipped"`
+}
+
+// ReplayAuditRecord представляет запись журнала повторных публикаций
+type ReplayAuditRecord struct {
+	Timestamp int64             `json:"timestamp"`
+	Actor     string            `json:"actor,omitempty"`
+	Reason    string            `json:"reason,omitempty"`
+	IDs       []string          `json:"ids,omitempty"`
+	Filter    *DeadLetterFilter `json:"filter,omitempty"`
+	Edited    []string          `json:"edited,omitempty"`
+	Replayed  []string          `json:"replayed"`
+	Skipped   []ReplaySkip      `json:"skipped,omitempty"`
+	Failed    []string          `json:"failed,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+// Replayer повторно публикует сообщения из dead letter topic и ведет журнал.
+// Защита от бесконечных циклов: одна запись публикуется повторно только один
+// раз (если не указан force), а сообщение, уже опубликованное повторно
+// maxReplays раз, больше не публикуется
+type Replayer struct {
+	mu         sync.Mutex
+	index      *DeadLetterIndex
+	writer     MessageWriter
+	audit      *storage.JSONLines
+	replayed   map[string]int
+	maxReplays int
+	config     *config.KafkaConfig
+	logger     *zap.Logger
+}
+
+// NewReplayer создает новый экземпляр Replayer и загружает журнал повторных публикаций
+func NewReplayer(kafkaConfig *config.KafkaConfig, index *DeadLetterIndex, auditPath string, maxReplays int) (*Replayer, error) {
+	// Топик задается для каждого сообщения по его типу и приоритету, партиция —
+	// по ключу получателя, как в основных топиках
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(kafkaConfig.Brokers...),
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireOne,
+		Async:        false,
+	}
+	return newReplayer(kafkaConfig, index, writer, storage.NewJSONLines(auditPath), maxReplays)
+}
+
+// newReplayer создает Replayer поверх произвольного MessageWriter
+func newReplayer(kafkaConfig *config.KafkaConfig, index *DeadLetterIndex, writer MessageWriter,
+	audit *storage.JSONLines, maxReplays int) (*Replayer, error) {
+	r := &Replayer{
+		index:      index,
+		writer:     writer,
+		audit:      audit,
+		replayed:   make(map[string]int),
+		maxReplays: maxReplays,
+		config:     kafkaConfig,
+		logger:     logger.GetLogger(),
+	}
+
+	err := audit.ReadAll(func(line []byte) error {
+		var record ReplayAuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		for _, id := range record.Replayed {
+			r.replayed[id]++
+		}
+		for _, id := range record.Failed {
+			r.replayed[id]--
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Replay повторно публикует выбранные сообщения и записывает операцию в журнал
+func (r *Replayer) Replay(ctx context.Context, req *ReplayRequest) (*ReplayResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entries, result, err := r.selectEntries(req)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]kafka.Message, 0, len(entries))
+	replayed := make([]string, 0, len(entries))
+	var edited []string
+	for _, entry := range entries {
+		if r.replayed[entry.ID] > 0 && !req.Force {
+			result.Skipped = append(result.Skipped, ReplaySkip{ID: entry.ID, Reason: "already replayed"})
+			continue
+		}
+		if entry.ReplayCount >= r.maxReplays {
+			result.Skipped = append(result.Skipped, ReplaySkip{
+				ID:     entry.ID,
+				Reason: fmt.Sprintf("replay limit reached (%d)", r.maxReplays),
+			})
+			continue
+		}
+
+		value := entry.value
+		if edit, ok := req.Edits[entry.ID]; ok {
+			if value, err = editPayload(entry, edit); err != nil {
+				return nil, fmt.Errorf("%w for %s: %v", ErrInvalidEdit, entry.ID, err)
+			}
+			edited = append(edited, entry.ID)
+		}
+
+		messages = append(messages, r.replayMessage(entry, value))
+		replayed = append(replayed, entry.ID)
+	}
+
+	// Журнал пишется до публикации: операция без записи в журнале не выполняется
+	record := ReplayAuditRecord{
+		Timestamp: shared.GetCurrentTimestamp(),
+		Actor:     req.Actor,
+		Reason:    req.Reason,
+		IDs:       req.IDs,
+		Filter:    req.Filter,
+		Edited:    edited,
+		Replayed:  replayed,
+		Skipped:   result.Skipped,
+	}
+	if err := r.audit.Append(record); err != nil {
+		return nil, fmt.Errorf("failed to write replay audit record: %w", err)
+	}
+
+	if len(messages) > 0 {
+		if err := r.writer.WriteMessages(ctx, messages...); err != nil {
+			// Отмечаем в журнале, что публикация не состоялась
+			failure := ReplayAuditRecord{
+				Timestamp: shared.GetCurrentTimestamp(),
+				Actor:     req.Actor,
+				Replayed:  []string{},
+				Failed:    replayed,
+				Error:     err.Error(),
+			}
+			if auditErr := r.audit.Append(failure); auditErr != nil {
+				r.logger.Error("Failed to write replay audit record", zap.Error(auditErr))
+			}
+			return nil, fmt.Errorf("failed to publish replayed messages: %w", err)
+		}
+	}
+
+	for _, id := range replayed {
+		r.replayed[id]++
+	}
+
+	result.Replayed = replayed
+	r.logger.Info("Dead-letter messages replayed",
+		zap.String("actor", req.Actor),
+		zap.String("reason", req.Reason),
+		zap.Int("replayed", len(replayed)),
+		zap.Int("skipped", len(result.Skipped)))
+	return result, nil
+}
+
+// Audit возвращает последние limit записей журнала, начиная с самых новых
+func (r *Replayer) Audit(limit int) ([]ReplayAuditRecord, error) {
+	var records []ReplayAuditRecord
+	err := r.audit.ReadAll(func(line []byte) error {
+		var record ReplayAuditRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	if limit > 0 && len(records) > limit {
+		records = records[:limit]
+	}
+	return records, nil
+}
+
+// Close закрывает соединение с Kafka
+func (r *Replayer) Close() error {
+	return r.writer.Close()
+}
+
+// selectEntries выбирает записи по идентификаторам или по фильтру
+func (r *Replayer) selectEntries(req *ReplayRequest) ([]*DeadLetterEntry, *ReplayResult, error) {
+	result := &ReplayResult{Replayed: []string{}, Skipped: []ReplaySkip{}}
+
+	if len(req.IDs) == 0 {
+		if req.Filter == nil || req.Filter.IsEmpty() {
+			return nil, nil, ErrReplaySelection
+		}
+		if len(req.Edits) > 0 {
+			return nil, nil, ErrEditNotSelected
+		}
+		entries := r.index.List(*req.Filter)
+		if len(entries) == 0 {
+			return nil, nil, ErrNothingToReplay
+		}
+		// Публикуем в исходном порядке: от старых к новым
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+		return entries, result, nil
+	}
+
+	selected := make(map[string]bool, len(req.IDs))
+	entries := make([]*DeadLetterEntry, 0, len(req.IDs))
+	for _, id := range req.IDs {
+		if selected[id] {
+			continue
+		}
+		selected[id] = true
+
+		entry, ok := r.index.Get(id)
+		if !ok {
+			result.Skipped = append(result.Skipped, ReplaySkip{ID: id, Reason: "not found"})
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	for id := range req.Edits {
+		if !selected[id] {
+			return nil, nil, ErrEditNotSelected
+		}
+	}
+	if len(entries) == 0 {
+		return nil, nil, ErrNothingToReplay
+	}
+	return entries, result, nil
+}
+
+// replayMessage формирует сообщение для повторной публикации. Заголовки ошибки
+// и повторной обработки удаляются, чтобы сообщение прошло все уровни заново,
+// а счетчик повторных публикаций увеличивается
+func (r *Replayer) replayMessage(entry *DeadLetterEntry, value []byte) kafka.Message {
+	headers := make([]kafka.Header, 0, len(entry.headers)+2)
+	for _, h := range entry.headers {
+		if isFailureHeader(h.Key) {
+			continue
+		}
+		headers = append(headers, h)
+	}
+	headers = append(headers,
+		kafka.Header{Key: shared.HeaderReplayCount, Value: []byte(strconv.Itoa(entry.ReplayCount + 1))},
+		kafka.Header{Key: shared.HeaderReplayedFrom, Value: []byte(entry.ID)},
+	)
+
+	topic := r.config.PriorityTopic(headerValue(entry.headers, shared.HeaderPriority))
+	if entry.Type == shared.MessageTypeBroadcast {
+		topic = r.config.BroadcastsTopic
+	}
+
+	// Payload мог быть исправлен, поэтому ключ получателя вычисляется заново
+	message := kafka.Message{
+		Topic:   topic,
+		Key:     []byte(entry.Key),
+		Value:   value,
+		Headers: headers,
+	}
+	message.Key = []byte(recipientKey(message))
+	return message
+}
+
+// editPayload заменяет payload в конверте сообщения и проверяет результат
+func editPayload(entry *DeadLetterEntry, payload json.RawMessage) ([]byte, error) {
+	envelope, err := shared.FromJSON(entry.value)
+	if err != nil {
+		return nil, fmt.Errorf("original message is not a valid envelope: %w", err)
+	}
+
+	var decoded interface{}
+	if err := json.Unmarshal(payload, &decoded); err != nil {
+		return nil, err
+	}
+
+	switch envelope.Type {
+	case shared.MessageTypeNotification:
+		err = shared.ValidateNotificationPayload(decoded)
+	case shared.MessageTypeBroadcast:
+		err = shared.ValidateBroadcastPayload(decoded)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	envelope.Payload = decoded
+	return envelope.ToJSON()
+}
+
+// isFailureHeader проверяет, что заголовок описывает прошлую ошибку или повторную обработку
+func isFailureHeader(key string) bool {
+	return strings.HasPrefix(key, "error-") || strings.HasPrefix(key, "source-") ||
+		strings.HasPrefix(key, "retry-") || key == shared.HeaderReplayCount || key == shared.HeaderReplayedFrom
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"github.com/segmentio/kafka-go"
+)
+
+var replayTestConfig = &config.KafkaConfig{
+	NotificationsTopic: "notifications",
+	HighPriorityTopic:  "notifications-high",
+	LowPriorityTopic:   "notifications-low",
+	BroadcastsTopic:    "broadcasts",
+}
+
+func newTestReplayer(t *testing.T, auditPath string, index *DeadLetterIndex) (*Replayer, *recordingWriter) {
+	writer := &recordingWriter{}
+	replayer, err := newReplayer(replayTestConfig, index, writer, storage.NewJSONLines(auditPath), 2)
+	if err != nil {
+		t.Fatalf("Failed to create replayer: %v", err)
+	}
+	return replayer, writer
+}
+
+func notificationValue(t *testing.T, text string) string {
+	value, err := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 1, Text: text}).ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to marshal notification: %v", err)
+	}
+	return string(value)
+}
+
+func TestReplayer_ReplayByIDs(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+	index.Add(deadLetterMessage(1, "notification", "url.Error", time.Now(), notificationValue(t, "hello")))
+	replayer, writer := newTestReplayer(t, filepath.Join(t.TempDir(), "audit.jsonl"), index)
+
+	result, err := replayer.Replay(context.Background(), &ReplayRequest{IDs: []string{"0-1", "0-9"}, Actor: "ops"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(result.Replayed) != 1 || result.Replayed[0] != "0-1" {
+		t.Errorf("Expected 0-1 to be replayed, got %v", result.Replayed)
+	}
+	if len(result.Skipped) != 1 || result.Skipped[0].ID != "0-9" {
+		t.Errorf("Expected unknown id to be skipped, got %v", result.Skipped)
+	}
+
+	message := writer.messages[0]
+	if message.Topic != "notifications" {
+		t.Errorf("Expected notifications topic, got %s", message.Topic)
+	}
+	if headerValue(message.Headers, shared.HeaderReplayCount) != "1" {
+		t.Errorf("Expected replay count 1, got %q", headerValue(message.Headers, shared.HeaderReplayCount))
+	}
+	if headerValue(message.Headers, shared.HeaderReplayedFrom) != "0-1" {
+		t.Errorf("Expected replayed-from header, got %q", headerValue(message.Headers, shared.HeaderReplayedFrom))
+	}
+	for _, key := range []string{shared.HeaderErrorClass, shared.HeaderRetryAttempt, shared.HeaderErrorTimestamp} {
+		if headerValue(message.Headers, key) != "" {
+			t.Errorf("Expected header %s to be removed", key)
+		}
+	}
+}
+
+func TestReplayer_SkipsAlreadyReplayedAcrossRestart(t *testing.T) {
+	auditPath := filepath.Join(t.TempDir(), "audit.jsonl")
+	index := NewDeadLetterIndex(0, 0)
+	index.Add(deadLetterMessage(1, "notification", "url.Error", time.Now(), notificationValue(t, "hello")))
+
+	replayer, _ := newTestReplayer(t, auditPath, index)
+	if _, err := replayer.Replay(context.Background(), &ReplayRequest{IDs: []string{"0-1"}}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	restarted, writer := newTestReplayer(t, auditPath, index)
+	result, err := restarted.Replay(context.Background(), &ReplayRequest{Filter: &DeadLetterFilter{Type: "notification"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(writer.messages) != 0 || len(result.Skipped) != 1 || result.Skipped[0].Reason != "already replayed" {
+		t.Errorf("Expected replayed entry to be skipped, got %+v", result)
+	}
+
+	result, err = restarted.Replay(context.Background(), &ReplayRequest{IDs: []string{"0-1"}, Force: true})
+	if err != nil || len(result.Replayed) != 1 {
+		t.Errorf("Expected forced replay, got %+v, %v", result, err)
+	}
+
+	records, err := restarted.Audit(0)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(records) != 3 {
+		t.Errorf("Expected 3 audit records, got %d", len(records))
+	}
+}
+
+func TestReplayer_StopsAtReplayLimit(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+	message := deadLetterMessage(5, "notification", "url.Error", time.Now(), notificationValue(t, "hello"))
+	message.Headers = append(message.Headers, kafka.Header{Key: shared.HeaderReplayCount, Value: []byte("2")})
+	index.Add(message)
+	replayer, writer := newTestReplayer(t, filepath.Join(t.TempDir(), "audit.jsonl"), index)
+
+	result, err := replayer.Replay(context.Background(), &ReplayRequest{IDs: []string{"0-5"}})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(writer.messages) != 0 || len(result.Skipped) != 1 {
+		t.Errorf("Expected message at replay limit to be skipped, got %+v", result)
+	}
+}
+
+func TestReplayer_EditPayload(t *testing.T) {
+	index := NewDeadLetterIndex(0, 0)
+	index.Add(deadLetterMessage(1, "notification", "url.Error", time.Now(), notificationValue(t, "hello")))
+	replayer, writer := newTestReplayer(t, filepath.Join(t.TempDir(), "audit.jsonl"), index)
+
+	_, err := replayer.Replay(context.Background(), &ReplayRequest{
+		IDs:   []string{"0-1"},
+		Edits: map[string]json.RawMessage{"0-1": json.RawMessage(`{"text":"missing target"}`)},
+	})
+	if !errors.Is(err, ErrInvalidEdit) {
+		t.Fatalf("Expected invalid edit error, got %v", err)
+	}
+
+	_, err = replayer.Replay(context.Background(), &ReplayRequest{
+		IDs:   []string{"0-1"},
+		Edits: map[string]json.RawMessage{"0-1": json.RawMessage(`{"chatId":2,"text":"fixed"}`)},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	envelope, err := shared.FromJSON(writer.messages[0].Value)
+	if err != nil {
+		t.Fatalf("Failed to parse replayed message: %v", err)
+	}
+	notification, _ := envelope.GetNotificationPayload()
+	if notification.ChatID != 2 || notification.Text != "fixed" {
+		t.Errorf("Expected edited payload, got %+v", notification)
+	}
+	// Ключ следует за исправленным получателем
+	if key := string(writer.messages[0].Key); key != "chat:2" {
+		t.Errorf("Expected recipient key chat:2, got %s", key)
+	}
+}
+
+func TestReplayer_RequiresSelection(t *testing.T) {
+	replayer, _ := newTestReplayer(t, filepath.Join(t.TempDir(), "audit.jsonl"), NewDeadLetterIndex(0, 0))
+
+	if _, err := replayer.Replay(context.Background(), &ReplayRequest{Filter: &DeadLetterFilter{}}); !errors.Is(err, ErrReplaySelection) {
+		t.Errorf("Expected selection error, got %v", err)
+	}
+	if _, err := replayer.Replay(context.Background(), &ReplayRequest{IDs: []string{"0-1"}}); !errors.Is(err, ErrNothingToReplay) {
+		t.Errorf("Expected nothing to replay, got %v", err)
+	}
+}
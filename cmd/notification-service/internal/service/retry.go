@@ -0,0 +1,260 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/tracing"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.uber.org/zap"
+)
+
+// Пауза между попытками передать сообщение дальше, если Kafka недоступна
+const (
+	handOffBackoff    = time.Second
+	maxHandOffBackoff = 30 * time.Second
+)
+
+// errInvalidMessage означает, что сообщение невозможно обработать повторно:
+// такие сообщения сразу уходят в dead letter topic
+var errInvalidMessage = errors.New("invalid message")
+
+// MessageWriter определяет интерфейс публикации сообщений в Kafka
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// retryTier представляет уровень повторной обработки и его reader
+type retryTier struct {
+	config.RetryTier
+	reader MessageReader
+	poll   *health.PollTracker
+}
+
+// consumeRetries обрабатывает сообщения уровня повторной обработки. Сообщения
+// уровня имеют одинаковую задержку и идут в порядке срока, поэтому consumer
+// ждет срока первого сообщения, не блокируя основные очереди. Пауза и drain
+// действуют так же, как для основных очередей
+func (s *KafkaService) consumeRetries(ctx context.Context, tier *retryTier) {
+	for {
+		tier.poll.Waiting()
+		if s.control.Wait(ctx) != nil {
+			return
+		}
+
+		message, err := tier.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			s.logger.Error("Failed to read retry message", zap.String("topic", tier.Topic), zap.Error(err))
+			continue
+		}
+
+		// Ожидание срока повторной попытки — штатный простой, а не зависание
+		if wait := time.Until(retryDueAt(message)); wait > 0 {
+			tier.poll.Waiting()
+			timer := time.NewTimer(wait)
+			select {
+			case <-ctx.Done():
+				// Offset не фиксирован: после перезапуска сообщение будет получено снова
+				timer.Stop()
+				return
+			case <-s.control.Draining():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+
+		// Пауза, начавшаяся во время ожидания срока, задерживает и это сообщение
+		if s.control.Wait(ctx) != nil {
+			return
+		}
+
+		logger.ForRequest(s.logger, headerValue(message.Headers, shared.HeaderRequestID)).Info("Retrying message",
+			zap.String("topic", tier.Topic),
+			zap.Int("attempt", retryAttempt(message)),
+			zap.String("lastError", headerValue(message.Headers, shared.HeaderRetryLastError)))
+
+		tier.poll.Polled()
+		s.control.Begin()
+		s.handleMessage(ctx, tier.reader, message)
+		s.control.Done()
+	}
+}
+
+// handleMessage обрабатывает сообщение и фиксирует offset. Если сообщение не
+// удалось ни обработать, ни передать дальше, offset не фиксируется
+func (s *KafkaService) handleMessage(ctx context.Context, reader MessageReader, message kafka.Message) {
+	if err := s.deliver(ctx, message); err != nil {
+		logger.ForRequest(s.logger, headerValue(message.Headers, shared.HeaderRequestID)).Error("Message left uncommitted",
+			zap.String("topic", message.Topic), zap.Error(err))
+		return
+	}
+
+	if err := reader.CommitMessages(ctx, message); err != nil {
+		logger.ForRequest(s.logger, headerValue(message.Headers, shared.HeaderRequestID)).Error("Failed to commit message", zap.String("topic", message.Topic), zap.Error(err))
+	}
+}
+
+// deliver обрабатывает сообщение и при ошибке передает его на повторную обработку.
+// Сообщения других типов, кроме уведомлений, учитываются как пропущенные.
+// Ошибка возвращается, только если сообщение не передано дальше до отмены контекста:
+// тогда его offset нельзя фиксировать
+func (s *KafkaService) deliver(ctx context.Context, message kafka.Message) error {
+	// Спан обработки продолжает трассировку продюсера или предыдущей попытки,
+	// а логи всех попыток помечаются идентификатором исходного запроса
+	ctx, span := tracing.StartConsumer(ctx, message)
+	ctx = logger.WithRequestID(ctx, headerValue(message.Headers, shared.HeaderRequestID))
+	span.SetAttributes(attribute.Int("messaging.retry.attempt", retryAttempt(message)))
+
+	messageType := headerValue(message.Headers, shared.HeaderMessageType)
+	err := s.processMessage(ctx, message)
+	tracing.End(span, err)
+	if err != nil {
+		logger.WithContext(ctx, s.logger).Error("Failed to process message",
+			zap.String("topic", message.Topic),
+			zap.Int("attempt", retryAttempt(message)),
+			zap.Error(err))
+		s.metrics.MessageConsumed(messageType, metrics.StatusFailed)
+		return s.handOff(ctx, message, err)
+	}
+
+	status := metrics.StatusProcessed
+	if messageType != "" && messageType != shared.MessageTypeNotification {
+		status = metrics.StatusSkipped
+	}
+	s.metrics.MessageConsumed(messageType, status)
+	return nil
+}
+
+// handOff повторяет handleFailure с растущей паузой, пока сообщение не будет
+// передано в топик повторной обработки или dead letter topic или пока не отменен контекст
+func (s *KafkaService) handOff(ctx context.Context, message kafka.Message, cause error) error {
+	backoff := handOffBackoff
+	for {
+		err := s.handleFailure(ctx, message, cause)
+		if err == nil {
+			return nil
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("failed to hand off message: %w", err)
+		case <-timer.C:
+		}
+		if backoff *= 2; backoff > maxHandOffBackoff {
+			backoff = maxHandOffBackoff
+		}
+	}
+}
+
+// handleFailure публикует сообщение в следующий уровень повторной обработки,
+// сохраняя в заголовках номер попытки и последнюю ошибку. После последнего
+// уровня, для некорректных сообщений и если топик повторной обработки недоступен —
+// отправляет в dead letter topic. Ошибка возвращается, если сообщение не
+// опубликовано никуда
+func (s *KafkaService) handleFailure(ctx context.Context, message kafka.Message, cause error) error {
+	attempt := retryAttempt(message) + 1
+	headers := setHeader(message.Headers, shared.HeaderRetryAttempt, strconv.Itoa(attempt))
+	headers = setHeader(headers, shared.HeaderRetryLastError, cause.Error())
+	if headerValue(headers, shared.HeaderRetryOriginTopic) == "" && message.Topic != "" {
+		headers = setHeader(headers, shared.HeaderRetryOriginTopic, message.Topic)
+	}
+	// Повторная попытка и dead letter продолжают трассировку неудачной обработки
+	headers = tracing.Inject(ctx, headers)
+
+	failed := message
+	failed.Headers = headers
+
+	if errors.Is(cause, errInvalidMessage) || attempt > len(s.retryTiers) {
+		return s.sendDeadLetter(ctx, failed, cause)
+	}
+
+	tier := s.retryTiers[attempt-1]
+	dueAt := time.Now().Add(tier.Delay)
+	retryMessage := kafka.Message{
+		Topic:   tier.Topic,
+		Key:     []byte(recipientKey(message)),
+		Value:   message.Value,
+		Headers: setHeader(headers, shared.HeaderRetryDueAt, strconv.FormatInt(dueAt.UnixMilli(), 10)),
+	}
+
+	if err := s.retryWriter.WriteMessages(ctx, retryMessage); err != nil {
+		logger.WithContext(ctx, s.logger).Error("Failed to send message to retry topic, sending to dead letter topic",
+			zap.String("topic", tier.Topic),
+			zap.Error(err))
+		return s.sendDeadLetter(ctx, failed, cause)
+	}
+
+	logger.WithContext(ctx, s.logger).Info("Message scheduled for retry",
+		zap.String("topic", tier.Topic),
+		zap.Int("attempt", attempt),
+		zap.Time("dueAt", dueAt))
+	s.stats.Inc(StatusRetried)
+	return nil
+}
+
+// sendDeadLetter отправляет сообщение в dead letter topic
+func (s *KafkaService) sendDeadLetter(ctx context.Context, message kafka.Message, cause error) error {
+	if err := s.deadLetterWriter.Send(ctx, message, cause); err != nil {
+		logger.WithContext(ctx, s.logger).Error("Failed to send message to dead letter topic", zap.Error(err))
+		return err
+	}
+	s.metrics.MessageConsumed(headerValue(message.Headers, shared.HeaderMessageType), metrics.StatusDeadLettered)
+	s.stats.Inc(StatusFailed)
+	return nil
+}
+
+// retryAttempt возвращает число уже выполненных повторных попыток
+func retryAttempt(message kafka.Message) int {
+	attempt, err := strconv.Atoi(headerValue(message.Headers, shared.HeaderRetryAttempt))
+	if err != nil {
+		return 0
+	}
+	return attempt
+}
+
+// retryDueAt возвращает момент, не раньше которого сообщение обрабатывается повторно
+func retryDueAt(message kafka.Message) time.Time {
+	dueAt, err := strconv.ParseInt(headerValue(message.Headers, shared.HeaderRetryDueAt), 10, 64)
+	if err != nil {
+		return time.Time{}
+	}
+	return time.UnixMilli(dueAt)
+}
+
+// headerValue возвращает значение заголовка Kafka сообщения или пустую строку
+func headerValue(headers []kafka.Header, key string) string {
+	for _, h := range headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// setHeader возвращает копию заголовков с установленным значением ключа
+func setHeader(headers []kafka.Header, key, value string) []kafka.Header {
+	result := make([]kafka.Header, 0, len(headers)+1)
+	for _, h := range headers {
+		if h.Key != key {
+			result = append(result, h)
+		}
+	}
+	return append(result, kafka.Header{Key: key, Value: []byte(value)})
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/deadletter"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// recordingWriter запоминает опубликованные сообщения
+type recordingWriter struct {
+	messages []kafka.Message
+	err      error
+}
+
+func (w *recordingWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.messages = append(w.messages, msgs...)
+	return nil
+}
+
+func (w *recordingWriter) Close() error {
+	return nil
+}
+
+func newRetryTestService() (*KafkaService, *recordingWriter, *recordingWriter) {
+	retries, deadLetters := &recordingWriter{}, &recordingWriter{}
+	service := newTestKafkaService()
+	service.retryWriter = retries
+	service.deadLetterWriter = deadletter.New(deadLetters, "notification-service")
+	service.retryTiers = []*retryTier{
+		{RetryTier: config.RetryTier{Topic: "notifications-retry-30s", Delay: 30 * time.Second}},
+		{RetryTier: config.RetryTier{Topic: "notifications-retry-5m", Delay: 5 * time.Minute}},
+	}
+	return service, retries, deadLetters
+}
+
+func TestKafkaService_HandleFailure_MovesThroughTiers(t *testing.T) {
+	service, retries, deadLetters := newRetryTestService()
+
+	message := kafka.Message{Topic: "notifications", Key: []byte("k"), Value: []byte("{}")}
+	service.handleFailure(context.Background(), message, errors.New("telegram unavailable"))
+
+	if len(retries.messages) != 1 {
+		t.Fatalf("Expected 1 retry message, got %d", len(retries.messages))
+	}
+	first := retries.messages[0]
+	if first.Topic != "notifications-retry-30s" {
+		t.Errorf("Expected first tier topic, got %s", first.Topic)
+	}
+	if attempt := retryAttempt(first); attempt != 1 {
+		t.Errorf("Expected attempt 1, got %d", attempt)
+	}
+	if lastError := headerValue(first.Headers, shared.HeaderRetryLastError); lastError != "telegram unavailable" {
+		t.Errorf("Expected last error header, got %q", lastError)
+	}
+	if origin := headerValue(first.Headers, shared.HeaderRetryOriginTopic); origin != "notifications" {
+		t.Errorf("Expected origin topic header, got %q", origin)
+	}
+	if retryDueAt(first).Before(time.Now().Add(29 * time.Second)) {
+		t.Errorf("Expected due time about 30s ahead, got %v", retryDueAt(first))
+	}
+
+	first.Topic = "notifications-retry-30s"
+	service.handleFailure(context.Background(), first, errors.New("still unavailable"))
+	if len(retries.messages) != 2 || retries.messages[1].Topic != "notifications-retry-5m" {
+		t.Fatalf("Expected second tier retry, got %+v", retries.messages)
+	}
+	if origin := headerValue(retries.messages[1].Headers, shared.HeaderRetryOriginTopic); origin != "notifications" {
+		t.Errorf("Expected origin topic to be preserved, got %q", origin)
+	}
+
+	service.handleFailure(context.Background(), retries.messages[1], errors.New("gave up"))
+	if len(deadLetters.messages) != 1 {
+		t.Fatalf("Expected message in dead letter topic after last tier, got %d", len(deadLetters.messages))
+	}
+	dead := deadLetters.messages[0]
+	if attempts := headerValue(dead.Headers, shared.HeaderErrorAttempts); attempts != strconv.Itoa(3) {
+		t.Errorf("Expected 3 attempts in dead letter, got %s", attempts)
+	}
+	if message := headerValue(dead.Headers, shared.HeaderErrorMessage); message != "gave up" {
+		t.Errorf("Expected last error in dead letter, got %q", message)
+	}
+	if source := headerValue(dead.Headers, shared.HeaderSourceTopic); source != "notifications-retry-5m" {
+		t.Errorf("Expected source topic of last attempt, got %q", source)
+	}
+}
+
+func TestKafkaService_HandleFailure_KeysRetryByRecipient(t *testing.T) {
+	service, retries, _ := newRetryTestService()
+
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{UserID: "u1", Text: "hello"})
+	value, _ := message.ToJSON()
+	service.handleFailure(context.Background(),
+		kafka.Message{Topic: "notifications", Key: []byte(message.ID), Value: value}, errors.New("smtp unavailable"))
+
+	if len(retries.messages) != 1 {
+		t.Fatalf("Expected 1 retry message, got %d", len(retries.messages))
+	}
+	if key := string(retries.messages[0].Key); key != "user:u1" {
+		t.Errorf("Expected recipient key user:u1, got %s", key)
+	}
+}
+
+func TestKafkaService_HandleFailure_InvalidMessageSkipsRetries(t *testing.T) {
+	service, retries, deadLetters := newRetryTestService()
+
+	message := kafka.Message{Topic: "notifications", Value: []byte("not json")}
+	err := service.processMessage(context.Background(), message)
+	if !errors.Is(err, errInvalidMessage) {
+		t.Fatalf("Expected invalid message error, got %v", err)
+	}
+
+	service.handleFailure(context.Background(), message, err)
+	if len(retries.messages) != 0 || len(deadLetters.messages) != 1 {
+		t.Errorf("Expected only dead letter, got %d retries and %d dead letters",
+			len(retries.messages), len(deadLetters.messages))
+	}
+}
+
+func TestKafkaService_HandleFailure_FallsBackToDeadLetter(t *testing.T) {
+	service, retries, deadLetters := newRetryTestService()
+	retries.err = errors.New("retry topic unavailable")
+
+	message := kafka.Message{Topic: "notifications", Key: []byte("k"), Value: []byte("{}")}
+	if err := service.handleFailure(context.Background(), message, errors.New("telegram unavailable")); err != nil {
+		t.Fatalf("Expected fallback to dead letter topic, got %v", err)
+	}
+	if len(deadLetters.messages) != 1 {
+		t.Fatalf("Expected message in dead letter topic, got %d", len(deadLetters.messages))
+	}
+
+	// Если недоступен и dead letter topic, offset не должен фиксироваться
+	deadLetters.err = errors.New("dead letter topic unavailable")
+	if err := service.handleFailure(context.Background(), message, errors.New("telegram unavailable")); err == nil {
+		t.Fatal("Expected error when message could not be handed off")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := service.deliver(ctx, kafka.Message{Topic: "notifications", Value: []byte("not json")}); err == nil {
+		t.Error("Expected deliver to report undelivered message")
+	}
+}
+
+func TestKafkaService_ConsumeRetries_ProcessesDueMessage(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	service := newTestKafkaService(telegram)
+
+	value, _ := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 123, Text: "hello"}).ToJSON()
+	reader := &fakeReader{messages: make(chan kafka.Message, 1)}
+	reader.messages <- kafka.Message{
+		Topic: "notifications-retry-30s",
+		Value: value,
+		Headers: []kafka.Header{
+			{Key: shared.HeaderRetryAttempt, Value: []byte("1")},
+			{Key: shared.HeaderRetryDueAt, Value: []byte(strconv.FormatInt(time.Now().UnixMilli(), 10))},
+		},
+	}
+
+	done := make(chan struct{})
+	go func() {
+		service.consumeRetries(ctx, &retryTier{RetryTier: config.RetryTier{Topic: "notifications-retry-30s"}, reader: reader})
+		close(done)
+	}()
+
+	deadline := time.After(2 * time.Second)
+	for service.Stats().Snapshot()[StatusDelivered] == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Expected retried message to be delivered")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	cancel()
+	<-done
+
+	if len(reader.committed) != 1 {
+		t.Errorf("Expected retried message to be committed, got %d commits", len(reader.committed))
+	}
+}
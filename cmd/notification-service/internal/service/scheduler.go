@@ -0,0 +1,140 @@
+package service
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// ScheduledItem представляет отложенное уведомление
+type ScheduledItem struct {
+	Message   *shared.KafkaMessage `json:"message"`
+	DueAt     int64                `json:"dueAt"`
+	Reason    string               `json:"reason"`
+	CreatedAt int64                `json:"createdAt"`
+}
+
+// Scheduler хранит отложенные уведомления на диске и возвращает их
+// в обработку по наступлении срока
+type Scheduler struct {
+	mu       sync.Mutex
+	items    []*ScheduledItem
+	file     *storage.JSONFile
+	interval time.Duration
+	logger   *zap.Logger
+}
+
+// NewScheduler создает новый экземпляр Scheduler и загружает сохраненные уведомления
+func NewScheduler(path string, interval time.Duration) (*Scheduler, error) {
+	s := &Scheduler{
+		file:     storage.NewJSONFile(path),
+		interval: interval,
+		logger:   logger.GetLogger(),
+	}
+
+	if err := s.file.Load(&s.items); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Scheduler loaded", zap.String("path", path), zap.Int("items", len(s.items)))
+	return s, nil
+}
+
+// Schedule откладывает уведомление до момента dueAt
+func (s *Scheduler) Schedule(message *shared.KafkaMessage, dueAt time.Time, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	items := append(append([]*ScheduledItem(nil), s.items...), &ScheduledItem{
+		Message:   message,
+		DueAt:     dueAt.UnixMilli(),
+		Reason:    reason,
+		CreatedAt: shared.GetCurrentTimestamp(),
+	})
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DueAt < items[j].DueAt })
+
+	if err := s.file.Save(items); err != nil {
+		return err
+	}
+	s.items = items
+
+	logger.ForRequest(s.logger, message.RequestID).Info("Notification deferred",
+		zap.String("messageId", message.ID),
+		zap.String("reason", reason),
+		zap.Time("dueAt", dueAt))
+	return nil
+}
+
+// List возвращает копию списка отложенных уведомлений
+func (s *Scheduler) List() []ScheduledItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]ScheduledItem, 0, len(s.items))
+	for _, item := range s.items {
+		result = append(result, *item)
+	}
+	return result
+}
+
+// Run периодически передает наступившие уведомления в handle до отмены контекста.
+// Уведомление удаляется из очереди только после успешного возврата из handle,
+// при ошибке оно остается в очереди до следующего прохода
+func (s *Scheduler) Run(ctx context.Context, handle func(ctx context.Context, message *shared.KafkaMessage) error) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, item := range s.due(time.Now()) {
+				if ctx.Err() != nil {
+					return
+				}
+				if err := handle(ctx, item.Message); err != nil {
+					s.logger.Error("Failed to handle deferred notification, keeping it scheduled",
+						zap.String("messageId", item.Message.ID),
+						zap.Error(err))
+					continue
+				}
+				s.remove(item)
+			}
+		}
+	}
+}
+
+// due возвращает уведомления со сроком не позже now
+func (s *Scheduler) due(now time.Time) []*ScheduledItem {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := now.UnixMilli()
+	n := sort.Search(len(s.items), func(i int) bool { return s.items[i].DueAt > cutoff })
+	return append([]*ScheduledItem(nil), s.items[:n]...)
+}
+
+// remove удаляет обработанное уведомление из очереди
+func (s *Scheduler) remove(item *ScheduledItem) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.items {
+		if existing == item {
+			s.items = append(s.items[:i], s.items[i+1:]...)
+			break
+		}
+	}
+
+	if err := s.file.Save(s.items); err != nil {
+		s.logger.Error("Failed to persist scheduler state", zap.Error(err))
+	}
+}
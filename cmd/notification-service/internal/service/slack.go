@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// slackRecipientErrors — ошибки Slack API, вызванные получателем, а не доступностью канала
+var slackRecipientErrors = map[string]bool{
+	"channel_not_found": true,
+	"user_not_found":    true,
+	"user_disabled":     true,
+	"is_archived":       true,
+	"cannot_dm_bot":     true,
+}
+
+// SlackService отправляет личные сообщения в Slack через Web API
+type SlackService struct {
+	token  string
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewSlackService создает новый экземпляр SlackService
+func NewSlackService(token string) (*SlackService, error) {
+	if token == "" {
+		return nil, fmt.Errorf("SLACK_BOT_TOKEN is not set")
+	}
+
+	return &SlackService{
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger.GetLogger(),
+	}, nil
+}
+
+// Channel возвращает название канала
+func (s *SlackService) Channel() string {
+	return shared.ChannelSlack
+}
+
+// Notify отправляет сообщение пользователю Slack
+func (s *SlackService) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	body, err := json.Marshal(map[string]string{
+		"channel": contacts.SlackUserID,
+		"text":    text,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal slack body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackPostMessageURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build slack request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+s.token)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		s.logger.Error("Error sending message to Slack", zap.Error(err), logger.Contact("slackUserId", contacts.SlackUserID))
+		return fmt.Errorf("failed to send slack message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode slack response: %w", err)
+	}
+	if !result.OK {
+		err := fmt.Errorf("slack API error: %s", result.Error)
+		if slackRecipientErrors[result.Error] {
+			return &RecipientError{Err: err}
+		}
+		return err
+	}
+
+	s.logger.Info("Message sent to Slack", logger.Contact("slackUserId", contacts.SlackUserID))
+	return nil
+}
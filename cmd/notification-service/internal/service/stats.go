@@ -0,0 +1,50 @@
+package service
+
+import (
+	"sync"
+
+	"kafka-notification-system/pkg/metrics"
+)
+
+// Дополнительные статусы обработки уведомлений, не связанные с правилами доставки
+const (
+	StatusExpired   = "expired"
+	StatusDigested  = "digested"
+	StatusRetried   = "retried"
+	StatusFailed    = "failed"
+	StatusEscalated = "escalated"
+	StatusDuplicate = "duplicate"
+)
+
+// DeliveryStats считает итоги обработки уведомлений по статусам. Если заданы
+// метрики, каждый итог учитывается и в notifications_total
+type DeliveryStats struct {
+	mu      sync.Mutex
+	counts  map[string]int64
+	metrics *metrics.Metrics
+}
+
+// NewDeliveryStats создает новый экземпляр DeliveryStats
+func NewDeliveryStats() *DeliveryStats {
+	return &DeliveryStats{counts: make(map[string]int64)}
+}
+
+// Inc увеличивает счетчик статуса на единицу
+func (s *DeliveryStats) Inc(status string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[status]++
+	s.metrics.NotificationStatus(status)
+}
+
+// Snapshot возвращает копию счетчиков
+func (s *DeliveryStats) Snapshot() map[string]int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := make(map[string]int64, len(s.counts))
+	for status, count := range s.counts {
+		snapshot[status] = count
+	}
+	return snapshot
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"sync"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// SuppressedItem представляет уведомление, не отправленное из-за настроек получателя
+type SuppressedItem struct {
+	MessageID    string `json:"messageId"`
+	UserID       string `json:"userId,omitempty"`
+	Category     string `json:"category,omitempty"`
+	Reason       string `json:"reason"`
+	SuppressedAt int64  `json:"suppressedAt"`
+}
+
+// SuppressionLog хранит в памяти последние подавленные уведомления
+type SuppressionLog struct {
+	mu       sync.Mutex
+	items    []SuppressedItem
+	capacity int
+}
+
+// NewSuppressionLog создает новый экземпляр SuppressionLog на capacity записей
+func NewSuppressionLog(capacity int) *SuppressionLog {
+	return &SuppressionLog{capacity: capacity}
+}
+
+// Add добавляет запись, вытесняя самую старую при переполнении
+func (l *SuppressionLog) Add(item SuppressedItem) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if item.SuppressedAt == 0 {
+		item.SuppressedAt = shared.GetCurrentTimestamp()
+	}
+	l.items = append(l.items, item)
+	if len(l.items) > l.capacity {
+		l.items = append([]SuppressedItem(nil), l.items[len(l.items)-l.capacity:]...)
+	}
+}
+
+// List возвращает записи, начиная с самых новых
+func (l *SuppressionLog) List() []SuppressedItem {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	result := make([]SuppressedItem, 0, len(l.items))
+	for i := len(l.items) - 1; i >= 0; i-- {
+		result = append(result, l.items[i])
+	}
+	return result
+}
@@ -0,0 +1,22 @@
+package service
+
+import "testing"
+
+func TestSuppressionLog_KeepsNewestItems(t *testing.T) {
+	log := NewSuppressionLog(2)
+
+	log.Add(SuppressedItem{MessageID: "1"})
+	log.Add(SuppressedItem{MessageID: "2"})
+	log.Add(SuppressedItem{MessageID: "3"})
+
+	items := log.List()
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+	if items[0].MessageID != "3" || items[1].MessageID != "2" {
+		t.Errorf("Expected newest items first, got %+v", items)
+	}
+	if items[0].SuppressedAt == 0 {
+		t.Error("Expected suppression time to be set")
+	}
+}
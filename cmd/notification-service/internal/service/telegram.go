@@ -1,8 +1,12 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net/http"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 	"go.uber.org/zap"
@@ -34,6 +38,25 @@ func NewTelegramService(botToken string) (*TelegramService, error) {
 	}, nil
 }
 
+// Channel возвращает название канала
+func (s *TelegramService) Channel() string {
+	return shared.ChannelTelegram
+}
+
+// Notify отправляет уведомление в Telegram чат получателя
+func (s *TelegramService) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	return s.SendMessage(contacts.TelegramChatID, text)
+}
+
+// Ping проверяет доступность Telegram Bot API запросом getMe
+func (s *TelegramService) Ping(ctx context.Context) (string, error) {
+	bot, err := s.bot.GetMe()
+	if err != nil {
+		return "", fmt.Errorf("telegram getMe failed: %w", err)
+	}
+	return "bot @" + bot.UserName, nil
+}
+
 // SendMessage отправляет сообщение в Telegram чат
 func (s *TelegramService) SendMessage(chatID int64, text string) error {
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -42,14 +65,20 @@ func (s *TelegramService) SendMessage(chatID int64, text string) error {
 	if err != nil {
 		s.logger.Error("Error sending message to Telegram",
 			zap.Error(err),
-			zap.Int64("chatId", chatID),
-			zap.String("text", text))
-		return fmt.Errorf("failed to send telegram message: %w", err)
+			logger.ChatID("chatId", chatID),
+			logger.Text("text", text))
+		err = fmt.Errorf("failed to send telegram message: %w", err)
+		// Bot API отвечает 400 на неизвестный чат и 403, если бот заблокирован
+		var apiErr *tgbotapi.Error
+		if errors.As(err, &apiErr) && (apiErr.Code == http.StatusBadRequest || apiErr.Code == http.StatusForbidden) {
+			return &RecipientError{Err: err}
+		}
+		return err
 	}
 
 	s.logger.Info("Message sent to Telegram",
-		zap.Int64("chatId", chatID),
-		zap.String("text", text))
+		logger.ChatID("chatId", chatID),
+		logger.Text("text", text))
 
 	return nil
 }
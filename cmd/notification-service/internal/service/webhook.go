@@ -0,0 +1,77 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+// WebhookService отправляет уведомления POST запросом на webhook получателя
+type WebhookService struct {
+	client *http.Client
+	logger *zap.Logger
+}
+
+// NewWebhookService создает новый экземпляр WebhookService
+func NewWebhookService(timeout time.Duration) *WebhookService {
+	return &WebhookService{
+		client: &http.Client{Timeout: timeout},
+		logger: logger.GetLogger(),
+	}
+}
+
+// Channel возвращает название канала
+func (s *WebhookService) Channel() string {
+	return shared.ChannelWebhook
+}
+
+// Notify отправляет уведомление на webhook получателя
+func (s *WebhookService) Notify(ctx context.Context, contacts *shared.ContactPoints, text string) error {
+	body, err := json.Marshal(map[string]string{"text": text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook body: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, contacts.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		// url.Error содержит адрес webhook: скрываем его так же, как поле контакта,
+		// сохраняя тип ошибки для классификации в dead letter очереди
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = logger.RedactContact(urlErr.URL)
+		}
+		s.logger.Error("Error sending webhook", zap.Error(err), logger.Contact("url", contacts.WebhookURL))
+		return fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		err := fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		// 4xx, кроме таймаута и ограничения частоты, относится к webhook получателя,
+		// а не к доступности канала
+		if resp.StatusCode < http.StatusInternalServerError &&
+			resp.StatusCode != http.StatusRequestTimeout && resp.StatusCode != http.StatusTooManyRequests {
+			return &RecipientError{Err: err}
+		}
+		return err
+	}
+
+	s.logger.Info("Message sent to webhook", logger.Contact("url", contacts.WebhookURL))
+	return nil
+}
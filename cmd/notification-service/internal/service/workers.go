@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// workerQueueSize задает число сообщений, ожидающих одного обработчика
+const workerQueueSize = 16
+
+// job представляет сообщение, переданное обработчику, и reader его топика.
+// generation — поколение состояния партиции в offsetTracker на момент чтения
+type job struct {
+	reader     MessageReader
+	message    kafka.Message
+	generation int
+}
+
+// workerPool обрабатывает сообщения параллельно. Сообщения с одним ключом
+// всегда попадают к одному обработчику и обрабатываются в порядке поступления
+type workerPool struct {
+	queues []chan job
+	handle func(ctx context.Context, j job)
+	wg     sync.WaitGroup
+}
+
+// newWorkerPool создает пул из size обработчиков
+func newWorkerPool(size int, handle func(ctx context.Context, j job)) *workerPool {
+	if size < 1 {
+		size = 1
+	}
+	queues := make([]chan job, size)
+	for i := range queues {
+		queues[i] = make(chan job, workerQueueSize)
+	}
+	return &workerPool{queues: queues, handle: handle}
+}
+
+// start запускает обработчики. Обработчик завершается при отмене контекста
+// или после закрытия очередей, обработав оставшиеся в ней сообщения
+func (p *workerPool) start(ctx context.Context) {
+	for _, queue := range p.queues {
+		p.wg.Add(1)
+		go func(queue chan job) {
+			defer p.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case j, ok := <-queue:
+					if !ok {
+						return
+					}
+					p.handle(ctx, j)
+				}
+			}
+		}(queue)
+	}
+}
+
+// submit передает сообщение обработчику ключа key. Если очередь обработчика
+// заполнена, submit ждет, сохраняя порядок выбора очередей приоритетов
+func (p *workerPool) submit(ctx context.Context, key string, j job) error {
+	h := fnv.New32a()
+	h.Write([]byte(key))
+	queue := p.queues[h.Sum32()%uint32(len(p.queues))]
+
+	select {
+	case queue <- j:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// stop закрывает очереди и ждет завершения обработчиков
+func (p *workerPool) stop() {
+	for _, queue := range p.queues {
+		close(queue)
+	}
+	p.wg.Wait()
+}
+
+// recipientKey возвращает ключ получателя уведомления, определяющий обработчика
+// и партицию: user:<userId> или chat:<chatId> из payload, как у Producer Service.
+// Для остальных сообщений используется ключ Kafka
+func recipientKey(message kafka.Message) string {
+	envelope, err := shared.FromJSON(message.Value)
+	if err == nil && envelope.IsNotificationMessage() {
+		if notification, err := envelope.GetNotificationPayload(); err == nil &&
+			(notification.UserID != "" || notification.ChatID != 0) {
+			return notification.Recipient()
+		}
+	}
+	return string(message.Key)
+}
+
+// topicPartition идентифицирует партицию топика
+type topicPartition struct {
+	topic     string
+	partition int
+}
+
+// partitionOffsets хранит offset прочитанных сообщений партиции в порядке
+// чтения и отметки об их обработке. generation увеличивается при сбросе состояния
+type partitionOffsets struct {
+	mu         sync.Mutex
+	pending    []int64
+	done       map[int64]bool
+	generation int
+}
+
+// offsetTracker фиксирует offset партиции только до последнего сообщения,
+// перед которым все прочитанные сообщения партиции уже обработаны.
+// Сообщения, обработка которых не завершилась, будут прочитаны повторно
+type offsetTracker struct {
+	mu         sync.Mutex
+	partitions map[topicPartition]*partitionOffsets
+}
+
+// newOffsetTracker создает новый экземпляр offsetTracker
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{partitions: make(map[topicPartition]*partitionOffsets)}
+}
+
+// track регистрирует прочитанное сообщение и возвращает поколение состояния
+// партиции. Вызывается в порядке чтения партиции.
+//
+// Offset не больше уже прочитанного означает, что партицию переназначили после
+// rebalance и чтение продолжилось с зафиксированного offset. Тогда состояние
+// партиции сбрасывается: незавершенные сообщения прочитаны заново, а завершение
+// обработки сообщений прошлого поколения больше не фиксирует offset
+func (t *offsetTracker) track(message kafka.Message) int {
+	p := t.partition(message)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if n := len(p.pending); n > 0 && message.Offset <= p.pending[n-1] {
+		p.pending = nil
+		p.done = make(map[int64]bool)
+		p.generation++
+	}
+	p.pending = append(p.pending, message.Offset)
+	return p.generation
+}
+
+// complete отмечает сообщение поколения generation обработанным и фиксирует
+// offset через reader, если непрерывный префикс обработанных сообщений вырос.
+// Фиксация выполняется под блокировкой партиции, поэтому offset партиции не
+// уменьшается. Сообщения прошлых поколений пропускаются
+func (t *offsetTracker) complete(ctx context.Context, reader MessageReader, message kafka.Message, generation int) error {
+	p := t.partition(message)
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if generation != p.generation {
+		return nil
+	}
+
+	p.done[message.Offset] = true
+	committed := int64(-1)
+	for len(p.pending) > 0 && p.done[p.pending[0]] {
+		committed = p.pending[0]
+		delete(p.done, committed)
+		p.pending = p.pending[1:]
+	}
+	if committed < 0 {
+		return nil
+	}
+
+	return reader.CommitMessages(ctx, kafka.Message{
+		Topic:     message.Topic,
+		Partition: message.Partition,
+		Offset:    committed,
+	})
+}
+
+// partition возвращает состояние партиции сообщения, создавая его при необходимости
+func (t *offsetTracker) partition(message kafka.Message) *partitionOffsets {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := topicPartition{topic: message.Topic, partition: message.Partition}
+	p, ok := t.partitions[key]
+	if !ok {
+		p = &partitionOffsets{done: make(map[int64]bool)}
+		t.partitions[key] = p
+	}
+	return p
+}
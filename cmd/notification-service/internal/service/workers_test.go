@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestOffsetTracker_CommitsContiguousPrefix(t *testing.T) {
+	tracker := newOffsetTracker()
+	reader := &fakeReader{}
+	ctx := context.Background()
+
+	messages := make([]kafka.Message, 5)
+	for i := range messages {
+		messages[i] = kafka.Message{Topic: "notifications", Partition: 0, Offset: int64(10 + i)}
+		tracker.track(messages[i])
+	}
+	// Другая партиция не влияет на фиксацию первой
+	other := kafka.Message{Topic: "notifications", Partition: 1, Offset: 3}
+	tracker.track(other)
+
+	for _, i := range []int{2, 1} {
+		tracker.complete(ctx, reader, messages[i], 0)
+	}
+	if offsets := reader.committedOffsets(); len(offsets) != 0 {
+		t.Fatalf("Expected no commit before the first message completes, got %v", offsets)
+	}
+
+	tracker.complete(ctx, reader, messages[0], 0)
+	tracker.complete(ctx, reader, messages[4], 0)
+	tracker.complete(ctx, reader, other, 0)
+	tracker.complete(ctx, reader, messages[3], 0)
+
+	if offsets := reader.committedOffsets(); !reflect.DeepEqual(offsets, []int64{12, 3, 14}) {
+		t.Errorf("Expected commits [12 3 14], got %v", offsets)
+	}
+}
+
+func TestOffsetTracker_ResetsOnReassignment(t *testing.T) {
+	tracker := newOffsetTracker()
+	reader := &fakeReader{}
+	ctx := context.Background()
+
+	message := func(offset int64) kafka.Message {
+		return kafka.Message{Topic: "notifications", Partition: 0, Offset: offset}
+	}
+	for offset := int64(10); offset < 13; offset++ {
+		tracker.track(message(offset))
+	}
+	tracker.complete(ctx, reader, message(10), 0)
+
+	// После rebalance партиция снова читается с зафиксированного offset
+	generation := tracker.track(message(11))
+	if generation != 1 {
+		t.Fatalf("Expected new generation after reassignment, got %d", generation)
+	}
+	tracker.track(message(12))
+
+	// Завершение сообщения прошлого поколения не фиксирует offset
+	tracker.complete(ctx, reader, message(12), 0)
+	tracker.complete(ctx, reader, message(11), 0)
+	if offsets := reader.committedOffsets(); !reflect.DeepEqual(offsets, []int64{10}) {
+		t.Fatalf("Expected stale completions to be ignored, got %v", offsets)
+	}
+
+	tracker.complete(ctx, reader, message(12), generation)
+	tracker.complete(ctx, reader, message(11), generation)
+	if offsets := reader.committedOffsets(); !reflect.DeepEqual(offsets, []int64{10, 12}) {
+		t.Errorf("Expected commits [10 12], got %v", offsets)
+	}
+}
+
+// keysOnDifferentWorkers возвращает два ключа, попадающих к разным обработчикам пула
+func keysOnDifferentWorkers(size int) (string, string) {
+	worker := func(key string) uint32 {
+		h := fnv.New32a()
+		h.Write([]byte(key))
+		return h.Sum32() % uint32(size)
+	}
+	first := "key-0"
+	for i := 1; ; i++ {
+		if key := fmt.Sprintf("key-%d", i); worker(key) != worker(first) {
+			return first, key
+		}
+	}
+}
+
+func TestWorkerPool_ProcessesKeysConcurrently(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	started := make(chan string, 2)
+	release := make(chan struct{})
+	pool := newWorkerPool(4, func(ctx context.Context, j job) {
+		started <- string(j.message.Key)
+		<-release
+	})
+	pool.start(ctx)
+
+	first, second := keysOnDifferentWorkers(4)
+	pool.submit(ctx, first, job{message: kafka.Message{Key: []byte(first)}})
+	pool.submit(ctx, second, job{message: kafka.Message{Key: []byte(second)}})
+
+	// Оба сообщения обрабатываются одновременно, хотя первое еще не завершено
+	for i := 0; i < 2; i++ {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("Expected messages with different keys to be processed concurrently")
+		}
+	}
+	close(release)
+	pool.stop()
+}
+
+func TestWorkerPool_KeepsOrderPerKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var mu sync.Mutex
+	seen := make(map[string][]int64)
+	pool := newWorkerPool(3, func(ctx context.Context, j job) {
+		time.Sleep(time.Duration(j.message.Offset%3) * time.Millisecond)
+		mu.Lock()
+		defer mu.Unlock()
+		key := string(j.message.Key)
+		seen[key] = append(seen[key], j.message.Offset)
+	})
+	pool.start(ctx)
+
+	for i := 0; i < 60; i++ {
+		key := fmt.Sprintf("chat:%d", i%5)
+		pool.submit(ctx, key, job{message: kafka.Message{Key: []byte(key), Offset: int64(i)}})
+	}
+	pool.stop()
+
+	for key, offsets := range seen {
+		if len(offsets) != 12 {
+			t.Errorf("Expected 12 messages for %s, got %d", key, len(offsets))
+		}
+		for i := 1; i < len(offsets); i++ {
+			if offsets[i] < offsets[i-1] {
+				t.Errorf("Messages of %s processed out of order: %v", key, offsets)
+				break
+			}
+		}
+	}
+}
+
+func TestRecipientKey(t *testing.T) {
+	value := func(payload shared.NotificationMessage) []byte {
+		data, _ := shared.NewKafkaMessage(shared.MessageTypeNotification, payload).ToJSON()
+		return data
+	}
+
+	tests := []struct {
+		name     string
+		message  kafka.Message
+		expected string
+	}{
+		{"user", kafka.Message{Value: value(shared.NotificationMessage{UserID: "user-42", Text: "hi"})}, "user:user-42"},
+		{"chat", kafka.Message{Value: value(shared.NotificationMessage{ChatID: 7, Text: "hi"})}, "chat:7"},
+		{"invalid", kafka.Message{Key: []byte("msg-1"), Value: []byte("not json")}, "msg-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := recipientKey(tt.message); key != tt.expected {
+				t.Errorf("Expected key %s, got %s", tt.expected, key)
+			}
+		})
+	}
+}
+
+// newConsumingTestService создает сервис с одной очередью, читающей уведомления в chat 1
+func newConsumingTestService(texts ...string) (*KafkaService, *fakeReader, *mockNotifier) {
+	reader := &fakeReader{messages: make(chan kafka.Message, len(texts)+10)}
+	for i, text := range texts {
+		reader.messages <- notificationMessage(int64(i), text)
+	}
+
+	telegram := &mockNotifier{channel: shared.ChannelTelegram}
+	service := newTestKafkaService(telegram)
+	service.lanes = []*lane{{priority: shared.PriorityNormal, topic: "notifications", reader: reader,
+		messages: make(chan kafka.Message, 1)}}
+	service.lanesScheduler = newLaneScheduler(config.PriorityModeStrict, []int{1})
+	service.wake = make(chan struct{}, 1)
+	service.workers = 2
+	service.offsets = newOffsetTracker()
+	service.config = &config.KafkaConfig{}
+	return service, reader, telegram
+}
+
+func notificationMessage(offset int64, text string) kafka.Message {
+	value, _ := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 1, Text: text}).ToJSON()
+	return kafka.Message{Topic: "notifications", Offset: offset, Value: value}
+}
+
+// waitForCommit ждет фиксации offset
+func waitForCommit(t *testing.T, reader *fakeReader, offset int64) {
+	deadline := time.After(2 * time.Second)
+	for {
+		offsets := reader.committedOffsets()
+		if len(offsets) > 0 && offsets[len(offsets)-1] == offset {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("Expected offset %d to be committed, got %v", offset, offsets)
+		case <-time.After(5 * time.Millisecond):
+		}
+	}
+}
+
+func TestKafkaService_StartConsuming_CommitsAfterProcessing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, reader, telegram := newConsumingTestService("one", "two", "three")
+
+	done := make(chan error, 1)
+	go func() { done <- service.StartConsuming(ctx) }()
+
+	waitForCommit(t, reader, 2)
+	cancel()
+	<-done
+
+	if !reflect.DeepEqual(telegram.sent, []string{"one", "two", "three"}) {
+		t.Errorf("Expected messages of one chat in order, got %v", telegram.sent)
+	}
+}
+
+func TestKafkaService_PauseAndDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	service, reader, telegram := newConsumingTestService("one")
+
+	done := make(chan error, 1)
+	go func() { done <- service.StartConsuming(ctx) }()
+	waitForCommit(t, reader, 0)
+
+	// Во время паузы новые сообщения не обрабатываются
+	service.Control().Pause("ops", "telegram incident")
+	reader.messages <- notificationMessage(1, "two")
+	time.Sleep(50 * time.Millisecond)
+	if len(telegram.sent) != 1 {
+		t.Fatalf("Expected no delivery while paused, got %v", telegram.sent)
+	}
+
+	service.Control().Resume()
+	waitForCommit(t, reader, 1)
+
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer drainCancel()
+	if err := service.Drain(drainCtx); err != nil {
+		t.Fatalf("Unexpected drain error: %v", err)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Expected StartConsuming to return nil after drain, got %v", err)
+	}
+	if status := service.Control().Status(); status.InFlight != 0 {
+		t.Errorf("Expected no messages in flight after drain, got %d", status.InFlight)
+	}
+}
@@ -3,17 +3,23 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sort"
 	"syscall"
 	"time"
+	_ "time/tzdata" // часовые пояса для окон тишины в образах без tzdata
 
 	"kafka-notification-system/cmd/notification-service/internal/handler"
 	"kafka-notification-system/cmd/notification-service/internal/service"
 	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/recipient"
+	"kafka-notification-system/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
@@ -30,64 +36,213 @@ func main() {
 	if appConfig.Port == "3000" {
 		appConfig.Port = "3002" // Устанавливаем порт по умолчанию для notification
 	}
+	accessLogConfig := config.LoadAccessLogConfig()
 	kafkaConfig := config.LoadKafkaConfig("notification-service", "telegram-notification-group")
+	channelsConfig := config.LoadChannelsConfig()
+	deliveryConfig := config.LoadDeliveryConfig()
+	breakerConfig := config.LoadBreakerConfig()
+	adminConfig := config.LoadAdminConfig()
+	tracingConfig := config.LoadTracingConfig()
+	healthConfig := config.LoadHealthConfig()
+	if adminConfig.Port == "" {
+		adminConfig.Port = "3102" // Порт административного API notification по умолчанию
+	}
 
 	// Инициализируем логгер
 	logger.InitLogger(appConfig.Environment)
 	log := logger.GetLogger()
 
+	// Метрики Prometheus помечаются именем сервиса из конфигурации Kafka
+	serviceMetrics := metrics.New(kafkaConfig.ClientID)
+
+	// Трассировка OpenTelemetry, спаны отправляются при остановке сервиса
+	shutdownTracing, err := tracing.Init(kafkaConfig.ClientID, tracingConfig)
+	if err != nil {
+		log.Fatal("Failed to init tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// Создаем Telegram сервис
 	telegramService, err := service.NewTelegramService(appConfig.TelegramBotToken)
 	if err != nil {
 		log.Fatal("Failed to create Telegram service", zap.Error(err))
 	}
 
+	// Подключаем дополнительные каналы доставки, если они настроены
+	notifiers := []service.Notifier{
+		telegramService,
+		service.NewWebhookService(channelsConfig.WebhookTimeout),
+	}
+	if emailService, err := service.NewEmailService(channelsConfig); err == nil {
+		notifiers = append(notifiers, emailService)
+	} else {
+		log.Info("Email channel disabled", zap.String("reason", err.Error()))
+	}
+	if slackService, err := service.NewSlackService(channelsConfig.SlackBotToken); err == nil {
+		notifiers = append(notifiers, slackService)
+	} else {
+		log.Info("Slack channel disabled", zap.String("reason", err.Error()))
+	}
+
+	// Проверки готовности включают доступность каналов, которые умеют ее проверять
+	checker := health.NewChecker(healthConfig.CheckTimeout)
+	for _, notifier := range notifiers {
+		if pinger, ok := notifier.(service.Pinger); ok {
+			checker.Add(notifier.Channel(), pinger.Ping)
+		}
+	}
+
+	// Каждый канал защищен circuit breaker: при недоступности канала
+	// уведомления откладываются, а не расходуют повторные попытки.
+	// Метрики учитывают только фактические отправки
+	for i, notifier := range notifiers {
+		notifiers[i] = service.NewBreakerNotifier(service.NewInstrumentedNotifier(notifier, serviceMetrics), breakerConfig, serviceMetrics)
+	}
+
+	dispatcher := service.NewDispatcher(recipient.NewClient(appConfig.RecipientServiceURL),
+		deliveryConfig.CriticalCategories, notifiers...)
+
+	// Создаем планировщик отложенных уведомлений (окна тишины)
+	scheduler, err := service.NewScheduler(filepath.Join(appConfig.DataDir, "scheduled.json"),
+		deliveryConfig.SchedulerInterval)
+	if err != nil {
+		log.Fatal("Failed to load scheduler state", zap.Error(err))
+	}
+
+	// Создаем буфер сводок для уведомлений с флагом digest
+	digest, err := service.NewDigestBuffer(filepath.Join(appConfig.DataDir, "digest.json"),
+		deliveryConfig.DigestWindow, deliveryConfig.DigestMaxCount)
+	if err != nil {
+		log.Fatal("Failed to load digest buffer", zap.Error(err))
+	}
+
+	// Создаем хранилище политик эскалации и исполнитель эскалаций критических уведомлений
+	policies, err := service.NewPolicyStore(filepath.Join(appConfig.DataDir, "escalation-policies.json"))
+	if err != nil {
+		log.Fatal("Failed to load escalation policies", zap.Error(err))
+	}
+	escalator, err := service.NewEscalator(filepath.Join(appConfig.DataDir, "escalations.json"),
+		policies, dispatcher, deliveryConfig.SchedulerInterval)
+	if err != nil {
+		log.Fatal("Failed to load escalations", zap.Error(err))
+	}
+
+	// Журнал обработанных уведомлений для пропуска повторов после перезапуска Fan-out Service
+	dedupe, err := service.NewDeduplicator(filepath.Join(appConfig.DataDir, "dedupe.jsonl"),
+		deliveryConfig.DedupeWindow, deliveryConfig.DedupeMaxEntries)
+	if err != nil {
+		log.Fatal("Failed to load processed notification ids", zap.Error(err))
+	}
+
 	// Создаем Kafka сервис
-	kafkaService := service.NewKafkaService(kafkaConfig, telegramService)
+	kafkaService := service.NewKafkaService(kafkaConfig, deliveryConfig, dispatcher, scheduler, digest, escalator,
+		dedupe, serviceMetrics)
 	defer func() {
 		if err := kafkaService.Close(); err != nil {
 			log.Error("Failed to close Kafka service", zap.Error(err))
 		}
 	}()
 
+	// Kafka проверяется по всем топикам, которые читает и пишет сервис
+	topics := append(kafkaConfig.NotificationTopics(), kafkaConfig.DeadLetterTopic)
+	for _, tier := range kafkaConfig.RetryTopics() {
+		topics = append(topics, tier.Topic)
+	}
+	checker.Add("kafka", health.KafkaCheck(kafkaConfig.Brokers, topics))
+	checker.Add("consumer-group", health.GroupCheck(kafkaConfig.Brokers, kafkaConfig.GroupID))
+	polls := kafkaService.Polls()
+	pollTopics := make([]string, 0, len(polls))
+	for topic := range polls {
+		pollTopics = append(pollTopics, topic)
+	}
+	sort.Strings(pollTopics)
+	for _, topic := range pollTopics {
+		checker.Add("poll "+topic, health.PollCheck(polls[topic], kafkaService.Control(), healthConfig.PollTimeout))
+	}
+
+	// Индекс и повторная публикация сообщений dead letter topic для административного API
+	deadLetters := service.NewDeadLetterIndex(adminConfig.DeadLetterMaxEntries, adminConfig.DeadLetterMaxAge)
+	replayer, err := service.NewReplayer(kafkaConfig, deadLetters,
+		filepath.Join(appConfig.DataDir, "replay-audit.jsonl"), adminConfig.MaxReplays)
+	if err != nil {
+		log.Fatal("Failed to load replay audit log", zap.Error(err))
+	}
+	defer func() {
+		if err := replayer.Close(); err != nil {
+			log.Error("Failed to close replayer", zap.Error(err))
+		}
+	}()
+
 	// Создаем обработчики
-	notificationHandler := handler.NewNotificationHandler(log)
+	notificationHandler := handler.NewNotificationHandler(kafkaService.Stats(), dispatcher, log)
+	deadLetterHandler := handler.NewDeadLetterHandler(deadLetters, replayer, log)
+	queueHandler := handler.NewQueueHandler(scheduler, kafkaService.Suppressed(), log)
+	controlHandler := handler.NewControlHandler(kafkaService.Control(), log)
+	escalationHandler := handler.NewEscalationHandler(policies, escalator, log)
 
 	// Настраиваем Gin
 	if appConfig.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
-
-	// Middleware для логирования
-	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	}))
-
+	// Один журнал запросов и один recovery: gin.Default добавил бы свои
+	router := gin.New()
+	router.Use(logger.RequestID())
+	router.Use(logger.AccessLog(log, accessLogConfig))
 	router.Use(gin.Recovery())
+	router.Use(serviceMetrics.Middleware())
+	router.Use(tracing.Middleware())
 
 	// Настраиваем маршруты
 	v1 := router.Group("/")
 	{
 		v1.GET("/health", notificationHandler.Health)
+		v1.GET("/livez", checker.Livez)
+		v1.GET("/readyz", checker.Readyz)
+		v1.GET("/stats", notificationHandler.Stats)
+		v1.GET("/breakers", notificationHandler.Breakers)
 	}
+	router.GET("/metrics", gin.WrapH(serviceMetrics.Handler()))
 
-	// Создаем HTTP сервер
+	// Административное API слушает отдельный порт
+	adminRouter := gin.New()
+	adminRouter.Use(logger.RequestID(), logger.AccessLog(log, accessLogConfig), gin.Recovery())
+	admin := adminRouter.Group("/")
+	{
+		admin.GET("/dead-letters", deadLetterHandler.ListDeadLetters)
+		admin.GET("/dead-letters/audit", deadLetterHandler.ReplayAudit)
+		admin.GET("/dead-letters/:id", deadLetterHandler.GetDeadLetter)
+		admin.POST("/dead-letters/replay", deadLetterHandler.ReplayDeadLetters)
+		admin.GET("/scheduled", queueHandler.ListScheduled)
+		admin.GET("/suppressed", queueHandler.ListSuppressed)
+		admin.GET("/consumer", controlHandler.Status)
+		admin.POST("/consumer/pause", controlHandler.Pause)
+		admin.POST("/consumer/resume", controlHandler.Resume)
+		admin.GET("/log-level", logger.Levels().GetLevel)
+		admin.PUT("/log-level", logger.Levels().PutLevel)
+		admin.GET("/escalation-policies", escalationHandler.ListPolicies)
+		admin.GET("/escalation-policies/:name", escalationHandler.GetPolicy)
+		admin.PUT("/escalation-policies/:name", escalationHandler.PutPolicy)
+		admin.DELETE("/escalation-policies/:name", escalationHandler.DeletePolicy)
+		admin.GET("/escalations", escalationHandler.ListEscalations)
+		admin.GET("/escalations/:id", escalationHandler.GetEscalation)
+		// Подтверждение останавливает эскалацию, поэтому доступно только на административном порту
+		admin.POST("/notifications/:id/ack", escalationHandler.Ack)
+	}
+
+	// Создаем HTTP серверы
 	srv := &http.Server{
 		Addr:    ":" + appConfig.Port,
 		Handler: router,
 	}
+	adminSrv := &http.Server{
+		Addr:    ":" + adminConfig.Port,
+		Handler: adminRouter,
+	}
 
 	// Создаем контекст для graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -96,7 +251,7 @@ func main() {
 	// Запускаем Kafka consumer в горутине
 	go func() {
 		log.Info("Starting Notification Service Kafka consumer",
-			zap.String("topic", kafkaConfig.NotificationsTopic),
+			zap.Strings("topics", kafkaConfig.NotificationTopics()),
 			zap.String("groupId", kafkaConfig.GroupID))
 
 		if err := kafkaService.StartConsuming(ctx); err != nil && err != context.Canceled {
@@ -104,6 +259,14 @@ func main() {
 		}
 	}()
 
+	// Запускаем планировщик отложенных уведомлений и выпуск сводок
+	go scheduler.Run(ctx, kafkaService.ProcessDeferred)
+	go digest.Run(ctx, kafkaService.ProcessDeferred)
+	go escalator.Run(ctx)
+
+	// Читаем dead letter topic для административного API
+	go service.NewDeadLetterConsumer(kafkaConfig.Brokers, kafkaConfig.DeadLetterTopic, deadLetters).Run(ctx)
+
 	// Запускаем HTTP сервер в горутине
 	go func() {
 		log.Info("Starting Notification Service",
@@ -117,6 +280,15 @@ func main() {
 		}
 	}()
 
+	// Запускаем административный HTTP сервер в горутине
+	go func() {
+		log.Info("Starting Notification Service admin API", zap.String("port", adminConfig.Port))
+
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start admin server", zap.Error(err))
+		}
+	}()
+
 	// Ожидаем сигнал завершения
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -124,13 +296,23 @@ func main() {
 
 	log.Info("Shutting down Notification Service...")
 
-	// Отменяем контекст для остановки Kafka consumer
-	cancel()
-
-	// Graceful shutdown HTTP сервера
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
+	// Прекращаем брать новые сообщения и дожидаемся обработки уже взятых
+	if err := kafkaService.Drain(shutdownCtx); err != nil {
+		log.Error("Kafka consumer drain did not complete", zap.Error(err))
+	}
+
+	// Отменяем контекст для остановки чтения топиков и фоновых задач
+	cancel()
+
+	// Отправляем накопленные сводки перед остановкой
+	digest.FlushAll(shutdownCtx, kafkaService.ProcessDeferred)
+
+	if err := adminSrv.Shutdown(shutdownCtx); err != nil {
+		log.Error("Admin server forced to shutdown", zap.Error(err))
+	}
 	if err := srv.Shutdown(shutdownCtx); err != nil {
 		log.Fatal("Server forced to shutdown", zap.Error(err))
 	}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"kafka-notification-system/pkg/auth"
+)
+
+// apiClient выполняет JSON запросы к HTTP API сервисов
+type apiClient struct {
+	httpClient *http.Client
+	apiKey     string
+}
+
+// newAPIClient создает новый экземпляр apiClient
+func newAPIClient() *apiClient {
+	return &apiClient{httpClient: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// withAPIKey возвращает клиент, передающий API ключ в каждом запросе.
+// Пустой ключ не передается
+func (c *apiClient) withAPIKey(apiKey string) *apiClient {
+	copied := *c
+	copied.apiKey = apiKey
+	return &copied
+}
+
+// do отправляет запрос с телом body и декодирует JSON ответ в v.
+// Ответ со статусом не из диапазона 2xx возвращается как ошибка с текстом из поля error
+func (c *apiClient) do(ctx context.Context, method, url string, body, v interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, c.apiKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s %s: status %d: %s", method, url, resp.StatusCode, apiErr.Error)
+		}
+		return fmt.Errorf("%s %s: status %d: %s", method, url, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	if v == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// get выполняет GET запрос
+func (c *apiClient) get(ctx context.Context, url string, v interface{}) error {
+	return c.do(ctx, http.MethodGet, url, nil, v)
+}
+
+// post выполняет POST запрос с JSON телом
+func (c *apiClient) post(ctx context.Context, url string, body, v interface{}) error {
+	return c.do(ctx, http.MethodPost, url, body, v)
+}
+
+// endpoint соединяет базовый адрес сервиса и путь
+func endpoint(baseURL, path string) string {
+	return strings.TrimRight(baseURL, "/") + path
+}
+
+// printJSON печатает значение в виде JSON с отступами
+func printJSON(w io.Writer, v interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// runHealth опрашивает /health всех сервисов и возвращает ошибку, если хотя бы один недоступен
+func runHealth(ctx context.Context, c *cli, args []string) error {
+	services := []struct {
+		name string
+		url  string
+	}{
+		{"producer", c.config.ProducerURL},
+		{"consumer", c.config.ConsumerURL},
+		{"notification", c.config.NotificationURL},
+		{"recipient", c.config.RecipientServiceURL},
+		{"fanout", c.config.FanoutURL},
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tURL\tSTATUS")
+
+	unhealthy := 0
+	for _, svc := range services {
+		checkCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+		var response shared.HealthResponse
+		err := c.client.get(checkCtx, endpoint(svc.url, "/health"), &response)
+		cancel()
+
+		status := response.Status
+		if err != nil {
+			status = "down: " + err.Error()
+			unhealthy++
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", svc.name, svc.url, status)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if unhealthy > 0 {
+		return fmt.Errorf("%d of %d services unhealthy", unhealthy, len(services))
+	}
+	return nil
+}
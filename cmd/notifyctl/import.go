@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/bulk"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+)
+
+// runImport отправляет сообщения из JSONL файла. По умолчанию строки
+// публикуются по одной через POST /messages, а отчет пишется рядом с файлом;
+// с флагом -upload файл целиком передается в POST /messages/bulk
+func runImport(ctx context.Context, c *cli, args []string) error {
+	bulkConfig := config.LoadBulkConfig()
+
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	producerURL := fs.String("producer", c.config.ProducerURL, "Producer Service URL")
+	apiKey := fs.String("api-key", c.config.ProducerAPIKey, "Producer Service API key")
+	file := fs.String("file", "", "JSONL file with message requests, one per line")
+	reportPath := fs.String("report", "", "Report file, default <file>.report.jsonl")
+	rate := fs.Float64("rate", bulkConfig.Rate, "Messages per second, 0 for no limit")
+	upload := fs.Bool("upload", false, "Upload the file to the Producer Service bulk endpoint")
+	importID := fs.String("import-id", "", "Import ID for -upload, default derived from the file name")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" {
+		return errors.New("-file is required")
+	}
+
+	input, err := os.Open(*file)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	if *upload {
+		if *importID == "" {
+			*importID = importIDFromFile(*file)
+		}
+		return uploadImport(ctx, c, *producerURL, *apiKey, *importID, input)
+	}
+
+	if *reportPath == "" {
+		*reportPath = *file + ".report.jsonl"
+	}
+	messagesURL := endpoint(*producerURL, "/messages")
+	client := c.client.withAPIKey(*apiKey)
+	summary, err := bulk.NewImporter(*reportPath, *rate).Run(ctx, input,
+		func(ctx context.Context, req *shared.CreateMessageRequest) (string, error) {
+			var response shared.CreateMessageResponse
+			if err := client.post(ctx, messagesURL, req, &response); err != nil {
+				return "", err
+			}
+			return response.ID, nil
+		})
+	if summary != nil {
+		if printErr := printJSON(c.stdout, summary); printErr != nil {
+			return printErr
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("%w (rerun to resume, report: %s)", err, *reportPath)
+	}
+	if summary.Invalid+summary.Failed > 0 {
+		return fmt.Errorf("%d lines not sent, see %s", summary.Invalid+summary.Failed, *reportPath)
+	}
+	return nil
+}
+
+// uploadImport передает файл в Producer Service потоком, без чтения в память
+func uploadImport(ctx context.Context, c *cli, producerURL, apiKey, importID string, input io.Reader) error {
+	target := endpoint(producerURL, "/messages/bulk?importId="+url.QueryEscape(importID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, input)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	if apiKey != "" {
+		req.Header.Set(auth.HeaderAPIKey, apiKey)
+	}
+
+	// Импорт идет с ограничением скорости и может длиться долго
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Ответы с ошибкой тоже содержат поле error, а прерванный импорт — итоги
+	var response bulk.ImportResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode response: status %d: %w", resp.StatusCode, err)
+	}
+	if response.Summary != nil {
+		if err := printJSON(c.stdout, response); err != nil {
+			return err
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("status %d: %s", resp.StatusCode, response.Error)
+	}
+	return nil
+}
+
+// importIDFromFile составляет идентификатор импорта из имени файла
+func importIDFromFile(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '_', r == '-':
+			return r
+		}
+		return '-'
+	}, name)
+	if len(id) > 64 {
+		id = id[:64]
+	}
+	return id
+}
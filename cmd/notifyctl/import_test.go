@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+func TestRunImport_ResumesFromReport(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "campaign.jsonl")
+	content := `{"type":"notification","payload":{"chatId":1,"text":"one"}}
+{"type":"notification","payload":{"chatId":2,"text":"two"}}
+`
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write import file: %v", err)
+	}
+
+	sent := 0
+	c, _, _, closeServer := newTestCLI(func(w http.ResponseWriter, r *http.Request) {
+		sent++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(shared.CreateMessageResponse{ID: "msg"})
+	}, "")
+	defer closeServer()
+
+	for i := 0; i < 2; i++ {
+		if err := runImport(context.Background(), c, []string{"-file", file, "-rate", "0"}); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	if sent != 2 {
+		t.Errorf("Expected each line to be sent once, got %d requests", sent)
+	}
+	report, err := os.ReadFile(file + ".report.jsonl")
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+	if strings.Count(string(report), `"status":"sent"`) != 2 {
+		t.Errorf("Unexpected report: %s", report)
+	}
+}
+
+func TestImportIDFromFile(t *testing.T) {
+	if id := importIDFromFile("/tmp/Spring campaign.jsonl"); id != "Spring-campaign" {
+		t.Errorf("Expected Spring-campaign, got %s", id)
+	}
+}
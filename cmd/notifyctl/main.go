@@ -0,0 +1,91 @@
+// notifyctl — утилита оператора Kafka Notification System: отправка уведомлений,
+// просмотр топиков, повторная публикация dead-letter сообщений, проверка сервисов
+// и просмотр отложенных и подавленных уведомлений
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"kafka-notification-system/pkg/config"
+)
+
+// cli содержит конфигурацию и потоки ввода-вывода подкоманд
+type cli struct {
+	config *config.CtlConfig
+	client *apiClient
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// command описывает подкоманду notifyctl
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, c *cli, args []string) error
+}
+
+var commands = []command{
+	{"send", "Send a notification or broadcast from flags or stdin JSONL", runSend},
+	{"import", "Import messages from a JSONL file with throttling and resume", runImport},
+	{"tail", "Print messages from notification and dead-letter topics", runTail},
+	{"replay", "Replay dead-letter messages through the admin API", runReplay},
+	{"health", "Check health of all services", runHealth},
+	{"scheduled", "List notifications deferred by quiet hours", runScheduled},
+	{"suppressed", "List recently suppressed notifications", runSuppressed},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+
+	c := &cli{
+		config: config.LoadCtlConfig(),
+		client: newAPIClient(),
+		stdin:  os.Stdin,
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+
+	// Прерывание останавливает длительные подкоманды, например tail
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	name := os.Args[1]
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		if err := cmd.run(ctx, c, os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "notifyctl %s: %v\n", name, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if name != "help" && name != "-h" && name != "--help" {
+		fmt.Fprintf(os.Stderr, "notifyctl: unknown command %q\n\n", name)
+		usage(os.Stderr)
+		os.Exit(2)
+	}
+	usage(os.Stdout)
+}
+
+// usage печатает список подкоманд
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: notifyctl <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-11s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'notifyctl <command> -h' for command flags.")
+}
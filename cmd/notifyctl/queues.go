@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// scheduledItem соответствует отложенному уведомлению в административном API
+type scheduledItem struct {
+	Message   *shared.KafkaMessage `json:"message"`
+	DueAt     int64                `json:"dueAt"`
+	Reason    string               `json:"reason"`
+	CreatedAt int64                `json:"createdAt"`
+}
+
+// suppressedItem соответствует подавленному уведомлению в административном API
+type suppressedItem struct {
+	MessageID    string `json:"messageId"`
+	UserID       string `json:"userId,omitempty"`
+	Category     string `json:"category,omitempty"`
+	Reason       string `json:"reason"`
+	SuppressedAt int64  `json:"suppressedAt"`
+}
+
+// runScheduled печатает уведомления, отложенные окнами тишины получателей
+func runScheduled(ctx context.Context, c *cli, args []string) error {
+	var items []scheduledItem
+	asJSON, err := fetchQueue(ctx, c, "scheduled", "/scheduled", args, &items)
+	if err != nil || asJSON {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DUE AT\tMESSAGE ID\tRECIPIENT\tREASON")
+	for _, item := range items {
+		messageID, recipient := "", ""
+		if item.Message != nil {
+			messageID = item.Message.ID
+			if notification, err := item.Message.GetNotificationPayload(); err == nil {
+				recipient = notification.UserID
+				if recipient == "" {
+					recipient = strconv.FormatInt(notification.ChatID, 10)
+				}
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", formatMillis(item.DueAt), messageID, recipient, item.Reason)
+	}
+	return w.Flush()
+}
+
+// runSuppressed печатает последние уведомления, подавленные настройками получателей
+func runSuppressed(ctx context.Context, c *cli, args []string) error {
+	var items []suppressedItem
+	asJSON, err := fetchQueue(ctx, c, "suppressed", "/suppressed", args, &items)
+	if err != nil || asJSON {
+		return err
+	}
+
+	w := tabwriter.NewWriter(c.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SUPPRESSED AT\tMESSAGE ID\tUSER\tCATEGORY\tREASON")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+			formatMillis(item.SuppressedAt), item.MessageID, item.UserID, item.Category, item.Reason)
+	}
+	return w.Flush()
+}
+
+// fetchQueue разбирает флаги подкоманды и загружает список из административного API.
+// С флагом -json ответ печатается как есть, и fetchQueue возвращает true
+func fetchQueue(ctx context.Context, c *cli, name, path string, args []string, items interface{}) (bool, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	adminURL := fs.String("admin", c.config.NotificationAdminURL, "Notification Service admin API URL")
+	asJSON := fs.Bool("json", false, "Print the raw JSON response")
+	if err := fs.Parse(args); err != nil {
+		return false, err
+	}
+
+	if err := c.client.get(ctx, endpoint(*adminURL, path), items); err != nil {
+		return false, err
+	}
+	if *asJSON {
+		return true, printJSON(c.stdout, items)
+	}
+	return false, nil
+}
+
+// formatMillis форматирует время в миллисекундах в RFC3339
+func formatMillis(ms int64) string {
+	return time.UnixMilli(ms).Format(time.RFC3339)
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// replayFilter соответствует фильтру административного API dead letter topic
+type replayFilter struct {
+	Type       string `json:"type,omitempty"`
+	ErrorClass string `json:"errorClass,omitempty"`
+	Service    string `json:"service,omitempty"`
+	From       string `json:"from,omitempty"`
+	To         string `json:"to,omitempty"`
+}
+
+// replayRequest соответствует телу запроса POST /dead-letters/replay
+type replayRequest struct {
+	IDs    []string      `json:"ids,omitempty"`
+	Filter *replayFilter `json:"filter,omitempty"`
+	Force  bool          `json:"force,omitempty"`
+	Actor  string        `json:"actor,omitempty"`
+	Reason string        `json:"reason,omitempty"`
+}
+
+// runReplay повторно публикует сообщения dead letter topic по идентификаторам
+// или фильтру. С флагом -dry-run только показывает подходящие сообщения
+func runReplay(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	adminURL := fs.String("admin", c.config.NotificationAdminURL, "Notification Service admin API URL")
+	ids := fs.String("ids", "", "Comma-separated dead-letter message IDs, e.g. 0-42,0-43")
+	var filter replayFilter
+	fs.StringVar(&filter.Type, "type", "", "Filter by message type")
+	fs.StringVar(&filter.ErrorClass, "error-class", "", "Filter by error class, e.g. url.Error")
+	fs.StringVar(&filter.Service, "service", "", "Filter by service that dead-lettered the message")
+	fs.StringVar(&filter.From, "from", "", "Filter by failure time, RFC3339 lower bound")
+	fs.StringVar(&filter.To, "to", "", "Filter by failure time, RFC3339 upper bound")
+	force := fs.Bool("force", false, "Replay messages that were already replayed")
+	actor := fs.String("actor", os.Getenv("USER"), "Operator recorded in the audit log")
+	reason := fs.String("reason", "", "Reason recorded in the audit log")
+	dryRun := fs.Bool("dry-run", false, "List matching messages without replaying")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	for _, value := range []string{filter.From, filter.To} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("invalid time %q: expected RFC3339", value)
+		}
+	}
+
+	req := replayRequest{Force: *force, Actor: *actor, Reason: *reason}
+	if *ids != "" {
+		req.IDs = strings.Split(*ids, ",")
+	} else if filter != (replayFilter{}) {
+		req.Filter = &filter
+	} else {
+		return errors.New("either -ids or a filter flag is required")
+	}
+
+	if *dryRun {
+		var entries []map[string]interface{}
+		if req.IDs != nil {
+			for _, id := range req.IDs {
+				var entry map[string]interface{}
+				if err := c.client.get(ctx, endpoint(*adminURL, "/dead-letters/"+url.PathEscape(id)), &entry); err != nil {
+					return err
+				}
+				entries = append(entries, entry)
+			}
+		} else if err := c.client.get(ctx, endpoint(*adminURL, "/dead-letters?"+filter.query()), &entries); err != nil {
+			return err
+		}
+		return printJSON(c.stdout, entries)
+	}
+
+	var result map[string]interface{}
+	if err := c.client.post(ctx, endpoint(*adminURL, "/dead-letters/replay"), req, &result); err != nil {
+		return err
+	}
+	return printJSON(c.stdout, result)
+}
+
+// query возвращает фильтр в виде параметров запроса списка dead-letter сообщений
+func (f *replayFilter) query() string {
+	values := url.Values{}
+	for key, value := range map[string]string{
+		"type":       f.Type,
+		"errorClass": f.ErrorClass,
+		"service":    f.Service,
+		"from":       f.From,
+		"to":         f.To,
+	} {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	return values.Encode()
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// runSend отправляет сообщения через Producer Service: одно сообщение из флагов
+// или поток запросов CreateMessageRequest из stdin, по одному JSON на строку
+func runSend(ctx context.Context, c *cli, args []string) error {
+	fs := flag.NewFlagSet("send", flag.ContinueOnError)
+	fs.SetOutput(c.stderr)
+	producerURL := fs.String("producer", c.config.ProducerURL, "Producer Service URL")
+	apiKey := fs.String("api-key", c.config.ProducerAPIKey, "Producer Service API key")
+	fromStdin := fs.Bool("stdin", false, "Read requests from stdin, one JSON object per line")
+	var m messageFlags
+	fs.StringVar(&m.messageType, "type", shared.MessageTypeNotification, "Message type: notification or broadcast")
+	fs.Int64Var(&m.chatID, "chat-id", 0, "Telegram chat ID")
+	fs.StringVar(&m.userID, "user-id", "", "Recipient user ID")
+	fs.StringVar(&m.audience, "audience", "", "Broadcast audience")
+	fs.StringVar(&m.category, "category", "", "Notification category")
+	fs.StringVar(&m.text, "text", "", "Message text")
+	fs.BoolVar(&m.digest, "digest", false, "Deliver as part of a digest")
+	fs.StringVar(&m.priority, "priority", "", "Priority: high, normal or low")
+	fs.StringVar(&m.ttl, "ttl", "", "Time to live, e.g. 10m")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	url := endpoint(*producerURL, "/messages")
+	client := c.client.withAPIKey(*apiKey)
+	if !*fromStdin {
+		req := m.request()
+		if err := req.Validate(time.Now()); err != nil {
+			return err
+		}
+		var response shared.CreateMessageResponse
+		if err := client.post(ctx, url, req, &response); err != nil {
+			return err
+		}
+		fmt.Fprintln(c.stdout, response.ID)
+		return nil
+	}
+
+	var failed int
+	err := readRequests(c.stdin, func(line int, req *shared.CreateMessageRequest, err error) error {
+		if err == nil {
+			err = req.Validate(time.Now())
+		}
+		if err == nil {
+			var response shared.CreateMessageResponse
+			if err = client.post(ctx, url, req, &response); err == nil {
+				fmt.Fprintf(c.stdout, "line %d: %s\n", line, response.ID)
+				return nil
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+		failed++
+		fmt.Fprintf(c.stderr, "line %d: %v\n", line, err)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d messages failed", failed)
+	}
+	return nil
+}
+
+// messageFlags содержит флаги сообщения, отправляемого без stdin
+type messageFlags struct {
+	messageType string
+	chatID      int64
+	userID      string
+	audience    string
+	category    string
+	text        string
+	digest      bool
+	priority    string
+	ttl         string
+}
+
+// request собирает запрос на создание сообщения из флагов
+func (m *messageFlags) request() *shared.CreateMessageRequest {
+	req := &shared.CreateMessageRequest{Type: m.messageType, Priority: m.priority, TTL: m.ttl}
+	if m.messageType == shared.MessageTypeBroadcast {
+		req.Payload = shared.BroadcastMessage{Audience: m.audience, Category: m.category, Text: m.text}
+	} else {
+		req.Payload = shared.NotificationMessage{
+			ChatID:   m.chatID,
+			UserID:   m.userID,
+			Category: m.category,
+			Text:     m.text,
+			Digest:   m.digest,
+		}
+	}
+	return req
+}
+
+// readRequests читает запросы JSONL и передает каждый в handle вместе с номером
+// строки. Ошибка разбора строки передается в handle и не прерывает чтение
+func readRequests(r io.Reader, handle func(line int, req *shared.CreateMessageRequest, err error) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(bytes.TrimSpace(data)) == 0 {
+			continue
+		}
+
+		var req shared.CreateMessageRequest
+		err := json.Unmarshal(data, &req)
+		if err == nil && (req.Type == "" || req.Payload == nil) {
+			err = errors.New("type and payload are required")
+		}
+		if err := handle(line, &req, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+)
+
+// newTestCLI создает cli с producer, отвечающим переданным обработчиком
+func newTestCLI(handler http.HandlerFunc, stdin string) (*cli, *bytes.Buffer, *bytes.Buffer, func()) {
+	server := httptest.NewServer(handler)
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	c := &cli{
+		config: &config.CtlConfig{ProducerURL: server.URL, NotificationAdminURL: server.URL},
+		client: newAPIClient(),
+		stdin:  strings.NewReader(stdin),
+		stdout: stdout,
+		stderr: stderr,
+	}
+	return c, stdout, stderr, server.Close
+}
+
+func TestRunSend_FromFlags(t *testing.T) {
+	var received shared.CreateMessageRequest
+	c, stdout, _, closeServer := newTestCLI(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(shared.CreateMessageResponse{ID: "msg-1"})
+	}, "")
+	defer closeServer()
+
+	err := runSend(context.Background(), c, []string{"-user-id", "user-42", "-text", "Hello", "-priority", "high"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if received.Type != shared.MessageTypeNotification || received.Priority != shared.PriorityHigh {
+		t.Errorf("Unexpected request: %+v", received)
+	}
+	if strings.TrimSpace(stdout.String()) != "msg-1" {
+		t.Errorf("Expected message id in output, got %q", stdout.String())
+	}
+}
+
+func TestRunSend_RejectsInvalidFlags(t *testing.T) {
+	c, _, _, closeServer := newTestCLI(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("Invalid message must not be sent")
+	}, "")
+	defer closeServer()
+
+	if err := runSend(context.Background(), c, []string{"-text", "Hello"}); err == nil {
+		t.Error("Expected error for notification without recipient")
+	}
+}
+
+func TestRunSend_FromStdin(t *testing.T) {
+	stdin := strings.Join([]string{
+		`{"type":"notification","payload":{"chatId":1,"text":"first"}}`,
+		``,
+		`not json`,
+		`{"type":"notification","payload":{"text":"no recipient"}}`,
+		`{"type":"broadcast","payload":{"audience":"oncall","text":"second"}}`,
+		`{"type":"notification","payload":{"chatId":2,"text":"rejected"}}`,
+	}, "\n")
+
+	sent := 0
+	c, stdout, stderr, closeServer := newTestCLI(func(w http.ResponseWriter, r *http.Request) {
+		var req shared.CreateMessageRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if payload, _ := req.Payload.(map[string]interface{}); payload["text"] == "rejected" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "rejected by producer"})
+			return
+		}
+		sent++
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(shared.CreateMessageResponse{ID: "msg"})
+	}, stdin)
+	defer closeServer()
+
+	err := runSend(context.Background(), c, []string{"-stdin"})
+	if err == nil || err.Error() != "3 messages failed" {
+		t.Errorf("Expected 3 failed messages, got %v", err)
+	}
+	if sent != 2 {
+		t.Errorf("Expected 2 messages sent, got %d", sent)
+	}
+	if !strings.Contains(stdout.String(), "line 1: msg") || !strings.Contains(stdout.String(), "line 5: msg") {
+		t.Errorf("Unexpected output: %q", stdout.String())
+	}
+	for _, expected := range []string{"line 3:", "line 4: either chatId or userId is required", "line 6:", "rejected by producer"} {
+		if !strings.Contains(stderr.String(), expected) {
+			t.Errorf("Expected %q in errors, got %q", expected, stderr.String())
+		}
+	}
+}
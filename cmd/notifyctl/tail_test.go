@@ -0,0 +1,42 @@
+package main
+
+import (
+	"testing"
+
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func TestNewTailRecord_DecodesEnvelope(t *testing.T) {
+	value, err := shared.NewKafkaMessage(shared.MessageTypeNotification,
+		shared.NotificationMessage{ChatID: 1, Text: "Hello"}).ToJSON()
+	if err != nil {
+		t.Fatalf("Failed to marshal message: %v", err)
+	}
+
+	record := newTailRecord(kafka.Message{
+		Topic:   "dead-letter",
+		Offset:  42,
+		Value:   value,
+		Headers: []kafka.Header{{Key: shared.HeaderErrorClass, Value: []byte("url.Error")}},
+	})
+
+	if record.Message == nil || record.Message.Type != shared.MessageTypeNotification {
+		t.Fatalf("Expected decoded envelope, got %+v", record)
+	}
+	if record.Raw != "" {
+		t.Errorf("Expected no raw value, got %q", record.Raw)
+	}
+	if record.Headers[shared.HeaderErrorClass] != "url.Error" {
+		t.Errorf("Expected error class header, got %v", record.Headers)
+	}
+}
+
+func TestNewTailRecord_KeepsInvalidValueRaw(t *testing.T) {
+	record := newTailRecord(kafka.Message{Topic: "notifications", Value: []byte("not json")})
+
+	if record.Message != nil || record.Raw != "not json" {
+		t.Errorf("Expected raw value, got %+v", record)
+	}
+}
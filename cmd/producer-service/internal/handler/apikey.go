@@ -0,0 +1,161 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// KeyStoreInterface определяет интерфейс хранилища API ключей
+type KeyStoreInterface interface {
+	List() []*auth.Key
+	Get(id string) (*auth.Key, error)
+	Create(req *auth.KeyRequest) (*auth.CreatedKey, error)
+	Update(id string, req *auth.KeyRequest) (*auth.Key, error)
+	Delete(id string) error
+}
+
+// APIKeyHandler обрабатывает запросы административного API к API ключам
+type APIKeyHandler struct {
+	keys   KeyStoreInterface
+	logger *zap.Logger
+}
+
+// NewAPIKeyHandler создает новый экземпляр APIKeyHandler
+func NewAPIKeyHandler(keys KeyStoreInterface, logger *zap.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys:   keys,
+		logger: logger,
+	}
+}
+
+// ListKeys godoc
+// @Summary List API keys
+// @Description Get all API keys with their scopes. Keys themselves are never returned
+// @Tags API keys
+// @Produce json
+// @Success 200 {array} auth.Key
+// @Router /api-keys [get]
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	c.JSON(http.StatusOK, h.keys.List())
+}
+
+// GetKey godoc
+// @Summary Get API key
+// @Description Get an API key name and scopes
+// @Tags API keys
+// @Produce json
+// @Param id path string true "Key ID"
+// @Success 200 {object} auth.Key
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id} [get]
+func (h *APIKeyHandler) GetKey(c *gin.Context) {
+	key, err := h.keys.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, key)
+}
+
+// CreateKey godoc
+// @Summary Create API key
+// @Description Create an API key with scopes. The key is returned only in this response, only its hash is stored
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Param key body auth.KeyRequest true "Key name and scopes"
+// @Success 201 {object} auth.CreatedKey
+// @Failure 400 {object} map[string]string
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req auth.KeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key format"})
+		return
+	}
+	if err := req.Scopes.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.keys.Create(&req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("API key created",
+		zap.String("keyId", created.ID),
+		zap.String("keyName", created.Name))
+	c.JSON(http.StatusCreated, created)
+}
+
+// UpdateKey godoc
+// @Summary Update API key
+// @Description Replace the name and scopes of an API key. The key itself does not change
+// @Tags API keys
+// @Accept json
+// @Produce json
+// @Param id path string true "Key ID"
+// @Param key body auth.KeyRequest true "Key name and scopes"
+// @Success 200 {object} auth.Key
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id} [put]
+func (h *APIKeyHandler) UpdateKey(c *gin.Context) {
+	var req auth.KeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key format"})
+		return
+	}
+	if err := req.Scopes.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.keys.Update(c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("API key updated",
+		zap.String("keyId", key.ID),
+		zap.String("keyName", key.Name))
+	c.JSON(http.StatusOK, key)
+}
+
+// DeleteKey godoc
+// @Summary Revoke API key
+// @Description Delete an API key, requests with it are rejected immediately
+// @Tags API keys
+// @Param id path string true "Key ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) DeleteKey(c *gin.Context) {
+	if err := h.keys.Delete(c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("API key revoked", zap.String("keyId", c.Param("id")))
+	c.Status(http.StatusNoContent)
+}
+
+// respondError преобразует ошибку хранилища ключей в HTTP ответ
+func (h *APIKeyHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, auth.ErrKeyNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "API key not found"})
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("API key store error", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
+	}
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"kafka-notification-system/cmd/producer-service/internal/service"
+	"kafka-notification-system/pkg/bulk"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// BulkServiceInterface определяет интерфейс массового импорта сообщений
+type BulkServiceInterface interface {
+	Import(ctx context.Context, importID string, r io.Reader) (*bulk.Summary, error)
+	Report(ctx context.Context, importID string) (io.ReadCloser, error)
+}
+
+// BulkHandler обрабатывает HTTP запросы массового импорта
+type BulkHandler struct {
+	bulkService BulkServiceInterface
+	logger      *zap.Logger
+}
+
+// NewBulkHandler создает новый экземпляр BulkHandler
+func NewBulkHandler(bulkService BulkServiceInterface, logger *zap.Logger) *BulkHandler {
+	return &BulkHandler{
+		bulkService: bulkService,
+		logger:      logger,
+	}
+}
+
+// ImportMessages godoc
+// @Summary Bulk import messages
+// @Description Stream a JSONL file, one message request per line. Lines are validated and published at a throttled rate. Repeating the upload with the same importId skips lines that were already sent; only the client that started the import can resume it
+// @Tags Producer
+// @Accept plain
+// @Produce json
+// @Param importId query string false "Import ID used to resume the import; generated if empty"
+// @Param file body string true "JSONL message requests"
+// @Success 200 {object} bulk.ImportResponse
+// @Failure 400 {object} bulk.ImportResponse
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} bulk.ImportResponse
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /messages/bulk [post]
+func (h *BulkHandler) ImportMessages(c *gin.Context) {
+	importID := c.Query("importId")
+	if importID == "" {
+		importID = uuid.New().String()
+	}
+
+	summary, err := h.bulkService.Import(c.Request.Context(), importID, c.Request.Body)
+	response := bulk.ImportResponse{
+		ImportID: importID,
+		Summary:  summary,
+		Report:   "/messages/bulk/" + importID + "/report",
+	}
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, response)
+	case errors.Is(err, service.ErrInvalidImportID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrImportRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrImportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case summary != nil:
+		// Импорт прерван: обработанные строки уже в отчете, загрузку можно повторить
+		response.Error = err.Error()
+		c.JSON(http.StatusBadRequest, response)
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Failed to import messages", zap.String("importId", importID), zap.Error(err))
+		response.Error = "Failed to import messages"
+		c.JSON(http.StatusInternalServerError, response)
+	}
+}
+
+// ImportReport godoc
+// @Summary Bulk import report
+// @Description Get the JSONL report of an import: line number, status, message ID or error. The last record of a line is its current state. Only the client that started the import can read it
+// @Tags Producer
+// @Produce plain
+// @Param importId path string true "Import ID"
+// @Success 200 {string} string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
+// @Router /messages/bulk/{importId}/report [get]
+func (h *BulkHandler) ImportReport(c *gin.Context) {
+	report, err := h.bulkService.Report(c.Request.Context(), c.Param("importId"))
+	switch {
+	case errors.Is(err, service.ErrInvalidImportID):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, service.ErrImportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	case err != nil:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Failed to open import report", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to open import report"})
+		return
+	}
+	defer report.Close()
+
+	c.DataFromReader(http.StatusOK, -1, "application/x-ndjson", report, nil)
+}
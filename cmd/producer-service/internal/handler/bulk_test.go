@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"kafka-notification-system/cmd/producer-service/internal/service"
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/bulk"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+func newBulkTestRouter(t *testing.T) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	bulkService := service.NewBulkService(t.TempDir(), 0, &MockKafkaService{})
+	handler := NewBulkHandler(bulkService, zap.NewNop())
+
+	router := gin.New()
+	// Клиент задается заголовком X-Client, как после auth.Middleware
+	router.Use(func(c *gin.Context) {
+		if id := c.GetHeader("X-Client"); id != "" {
+			p := &auth.Principal{Method: auth.MethodAPIKey, ID: id, Grants: []auth.Scopes{{}}}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		}
+		c.Next()
+	})
+	router.POST("/messages/bulk", handler.ImportMessages)
+	router.GET("/messages/bulk/:importId/report", handler.ImportReport)
+	return router
+}
+
+func TestBulkHandler_ImportMessages(t *testing.T) {
+	router := newBulkTestRouter(t)
+	body := `{"type":"notification","payload":{"chatId":1,"text":"Hello"}}
+{"type":"notification","payload":{"text":"no recipient"}}
+`
+
+	req, _ := http.NewRequest("POST", "/messages/bulk?importId=campaign-1", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	var response bulk.ImportResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.ImportID != "campaign-1" || response.Summary.Sent != 1 || response.Summary.Invalid != 1 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	// Отчет содержит идентификатор отправленного сообщения и ошибку некорректной строки
+	req, _ = http.NewRequest("GET", response.Report, nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	report := w.Body.String()
+	if !strings.Contains(report, `"id":"test-id"`) || !strings.Contains(report, "either chatId or userId is required") {
+		t.Errorf("Unexpected report: %s", report)
+	}
+}
+
+func TestBulkHandler_ImportMessages_InvalidID(t *testing.T) {
+	router := newBulkTestRouter(t)
+
+	req, _ := http.NewRequest("POST", "/messages/bulk?importId=../etc", strings.NewReader(""))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestBulkHandler_ImportReport_NotFound(t *testing.T) {
+	router := newBulkTestRouter(t)
+
+	req, _ := http.NewRequest("GET", "/messages/bulk/unknown/report", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestBulkHandler_ImportBoundToClient(t *testing.T) {
+	router := newBulkTestRouter(t)
+	send := func(method, path, client, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("X-Client", client)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	body := `{"type":"notification","payload":{"chatId":1,"text":"Hello"}}
+`
+
+	if w := send("POST", "/messages/bulk?importId=campaign-1", "alice", body); w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d: %s", http.StatusOK, w.Code, w.Body.String())
+	}
+
+	// Другой клиент не видит отчет и не может продолжить импорт
+	if w := send("GET", "/messages/bulk/campaign-1/report", "bob", ""); w.Code != http.StatusNotFound {
+		t.Errorf("Expected report of another client to be not found, got %d", w.Code)
+	}
+	if w := send("POST", "/messages/bulk?importId=campaign-1", "bob", body); w.Code != http.StatusNotFound {
+		t.Errorf("Expected import of another client to be not found, got %d", w.Code)
+	}
+
+	if w := send("GET", "/messages/bulk/campaign-1/report", "alice", ""); w.Code != http.StatusOK {
+		t.Errorf("Expected owner to read the report, got %d", w.Code)
+	}
+}
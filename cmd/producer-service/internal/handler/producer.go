@@ -2,8 +2,13 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"time"
 
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/ratelimit"
 	"kafka-notification-system/pkg/shared"
 
 	"github.com/gin-gonic/gin"
@@ -39,22 +44,51 @@ func NewProducerHandler(kafkaService KafkaServiceInterface, logger *zap.Logger)
 // @Param message body shared.CreateMessageRequest true "Message to send"
 // @Success 201 {object} shared.CreateMessageResponse
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
+// @Security ApiKeyAuth
+// @Security BearerAuth
 // @Router /messages [post]
 func (h *ProducerHandler) SendMessage(c *gin.Context) {
 	var req shared.CreateMessageRequest
 
 	// Валидируем входящий JSON
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.logger.Error("Invalid request body", zap.Error(err))
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid message format"})
 		return
 	}
 
+	// Проверяем приоритет, срок жизни и payload сообщения
+	validationErr := req.Validate(time.Now())
+	if validationErr != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid message payload", zap.Error(validationErr))
+		c.JSON(http.StatusBadRequest, gin.H{"error": validationErr.Error()})
+		return
+	}
+
+	// Проверяем, что сообщение не выходит за скоупы клиента
+	if err := auth.Authorize(c.Request.Context(), &req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Warn("Message rejected by client scopes", zap.Error(err))
+		if errors.Is(err, auth.ErrForbidden) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Отправляем сообщение в Kafka
 	response, err := h.kafkaService.SendMessage(c.Request.Context(), &req)
+	var limitErr *ratelimit.LimitError
+	if errors.As(err, &limitErr) {
+		ratelimit.Abort(c, limitErr)
+		return
+	}
 	if err != nil {
-		h.logger.Error("Failed to send message", zap.Error(err))
+		logger.WithContext(c.Request.Context(), h.logger).Error("Failed to send message", zap.Error(err))
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
 		return
 	}
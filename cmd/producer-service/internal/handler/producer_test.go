@@ -4,6 +4,10 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"kafka-notification-system/cmd/producer-service/internal/service"
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/ratelimit"
 	"kafka-notification-system/pkg/shared"
 	"net/http"
 	"net/http/httptest"
@@ -154,3 +158,207 @@ func TestProducerHandler_Health(t *testing.T) {
 		t.Errorf("Expected status 'ok', got %s", response.Status)
 	}
 }
+
+func TestProducerHandler_SendMessage_UserIDTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockKafkaService{shouldError: false}
+	logger := zap.NewNop()
+	handler := NewProducerHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/messages", handler.SendMessage)
+
+	requestBody := shared.CreateMessageRequest{
+		Type: "notification",
+		Payload: map[string]interface{}{
+			"userId": "user-42",
+			"text":   "Test message",
+		},
+	}
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+}
+
+func TestProducerHandler_SendMessage_MissingTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockKafkaService{shouldError: false}
+	logger := zap.NewNop()
+	handler := NewProducerHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/messages", handler.SendMessage)
+
+	requestBody := shared.CreateMessageRequest{
+		Type: "notification",
+		Payload: map[string]interface{}{
+			"text": "Test message",
+		},
+	}
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestProducerHandler_SendMessage_InvalidPriority(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockKafkaService{shouldError: false}
+	logger := zap.NewNop()
+	handler := NewProducerHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/messages", handler.SendMessage)
+
+	requestBody := shared.CreateMessageRequest{
+		Type:     "notification",
+		Priority: "urgent",
+		Payload: map[string]interface{}{
+			"chatId": 123456,
+			"text":   "Test message",
+		},
+	}
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestProducerHandler_SendMessage_InvalidTTL(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mockService := &MockKafkaService{shouldError: false}
+	logger := zap.NewNop()
+	handler := NewProducerHandler(mockService, logger)
+
+	router := gin.New()
+	router.POST("/messages", handler.SendMessage)
+
+	requestBody := shared.CreateMessageRequest{
+		Type: "notification",
+		TTL:  "-5m",
+		Payload: map[string]interface{}{
+			"chatId": 123456,
+			"text":   "Your code is 123456",
+		},
+	}
+
+	jsonBody, _ := json.Marshal(requestBody)
+	req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestProducerHandler_SendMessage_OutOfScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	handler := NewProducerHandler(&MockKafkaService{}, zap.NewNop())
+	principal := &auth.Principal{
+		Method: auth.MethodAPIKey,
+		ID:     "billing",
+		Grants: []auth.Scopes{{Recipients: []string{"user:billing-*"}}},
+	}
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+	})
+	router.POST("/messages", handler.SendMessage)
+
+	tests := []struct {
+		name     string
+		userID   string
+		expected int
+	}{
+		{name: "recipient in scope", userID: "billing-42", expected: http.StatusCreated},
+		{name: "recipient out of scope", userID: "user-42", expected: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(shared.CreateMessageRequest{
+				Type:    shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"userId": tt.userID, "text": "Invoice ready"},
+			})
+			req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d: %s", tt.expected, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestProducerHandler_SendMessage_RecipientRateLimited(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := ratelimit.NewLimiter(ratelimit.NewMemoryStore(), &config.RateLimitConfig{Recipient: "1/1m"})
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	handler := NewProducerHandler(service.NewRateLimitedService(&MockKafkaService{}, limiter), zap.NewNop())
+
+	router := gin.New()
+	router.POST("/messages", handler.SendMessage)
+
+	send := func(chatID int64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(shared.CreateMessageRequest{
+			Type:    shared.MessageTypeNotification,
+			Payload: map[string]interface{}{"chatId": chatID, "text": "Test message"},
+		})
+		req, _ := http.NewRequest("POST", "/messages", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send(123456); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	w := send(123456)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected status %d, got %d", http.StatusTooManyRequests, w.Code)
+	}
+	if w.Header().Get("Retry-After") == "" {
+		t.Error("Expected Retry-After header")
+	}
+	if w := send(654321); w.Code != http.StatusCreated {
+		t.Errorf("Expected other chat to be allowed, got %d", w.Code)
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/bulk"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// Ошибки массового импорта
+var (
+	ErrInvalidImportID = errors.New("import id must be 1-64 characters: letters, digits, '.', '_' or '-'")
+	ErrImportRunning   = errors.New("import is already running")
+	ErrImportNotFound  = errors.New("import not found")
+)
+
+// importIDPattern ограничивает идентификатор импорта безопасным именем файла
+var importIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+// MessageSender определяет интерфейс отправки одного сообщения
+type MessageSender interface {
+	SendMessage(ctx context.Context, req *shared.CreateMessageRequest) (*shared.CreateMessageResponse, error)
+}
+
+// importMeta хранит владельца импорта — клиента, начавшего его
+type importMeta struct {
+	Owner string `json:"owner"`
+}
+
+// BulkService выполняет массовый импорт сообщений. Отчет каждого импорта
+// хранится в каталоге dir под именем <importId>.jsonl, поэтому повторная
+// загрузка с тем же идентификатором продолжает импорт. Продолжить импорт и
+// прочитать отчет может только клиент, начавший импорт: для остальных импорт
+// не найден
+type BulkService struct {
+	mu      sync.Mutex
+	running map[string]bool
+	dir     string
+	rate    float64
+	sender  MessageSender
+	logger  *zap.Logger
+}
+
+// NewBulkService создает новый экземпляр BulkService
+func NewBulkService(dir string, rate float64, sender MessageSender) *BulkService {
+	return &BulkService{
+		running: make(map[string]bool),
+		dir:     dir,
+		rate:    rate,
+		sender:  sender,
+		logger:  logger.GetLogger(),
+	}
+}
+
+// Import отправляет сообщения из JSONL потока r в рамках импорта importID
+func (s *BulkService) Import(ctx context.Context, importID string, r io.Reader) (*bulk.Summary, error) {
+	if !importIDPattern.MatchString(importID) {
+		return nil, ErrInvalidImportID
+	}
+
+	// Одновременный импорт с одним идентификатором испортил бы отчет
+	s.mu.Lock()
+	if s.running[importID] {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %s", ErrImportRunning, importID)
+	}
+	s.running[importID] = true
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.running, importID)
+		s.mu.Unlock()
+	}()
+
+	if err := s.claim(ctx, importID); err != nil {
+		return nil, err
+	}
+
+	logger.WithContext(ctx, s.logger).Info("Bulk import started", zap.String("importId", importID), zap.Float64("rate", s.rate))
+
+	importer := bulk.NewImporter(s.ReportPath(importID), s.rate)
+	summary, err := importer.Run(ctx, r, func(ctx context.Context, req *shared.CreateMessageRequest) (string, error) {
+		// Строки, выходящие за скоупы клиента, попадают в отчет как failed
+		if err := auth.Authorize(ctx, req); err != nil {
+			return "", err
+		}
+		response, err := s.sender.SendMessage(ctx, req)
+		if err != nil {
+			return "", err
+		}
+		return response.ID, nil
+	})
+
+	fields := []zap.Field{zap.String("importId", importID)}
+	if summary != nil {
+		fields = append(fields,
+			zap.Int("sent", summary.Sent),
+			zap.Int("skipped", summary.Skipped),
+			zap.Int("invalid", summary.Invalid),
+			zap.Int("failed", summary.Failed))
+	}
+	if err != nil {
+		logger.WithContext(ctx, s.logger).Error("Bulk import interrupted", append(fields, zap.Error(err))...)
+		return summary, err
+	}
+	logger.WithContext(ctx, s.logger).Info("Bulk import finished", fields...)
+	return summary, nil
+}
+
+// ReportPath возвращает путь к отчету импорта
+func (s *BulkService) ReportPath(importID string) string {
+	return filepath.Join(s.dir, importID+".jsonl")
+}
+
+// Report открывает отчет импорта клиента из ctx для чтения
+func (s *BulkService) Report(ctx context.Context, importID string) (io.ReadCloser, error) {
+	if !importIDPattern.MatchString(importID) {
+		return nil, ErrInvalidImportID
+	}
+	if err := s.checkOwner(ctx, importID); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(s.ReportPath(importID))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrImportNotFound, importID)
+	}
+	return file, err
+}
+
+// claim закрепляет новый импорт за клиентом из ctx или проверяет владельца
+// существующего. Вызывается, пока импорт отмечен как выполняющийся
+func (s *BulkService) claim(ctx context.Context, importID string) error {
+	_, err := os.Stat(s.ReportPath(importID))
+	if err == nil {
+		return s.checkOwner(ctx, importID)
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	owner := auth.PrincipalFromContext(ctx).Subject()
+	if err := s.meta(importID).Save(&importMeta{Owner: owner}); err != nil {
+		return fmt.Errorf("failed to save import owner: %w", err)
+	}
+	return nil
+}
+
+// checkOwner возвращает ErrImportNotFound, если импорт начат другим клиентом.
+// Импорт без сохраненного владельца принадлежит клиенту без аутентификации
+func (s *BulkService) checkOwner(ctx context.Context, importID string) error {
+	var meta importMeta
+	if err := s.meta(importID).Load(&meta); err != nil {
+		return err
+	}
+	if meta.Owner != auth.PrincipalFromContext(ctx).Subject() {
+		logger.WithContext(ctx, s.logger).Warn("Import belongs to another client", zap.String("importId", importID))
+		return fmt.Errorf("%w: %s", ErrImportNotFound, importID)
+	}
+	return nil
+}
+
+// meta возвращает файл с владельцем импорта
+func (s *BulkService) meta(importID string) *storage.JSONFile {
+	return storage.NewJSONFile(filepath.Join(s.dir, importID+".meta.json"))
+}
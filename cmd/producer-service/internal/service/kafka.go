@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"kafka-notification-system/pkg/config"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
 	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/tracing"
+	"time"
 
 	"github.com/segmentio/kafka-go"
 	"go.uber.org/zap"
@@ -13,25 +16,31 @@ import (
 
 // KafkaService обрабатывает отправку сообщений в Kafka
 type KafkaService struct {
-	writer *kafka.Writer
-	config *config.KafkaConfig
-	logger *zap.Logger
+	writer  *kafka.Writer
+	config  *config.KafkaConfig
+	metrics *metrics.Metrics
+	logger  *zap.Logger
 }
 
-// NewKafkaService создает новый экземпляр KafkaService
-func NewKafkaService(kafkaConfig *config.KafkaConfig) *KafkaService {
+// NewKafkaService создает новый экземпляр KafkaService. Метрики публикации
+// учитываются в m, если он задан
+func NewKafkaService(kafkaConfig *config.KafkaConfig, m *metrics.Metrics) *KafkaService {
+	// Топик задается для каждого сообщения отдельно, см. topicFor. Партиция
+	// выбирается по ключу, см. keyFor
 	writer := &kafka.Writer{
 		Addr:         kafka.TCP(kafkaConfig.Brokers...),
-		Topic:        kafkaConfig.NotificationsTopic,
-		Balancer:     &kafka.LeastBytes{},
+		Balancer:     &kafka.Hash{},
 		RequiredAcks: kafka.RequireOne,
 		Async:        false,
 	}
 
+	m.RegisterWriter("messages", writer)
+
 	return &KafkaService{
-		writer: writer,
-		config: kafkaConfig,
-		logger: logger.GetLogger(),
+		writer:  writer,
+		config:  kafkaConfig,
+		metrics: m,
+		logger:  logger.GetLogger(),
 	}
 }
 
@@ -40,42 +49,103 @@ func (s *KafkaService) SendMessage(ctx context.Context, req *shared.CreateMessag
 	// Создаем Kafka сообщение
 	message := shared.NewKafkaMessage(req.Type, req.Payload)
 
+	// Срок жизни отсчитывается от времени создания сообщения
+	expiresAt, err := req.ResolveExpiresAt(time.UnixMilli(message.Timestamp))
+	if err != nil {
+		return nil, fmt.Errorf("invalid message expiry: %w", err)
+	}
+	message.ExpiresAt = expiresAt
+	// Идентификатор запроса связывает логи всех сервисов, через которые пройдет сообщение
+	message.RequestID = logger.RequestIDFromContext(ctx)
+	log := logger.WithContext(ctx, s.logger)
+
 	// Конвертируем в JSON
 	messageBytes, err := message.ToJSON()
 	if err != nil {
-		s.logger.Error("Failed to marshal message", zap.Error(err))
+		log.Error("Failed to marshal message", zap.Error(err))
 		return nil, fmt.Errorf("failed to marshal message: %w", err)
 	}
 
 	// Создаем Kafka сообщение для отправки
 	kafkaMessage := kafka.Message{
-		Key:   []byte(message.ID),
+		Topic: s.topicFor(req),
+		Key:   []byte(keyFor(req, message)),
 		Value: messageBytes,
 		Headers: []kafka.Header{
 			{
-				Key:   "message-type",
+				Key:   shared.HeaderMessageType,
 				Value: []byte(req.Type),
 			},
+			{
+				Key:   shared.HeaderPriority,
+				Value: []byte(priorityOf(req)),
+			},
 		},
 	}
+	if message.RequestID != "" {
+		kafkaMessage.Headers = append(kafkaMessage.Headers, kafka.Header{
+			Key:   shared.HeaderRequestID,
+			Value: []byte(message.RequestID),
+		})
+	}
+
+	// Контекст трассировки передается потребителям в заголовках traceparent/tracestate
+	ctx, span := tracing.StartProducer(ctx, kafkaMessage.Topic)
+	kafkaMessage.Headers = tracing.Inject(ctx, kafkaMessage.Headers)
 
 	// Отправляем сообщение
+	start := time.Now()
 	err = s.writer.WriteMessages(ctx, kafkaMessage)
+	s.metrics.ObservePublish(kafkaMessage.Topic, req.Type, err, time.Since(start))
+	tracing.End(span, err)
 	if err != nil {
-		s.logger.Error("Failed to send message to Kafka", 
+		log.Error("Failed to send message to Kafka", 
 			zap.Error(err),
 			zap.String("messageId", message.ID),
 			zap.String("messageType", req.Type))
 		return nil, fmt.Errorf("failed to send message: %w", err)
 	}
 
-	s.logger.Info("Message sent successfully",
+	log.Info("Message sent successfully",
 		zap.String("messageId", message.ID),
-		zap.String("messageType", req.Type))
+		zap.String("messageType", req.Type),
+		zap.String("priority", priorityOf(req)))
 
 	return &shared.CreateMessageResponse{ID: message.ID}, nil
 }
 
+// topicFor выбирает топик для сообщения: рассылки на аудиторию уходят
+// в отдельный топик стадии fan-out, уведомления — в топик своего приоритета.
+// Приоритет рассылки передается в заголовке и учитывается при развертывании
+func (s *KafkaService) topicFor(req *shared.CreateMessageRequest) string {
+	if req.Type == shared.MessageTypeBroadcast {
+		return s.config.BroadcastsTopic
+	}
+	return s.config.PriorityTopic(req.Priority)
+}
+
+// keyFor выбирает ключ сообщения. Уведомления получают ключ получателя
+// (user:<userId> или chat:<chatId>), по которому Notification Service
+// распределяет их между обработчиками: уведомления одному получателю попадают
+// в одну партицию и обрабатываются по порядку. Остальные сообщения
+// распределяются по идентификатору
+func keyFor(req *shared.CreateMessageRequest, message *shared.KafkaMessage) string {
+	if req.Type == shared.MessageTypeNotification {
+		if recipient, err := req.Recipient(); err == nil {
+			return recipient
+		}
+	}
+	return message.ID
+}
+
+// priorityOf возвращает приоритет запроса, по умолчанию normal
+func priorityOf(req *shared.CreateMessageRequest) string {
+	if req.Priority == "" {
+		return shared.PriorityNormal
+	}
+	return req.Priority
+}
+
 // Close закрывает соединение с Kafka
 func (s *KafkaService) Close() error {
 	return s.writer.Close()
@@ -15,7 +15,7 @@ func TestNewKafkaService(t *testing.T) {
 		NotificationsTopic: "test-notifications",
 	}
 
-	service := NewKafkaService(kafkaConfig)
+	service := NewKafkaService(kafkaConfig, nil)
 
 	if service == nil {
 		t.Fatal("Expected non-nil service")
@@ -47,7 +47,7 @@ func TestKafkaService_SendMessage(t *testing.T) {
 		NotificationsTopic: "test-notifications",
 	}
 
-	service := NewKafkaService(kafkaConfig)
+	service := NewKafkaService(kafkaConfig, nil)
 	defer service.Close()
 
 	req := &shared.CreateMessageRequest{
@@ -82,7 +82,7 @@ func TestKafkaService_SendMessage_InvalidPayload(t *testing.T) {
 		NotificationsTopic: "test-notifications",
 	}
 
-	service := NewKafkaService(kafkaConfig)
+	service := NewKafkaService(kafkaConfig, nil)
 	defer service.Close()
 
 	// Test with invalid payload that can't be marshaled to JSON
@@ -99,3 +99,48 @@ func TestKafkaService_SendMessage_InvalidPayload(t *testing.T) {
 		t.Error("Expected error when sending message with invalid payload")
 	}
 }
+
+func TestKafkaService_TopicFor(t *testing.T) {
+	kafkaConfig := &config.KafkaConfig{
+		NotificationsTopic: "notifications",
+		HighPriorityTopic:  "notifications-high",
+		LowPriorityTopic:   "notifications-low",
+		BroadcastsTopic:    "broadcasts",
+	}
+	service := NewKafkaService(kafkaConfig, nil)
+
+	tests := []struct {
+		req      shared.CreateMessageRequest
+		expected string
+	}{
+		{shared.CreateMessageRequest{Type: shared.MessageTypeNotification}, "notifications"},
+		{shared.CreateMessageRequest{Type: shared.MessageTypeNotification, Priority: shared.PriorityHigh}, "notifications-high"},
+		{shared.CreateMessageRequest{Type: shared.MessageTypeNotification, Priority: shared.PriorityLow}, "notifications-low"},
+		{shared.CreateMessageRequest{Type: shared.MessageTypeBroadcast, Priority: shared.PriorityHigh}, "broadcasts"},
+	}
+
+	for _, tt := range tests {
+		if topic := service.topicFor(&tt.req); topic != tt.expected {
+			t.Errorf("Expected topic %s for %+v, got %s", tt.expected, tt.req, topic)
+		}
+	}
+}
+
+func TestKeyFor(t *testing.T) {
+	message := shared.NewKafkaMessage(shared.MessageTypeNotification, nil)
+
+	tests := []struct {
+		req      shared.CreateMessageRequest
+		expected string
+	}{
+		{shared.CreateMessageRequest{Type: shared.MessageTypeNotification, Payload: map[string]interface{}{"chatId": 123, "text": "hi"}}, "chat:123"},
+		{shared.CreateMessageRequest{Type: shared.MessageTypeNotification, Payload: map[string]interface{}{"userId": "u1", "chatId": 123, "text": "hi"}}, "user:u1"},
+		{shared.CreateMessageRequest{Type: shared.MessageTypeBroadcast, Payload: map[string]interface{}{"audience": "all", "text": "hi"}}, message.ID},
+	}
+
+	for _, tt := range tests {
+		if key := keyFor(&tt.req, message); key != tt.expected {
+			t.Errorf("Expected key %s for %+v, got %s", tt.expected, tt.req, key)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"go.uber.org/zap"
+)
+
+// RecipientLimiter ограничивает частоту сообщений одному адресату
+type RecipientLimiter interface {
+	AllowRecipient(ctx context.Context, recipient string) error
+}
+
+// messageService — сервис отправки сообщений, который нужно закрыть при остановке
+type messageService interface {
+	MessageSender
+	Close() error
+}
+
+// RateLimitedService проверяет лимит адресата перед отправкой сообщения. Лимит
+// действует и на одиночные сообщения, и на строки массового импорта
+type RateLimitedService struct {
+	service messageService
+	limiter RecipientLimiter
+	logger  *zap.Logger
+}
+
+// NewRateLimitedService создает новый экземпляр RateLimitedService
+func NewRateLimitedService(service messageService, limiter RecipientLimiter) *RateLimitedService {
+	return &RateLimitedService{
+		service: service,
+		limiter: limiter,
+		logger:  logger.GetLogger(),
+	}
+}
+
+// SendMessage отправляет сообщение, если адресат не превысил лимит. При
+// превышении возвращает *ratelimit.LimitError
+func (s *RateLimitedService) SendMessage(ctx context.Context, req *shared.CreateMessageRequest) (*shared.CreateMessageResponse, error) {
+	recipient, err := req.Recipient()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.limiter.AllowRecipient(ctx, recipient); err != nil {
+		logger.WithContext(ctx, s.logger).Warn("Recipient rate limit exceeded",
+			logger.Contact("recipient", recipient),
+			zap.Error(err))
+		return nil, err
+	}
+	return s.service.SendMessage(ctx, req)
+}
+
+// Close закрывает сервис отправки
+func (s *RateLimitedService) Close() error {
+	return s.service.Close()
+}
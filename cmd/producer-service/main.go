@@ -3,17 +3,22 @@ package main
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
 	"kafka-notification-system/cmd/producer-service/internal/handler"
 	"kafka-notification-system/cmd/producer-service/internal/service"
+	"kafka-notification-system/pkg/auth"
 	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
 	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/metrics"
+	"kafka-notification-system/pkg/ratelimit"
+	"kafka-notification-system/pkg/tracing"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -26,65 +31,164 @@ import (
 // @description The Producer Service API for Kafka Notification System
 // @host localhost:3000
 // @BasePath /
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
 func main() {
 	// Загружаем конфигурацию
 	appConfig := config.LoadAppConfig()
+	accessLogConfig := config.LoadAccessLogConfig()
+	adminConfig := config.LoadAdminConfig()
+	if adminConfig.Port == "" {
+		adminConfig.Port = "3100" // Порт административного API producer по умолчанию
+	}
 	kafkaConfig := config.LoadKafkaConfig("producer-service", "")
+	bulkConfig := config.LoadBulkConfig()
+	tracingConfig := config.LoadTracingConfig()
+	healthConfig := config.LoadHealthConfig()
+	rateLimitConfig := config.LoadRateLimitConfig()
+	authConfig := config.LoadAuthConfig(appConfig.Environment)
+	if authConfig.APIKeysFile == "" {
+		authConfig.APIKeysFile = filepath.Join(appConfig.DataDir, "api-keys.json")
+	}
 
 	// Инициализируем логгер
 	logger.InitLogger(appConfig.Environment)
 	log := logger.GetLogger()
 
+	// Метрики Prometheus помечаются именем сервиса из конфигурации Kafka
+	serviceMetrics := metrics.New(kafkaConfig.ClientID)
+
+	// Трассировка OpenTelemetry, спаны отправляются при остановке сервиса
+	shutdownTracing, err := tracing.Init(kafkaConfig.ClientID, tracingConfig)
+	if err != nil {
+		log.Fatal("Failed to init tracing", zap.Error(err))
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			log.Error("Failed to flush traces", zap.Error(err))
+		}
+	}()
+
 	// Создаем сервисы
-	kafkaService := service.NewKafkaService(kafkaConfig)
+	kafkaService := service.NewKafkaService(kafkaConfig, serviceMetrics)
 	defer func() {
 		if err := kafkaService.Close(); err != nil {
 			log.Error("Failed to close Kafka service", zap.Error(err))
 		}
 	}()
 
+	// Лимиты частоты: запросы клиента проверяет middleware, сообщения адресату — сервис отправки
+	rateLimitStore, err := ratelimit.NewStore(rateLimitConfig.Store)
+	if err != nil {
+		log.Fatal("Failed to create rate limit store", zap.Error(err))
+	}
+	limiter, err := ratelimit.NewLimiter(rateLimitStore, rateLimitConfig)
+	if err != nil {
+		log.Fatal("Invalid rate limit configuration", zap.Error(err))
+	}
+	messageService := service.NewRateLimitedService(kafkaService, limiter)
+
+	bulkService := service.NewBulkService(filepath.Join(appConfig.DataDir, "imports"), bulkConfig.Rate, messageService)
+
+	// API ключи клиентов хранятся в виде хешей вместе со скоупами
+	keyStore, err := auth.NewKeyStore(authConfig.APIKeysFile)
+	if err != nil {
+		log.Fatal("Failed to load API keys", zap.Error(err))
+	}
+
+	// Клиент аутентифицируется API ключом или bearer токеном, в зависимости от настроек
+	var authenticators []auth.Authenticator
+	if authConfig.APIKeysEnabled {
+		authenticators = append(authenticators, keyStore)
+	}
+	if authConfig.JWTEnabled {
+		jwtAuth, err := newJWTAuthenticator(authConfig)
+		if err != nil {
+			log.Fatal("Failed to configure JWT authentication", zap.Error(err))
+		}
+		authenticators = append(authenticators, jwtAuth)
+	}
+
 	// Создаем обработчики
-	producerHandler := handler.NewProducerHandler(kafkaService, log)
+	producerHandler := handler.NewProducerHandler(messageService, log)
+	bulkHandler := handler.NewBulkHandler(bulkService, log)
+	apiKeyHandler := handler.NewAPIKeyHandler(keyStore, log)
+
+	// Проверка готовности: брокеры доступны и топики публикации существуют
+	checker := health.NewChecker(healthConfig.CheckTimeout)
+	checker.Add("kafka", health.KafkaCheck(kafkaConfig.Brokers,
+		append(kafkaConfig.NotificationTopics(), kafkaConfig.BroadcastsTopic)))
 
 	// Настраиваем Gin
 	if appConfig.Environment == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
-	router := gin.Default()
-
-	// Middleware для логирования
-	router.Use(gin.LoggerWithFormatter(func(param gin.LogFormatterParams) string {
-		return fmt.Sprintf("%s - [%s] \"%s %s %s %d %s \"%s\" %s\"\n",
-			param.ClientIP,
-			param.TimeStamp.Format(time.RFC1123),
-			param.Method,
-			param.Path,
-			param.Request.Proto,
-			param.StatusCode,
-			param.Latency,
-			param.Request.UserAgent(),
-			param.ErrorMessage,
-		)
-	}))
-
+	// Один журнал запросов и один recovery: gin.Default добавил бы свои
+	router := gin.New()
+	// IP клиента для лимита запросов без аутентификации берется из X-Forwarded-For
+	// только от доверенных прокси, иначе заголовок позволил бы обойти лимит
+	if err := router.SetTrustedProxies(rateLimitConfig.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES", zap.Error(err))
+	}
+	router.Use(logger.RequestID())
+	router.Use(logger.AccessLog(log, accessLogConfig))
 	router.Use(gin.Recovery())
+	router.Use(serviceMetrics.Middleware())
+	router.Use(tracing.Middleware())
+
+	// Настраиваем маршруты. Отправка сообщений требует аутентификации, если она включена
+	messages := router.Group("/messages")
+	if len(authenticators) > 0 {
+		messages.Use(auth.Middleware(authenticators...))
+	} else {
+		log.Warn("Authentication is disabled, /messages is open")
+	}
+	messages.Use(limiter.Middleware())
+	{
+		messages.POST("", producerHandler.SendMessage)
+		messages.POST("/bulk", bulkHandler.ImportMessages)
+		messages.GET("/bulk/:importId/report", bulkHandler.ImportReport)
+	}
 
-	// Настраиваем маршруты
 	v1 := router.Group("/")
 	{
-		v1.POST("/messages", producerHandler.SendMessage)
 		v1.GET("/health", producerHandler.Health)
+		v1.GET("/livez", checker.Livez)
+		v1.GET("/readyz", checker.Readyz)
 	}
+	router.GET("/metrics", gin.WrapH(serviceMetrics.Handler()))
 
 	// Swagger документация
 	router.GET("/api/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	// Создаем HTTP сервер
+	// Административное API слушает отдельный порт
+	adminRouter := gin.New()
+	adminRouter.Use(logger.RequestID(), logger.AccessLog(log, accessLogConfig), gin.Recovery())
+	admin := adminRouter.Group("/")
+	{
+		admin.GET("/log-level", logger.Levels().GetLevel)
+		admin.PUT("/log-level", logger.Levels().PutLevel)
+		admin.GET("/api-keys", apiKeyHandler.ListKeys)
+		admin.POST("/api-keys", apiKeyHandler.CreateKey)
+		admin.GET("/api-keys/:id", apiKeyHandler.GetKey)
+		admin.PUT("/api-keys/:id", apiKeyHandler.UpdateKey)
+		admin.DELETE("/api-keys/:id", apiKeyHandler.DeleteKey)
+	}
+
+	// Создаем HTTP серверы
 	srv := &http.Server{
 		Addr:    ":" + appConfig.Port,
 		Handler: router,
 	}
+	adminSrv := &http.Server{
+		Addr:    ":" + adminConfig.Port,
+		Handler: adminRouter,
+	}
 
 	// Запускаем сервер в горутине
 	go func() {
@@ -99,6 +203,14 @@ func main() {
 		}
 	}()
 
+	go func() {
+		log.Info("Starting Producer Service admin API", zap.String("port", adminConfig.Port))
+
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start admin server", zap.Error(err))
+		}
+	}()
+
 	// Ожидаем сигнал завершения
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -110,9 +222,49 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	if err := adminSrv.Shutdown(ctx); err != nil {
+		log.Error("Admin server forced to shutdown", zap.Error(err))
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatal("Server forced to shutdown", zap.Error(err))
 	}
 
 	log.Info("Producer Service stopped")
 }
+
+// newJWTAuthenticator создает проверку bearer токенов с ключами из файла или по адресу JWKS
+func newJWTAuthenticator(cfg *config.AuthConfig) (*auth.JWTAuthenticator, error) {
+	log := logger.GetLogger()
+
+	var keys auth.KeySource
+	switch {
+	case cfg.JWKSFile != "" && cfg.JWKSURL != "":
+		return nil, fmt.Errorf("only one of JWT_JWKS_FILE and JWT_JWKS_URL can be set")
+	case cfg.JWKSFile != "":
+		jwks, err := auth.LoadJWKSFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		keys = jwks
+	case cfg.JWKSURL != "":
+		keys = auth.NewJWKSFromURL(cfg.JWKSURL, cfg.JWKSRefresh)
+	default:
+		return nil, fmt.Errorf("JWT_JWKS_FILE or JWT_JWKS_URL is required")
+	}
+
+	var mapping auth.ScopeMapping
+	if cfg.ScopesFile != "" {
+		var err error
+		if mapping, err = auth.LoadScopeMapping(cfg.ScopesFile); err != nil {
+			return nil, err
+		}
+	} else {
+		log.Warn("JWT_SCOPES_FILE is not set, bearer tokens are not restricted by scopes")
+	}
+	// Без проверки iss и aud принимались бы токены других приложений того же identity provider
+	if cfg.Issuer == "" || cfg.Audience == "" {
+		return nil, fmt.Errorf("JWT_ISSUER and JWT_AUDIENCE are required when JWT_ENABLED=true")
+	}
+
+	return auth.NewJWTAuthenticator(cfg, keys, mapping), nil
+}
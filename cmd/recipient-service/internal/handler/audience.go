@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"kafka-notification-system/cmd/recipient-service/internal/service"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// AudienceStoreInterface определяет интерфейс хранилища аудиторий
+type AudienceStoreInterface interface {
+	List() []*shared.Audience
+	Get(name string) (*shared.Audience, error)
+	Create(a *shared.Audience) (*shared.Audience, error)
+	Update(name string, a *shared.Audience) (*shared.Audience, error)
+	Delete(name string) error
+}
+
+// AudienceHandler обрабатывает HTTP запросы к аудиториям рассылок
+type AudienceHandler struct {
+	store  AudienceStoreInterface
+	logger *zap.Logger
+}
+
+// NewAudienceHandler создает новый экземпляр AudienceHandler
+func NewAudienceHandler(store AudienceStoreInterface, logger *zap.Logger) *AudienceHandler {
+	return &AudienceHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// ListAudiences godoc
+// @Summary List audiences
+// @Description Get all broadcast audiences
+// @Tags Audiences
+// @Produce json
+// @Success 200 {array} shared.Audience
+// @Router /audiences [get]
+func (h *AudienceHandler) ListAudiences(c *gin.Context) {
+	c.JSON(http.StatusOK, h.store.List())
+}
+
+// GetAudience godoc
+// @Summary Get audience
+// @Description Get a broadcast audience with its members
+// @Tags Audiences
+// @Produce json
+// @Param name path string true "Audience name"
+// @Success 200 {object} shared.Audience
+// @Failure 404 {object} map[string]string
+// @Router /audiences/{name} [get]
+func (h *AudienceHandler) GetAudience(c *gin.Context) {
+	audience, err := h.store.Get(c.Param("name"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, audience)
+}
+
+// CreateAudience godoc
+// @Summary Create audience
+// @Description Define a named group of recipients for broadcasts
+// @Tags Audiences
+// @Accept json
+// @Produce json
+// @Param audience body shared.Audience true "Audience to create"
+// @Success 201 {object} shared.Audience
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /audiences [post]
+func (h *AudienceHandler) CreateAudience(c *gin.Context) {
+	var req shared.Audience
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audience format"})
+		return
+	}
+
+	if req.Name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+
+	audience, err := h.store.Create(&req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Audience created",
+		zap.String("audience", audience.Name),
+		zap.Int("members", len(audience.Members)))
+	c.JSON(http.StatusCreated, audience)
+}
+
+// UpdateAudience godoc
+// @Summary Update audience
+// @Description Replace description and members of an audience
+// @Tags Audiences
+// @Accept json
+// @Produce json
+// @Param name path string true "Audience name"
+// @Param audience body shared.Audience true "Audience data"
+// @Success 200 {object} shared.Audience
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /audiences/{name} [put]
+func (h *AudienceHandler) UpdateAudience(c *gin.Context) {
+	var req shared.Audience
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audience format"})
+		return
+	}
+
+	audience, err := h.store.Update(c.Param("name"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Audience updated",
+		zap.String("audience", audience.Name),
+		zap.Int("members", len(audience.Members)))
+	c.JSON(http.StatusOK, audience)
+}
+
+// DeleteAudience godoc
+// @Summary Delete audience
+// @Description Remove a broadcast audience
+// @Tags Audiences
+// @Param name path string true "Audience name"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /audiences/{name} [delete]
+func (h *AudienceHandler) DeleteAudience(c *gin.Context) {
+	if err := h.store.Delete(c.Param("name")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Audience deleted", zap.String("audience", c.Param("name")))
+	c.Status(http.StatusNoContent)
+}
+
+// respondError преобразует ошибку хранилища в HTTP ответ
+func (h *AudienceHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrAudienceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Audience not found"})
+	case errors.Is(err, service.ErrAudienceExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Audience already exists"})
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Audience store error", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
+	}
+}
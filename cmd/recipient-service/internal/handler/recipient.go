@@ -0,0 +1,247 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"kafka-notification-system/cmd/recipient-service/internal/service"
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// RecipientStoreInterface определяет интерфейс хранилища получателей
+type RecipientStoreInterface interface {
+	List() []*shared.Recipient
+	Get(id string) (*shared.Recipient, error)
+	Create(r *shared.Recipient) (*shared.Recipient, error)
+	Update(id string, r *shared.Recipient) (*shared.Recipient, error)
+	Delete(id string) error
+	UpdatePreferences(id string, prefs shared.Preferences) (*shared.Recipient, error)
+}
+
+// RecipientHandler обрабатывает HTTP запросы для Recipient Service
+type RecipientHandler struct {
+	store  RecipientStoreInterface
+	logger *zap.Logger
+}
+
+// NewRecipientHandler создает новый экземпляр RecipientHandler
+func NewRecipientHandler(store RecipientStoreInterface, logger *zap.Logger) *RecipientHandler {
+	return &RecipientHandler{
+		store:  store,
+		logger: logger,
+	}
+}
+
+// ListRecipients godoc
+// @Summary List recipients
+// @Description Get all recipients from the registry
+// @Tags Recipients
+// @Produce json
+// @Success 200 {array} shared.Recipient
+// @Router /recipients [get]
+func (h *RecipientHandler) ListRecipients(c *gin.Context) {
+	c.JSON(http.StatusOK, h.store.List())
+}
+
+// GetRecipient godoc
+// @Summary Get recipient
+// @Description Get a recipient by user ID
+// @Tags Recipients
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} shared.Recipient
+// @Failure 404 {object} map[string]string
+// @Router /recipients/{id} [get]
+func (h *RecipientHandler) GetRecipient(c *gin.Context) {
+	recipient, err := h.store.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recipient)
+}
+
+// CreateRecipient godoc
+// @Summary Create recipient
+// @Description Register a recipient with contact points and channel priorities
+// @Tags Recipients
+// @Accept json
+// @Produce json
+// @Param recipient body shared.Recipient true "Recipient to create"
+// @Success 201 {object} shared.Recipient
+// @Failure 400 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /recipients [post]
+func (h *RecipientHandler) CreateRecipient(c *gin.Context) {
+	var req shared.Recipient
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient format"})
+		return
+	}
+
+	if err := validateRecipient(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.store.Create(&req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Recipient created", zap.String("userId", recipient.ID))
+	c.JSON(http.StatusCreated, recipient)
+}
+
+// UpdateRecipient godoc
+// @Summary Update recipient
+// @Description Replace contact points and channel priorities of a recipient
+// @Tags Recipients
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param recipient body shared.Recipient true "Recipient data"
+// @Success 200 {object} shared.Recipient
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recipients/{id} [put]
+func (h *RecipientHandler) UpdateRecipient(c *gin.Context) {
+	var req shared.Recipient
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid recipient format"})
+		return
+	}
+
+	if err := validateRecipient(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.store.Update(c.Param("id"), &req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Recipient updated", zap.String("userId", recipient.ID))
+	c.JSON(http.StatusOK, recipient)
+}
+
+// DeleteRecipient godoc
+// @Summary Delete recipient
+// @Description Remove a recipient from the registry
+// @Tags Recipients
+// @Param id path string true "User ID"
+// @Success 204
+// @Failure 404 {object} map[string]string
+// @Router /recipients/{id} [delete]
+func (h *RecipientHandler) DeleteRecipient(c *gin.Context) {
+	if err := h.store.Delete(c.Param("id")); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Recipient deleted", zap.String("userId", c.Param("id")))
+	c.Status(http.StatusNoContent)
+}
+
+// GetPreferences godoc
+// @Summary Get recipient preferences
+// @Description Get opt-out, category and quiet hours preferences of a recipient
+// @Tags Recipients
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} shared.Preferences
+// @Failure 404 {object} map[string]string
+// @Router /recipients/{id}/preferences [get]
+func (h *RecipientHandler) GetPreferences(c *gin.Context) {
+	recipient, err := h.store.Get(c.Param("id"))
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, recipient.Preferences)
+}
+
+// UpdatePreferences godoc
+// @Summary Update recipient preferences
+// @Description Replace opt-out, category and quiet hours preferences of a recipient
+// @Tags Recipients
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param preferences body shared.Preferences true "Preferences"
+// @Success 200 {object} shared.Preferences
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /recipients/{id}/preferences [put]
+func (h *RecipientHandler) UpdatePreferences(c *gin.Context) {
+	var req shared.Preferences
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.WithContext(c.Request.Context(), h.logger).Error("Invalid request body", zap.Error(err))
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid preferences format"})
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipient, err := h.store.UpdatePreferences(c.Param("id"), req)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	logger.WithContext(c.Request.Context(), h.logger).Info("Recipient preferences updated",
+		zap.String("userId", recipient.ID),
+		zap.Bool("optOut", recipient.Preferences.OptOut))
+	c.JSON(http.StatusOK, recipient.Preferences)
+}
+
+// Health godoc
+// @Summary Health check
+// @Description Get the health status of the recipient service
+// @Tags Health
+// @Produce json
+// @Success 200 {object} shared.HealthResponse
+// @Router /health [get]
+func (h *RecipientHandler) Health(c *gin.Context) {
+	c.JSON(http.StatusOK, shared.HealthResponse{Status: "ok"})
+}
+
+// respondError преобразует ошибку хранилища в HTTP ответ
+func (h *RecipientHandler) respondError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrRecipientNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Recipient not found"})
+	case errors.Is(err, service.ErrRecipientExists):
+		c.JSON(http.StatusConflict, gin.H{"error": "Recipient already exists"})
+	default:
+		logger.WithContext(c.Request.Context(), h.logger).Error("Recipient store error", zap.Error(err))
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
+	}
+}
+
+// validateRecipient проверяет контакты, приоритеты каналов и настройки получателя
+func validateRecipient(r *shared.Recipient) error {
+	for _, channel := range r.Channels {
+		if !shared.IsValidChannel(channel) {
+			return fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+
+	if len(r.DeliveryChannels()) == 0 {
+		return errors.New("recipient must have a contact point for at least one channel")
+	}
+	return r.Preferences.Validate()
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrAudienceNotFound возвращается, если аудитория не найдена
+	ErrAudienceNotFound = errors.New("audience not found")
+	// ErrAudienceExists возвращается при попытке создать уже существующую аудиторию
+	ErrAudienceExists = errors.New("audience already exists")
+)
+
+// AudienceStore хранит аудитории рассылок в памяти и сохраняет их на диск
+type AudienceStore struct {
+	mu        sync.RWMutex
+	audiences map[string]*shared.Audience
+	file      *storage.JSONFile
+	logger    *zap.Logger
+}
+
+// NewAudienceStore создает новый экземпляр AudienceStore и загружает сохраненные аудитории
+func NewAudienceStore(path string) (*AudienceStore, error) {
+	s := &AudienceStore{
+		audiences: make(map[string]*shared.Audience),
+		file:      storage.NewJSONFile(path),
+		logger:    logger.GetLogger(),
+	}
+
+	var saved []*shared.Audience
+	if err := s.file.Load(&saved); err != nil {
+		return nil, err
+	}
+	for _, a := range saved {
+		s.audiences[a.Name] = a
+	}
+
+	s.logger.Info("Audiences loaded",
+		zap.String("path", path),
+		zap.Int("audiences", len(s.audiences)))
+
+	return s, nil
+}
+
+// List возвращает все аудитории, отсортированные по названию
+func (s *AudienceStore) List() []*shared.Audience {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*shared.Audience, 0, len(s.audiences))
+	for _, a := range s.audiences {
+		copied := *a
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Get возвращает аудиторию по названию
+func (s *AudienceStore) Get(name string) (*shared.Audience, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	a, ok := s.audiences[name]
+	if !ok {
+		return nil, ErrAudienceNotFound
+	}
+	copied := *a
+	return &copied, nil
+}
+
+// Create добавляет новую аудиторию
+func (s *AudienceStore) Create(a *shared.Audience) (*shared.Audience, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.audiences[a.Name]; exists {
+		return nil, ErrAudienceExists
+	}
+
+	now := shared.GetCurrentTimestamp()
+	a.CreatedAt = now
+	a.UpdatedAt = now
+	s.audiences[a.Name] = a
+
+	if err := s.persist(); err != nil {
+		delete(s.audiences, a.Name)
+		return nil, err
+	}
+
+	copied := *a
+	return &copied, nil
+}
+
+// Update заменяет описание и участников существующей аудитории
+func (s *AudienceStore) Update(name string, a *shared.Audience) (*shared.Audience, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.audiences[name]
+	if !ok {
+		return nil, ErrAudienceNotFound
+	}
+
+	a.Name = name
+	a.CreatedAt = existing.CreatedAt
+	a.UpdatedAt = shared.GetCurrentTimestamp()
+	s.audiences[name] = a
+
+	if err := s.persist(); err != nil {
+		s.audiences[name] = existing
+		return nil, err
+	}
+
+	copied := *a
+	return &copied, nil
+}
+
+// Delete удаляет аудиторию
+func (s *AudienceStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.audiences[name]
+	if !ok {
+		return ErrAudienceNotFound
+	}
+	delete(s.audiences, name)
+
+	if err := s.persist(); err != nil {
+		s.audiences[name] = existing
+		return err
+	}
+	return nil
+}
+
+// persist сохраняет аудитории на диск. Вызывается под блокировкой
+func (s *AudienceStore) persist() error {
+	snapshot := make([]*shared.Audience, 0, len(s.audiences))
+	for _, a := range s.audiences {
+		snapshot = append(snapshot, a)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+
+	if err := s.file.Save(snapshot); err != nil {
+		s.logger.Error("Failed to persist audiences", zap.Error(err))
+		return fmt.Errorf("failed to persist audiences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrRecipientNotFound возвращается, если получатель не найден
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrRecipientExists возвращается при попытке создать уже существующего получателя
+	ErrRecipientExists = errors.New("recipient already exists")
+)
+
+// RecipientStore хранит реестр получателей в памяти и сохраняет его на диск
+type RecipientStore struct {
+	mu         sync.RWMutex
+	recipients map[string]*shared.Recipient
+	file       *storage.JSONFile
+	logger     *zap.Logger
+}
+
+// NewRecipientStore создает новый экземпляр RecipientStore и загружает сохраненный реестр
+func NewRecipientStore(path string) (*RecipientStore, error) {
+	s := &RecipientStore{
+		recipients: make(map[string]*shared.Recipient),
+		file:       storage.NewJSONFile(path),
+		logger:     logger.GetLogger(),
+	}
+
+	var saved []*shared.Recipient
+	if err := s.file.Load(&saved); err != nil {
+		return nil, err
+	}
+	for _, r := range saved {
+		s.recipients[r.ID] = r
+	}
+
+	s.logger.Info("Recipient registry loaded",
+		zap.String("path", path),
+		zap.Int("recipients", len(s.recipients)))
+
+	return s, nil
+}
+
+// List возвращает всех получателей, отсортированных по идентификатору
+func (s *RecipientStore) List() []*shared.Recipient {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*shared.Recipient, 0, len(s.recipients))
+	for _, r := range s.recipients {
+		copied := *r
+		result = append(result, &copied)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Get возвращает получателя по идентификатору
+func (s *RecipientStore) Get(id string) (*shared.Recipient, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	r, ok := s.recipients[id]
+	if !ok {
+		return nil, ErrRecipientNotFound
+	}
+	copied := *r
+	return &copied, nil
+}
+
+// Create добавляет нового получателя. Если ID не задан, он генерируется
+func (s *RecipientStore) Create(r *shared.Recipient) (*shared.Recipient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r.ID == "" {
+		r.ID = uuid.New().String()
+	}
+	if _, exists := s.recipients[r.ID]; exists {
+		return nil, ErrRecipientExists
+	}
+
+	now := shared.GetCurrentTimestamp()
+	r.CreatedAt = now
+	r.UpdatedAt = now
+	s.recipients[r.ID] = r
+
+	if err := s.persist(); err != nil {
+		delete(s.recipients, r.ID)
+		return nil, err
+	}
+
+	copied := *r
+	return &copied, nil
+}
+
+// Update заменяет данные существующего получателя
+func (s *RecipientStore) Update(id string, r *shared.Recipient) (*shared.Recipient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.recipients[id]
+	if !ok {
+		return nil, ErrRecipientNotFound
+	}
+
+	r.ID = id
+	r.CreatedAt = existing.CreatedAt
+	r.UpdatedAt = shared.GetCurrentTimestamp()
+	s.recipients[id] = r
+
+	if err := s.persist(); err != nil {
+		s.recipients[id] = existing
+		return nil, err
+	}
+
+	copied := *r
+	return &copied, nil
+}
+
+// UpdatePreferences заменяет настройки доставки получателя
+func (s *RecipientStore) UpdatePreferences(id string, prefs shared.Preferences) (*shared.Recipient, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.recipients[id]
+	if !ok {
+		return nil, ErrRecipientNotFound
+	}
+
+	updated := *existing
+	updated.Preferences = prefs
+	updated.UpdatedAt = shared.GetCurrentTimestamp()
+	s.recipients[id] = &updated
+
+	if err := s.persist(); err != nil {
+		s.recipients[id] = existing
+		return nil, err
+	}
+
+	copied := updated
+	return &copied, nil
+}
+
+// Delete удаляет получателя из реестра
+func (s *RecipientStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.recipients[id]
+	if !ok {
+		return ErrRecipientNotFound
+	}
+	delete(s.recipients, id)
+
+	if err := s.persist(); err != nil {
+		s.recipients[id] = existing
+		return err
+	}
+	return nil
+}
+
+// persist сохраняет реестр на диск. Вызывается под блокировкой
+func (s *RecipientStore) persist() error {
+	snapshot := make([]*shared.Recipient, 0, len(s.recipients))
+	for _, r := range s.recipients {
+		snapshot = append(snapshot, r)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].ID < snapshot[j].ID })
+
+	if err := s.file.Save(snapshot); err != nil {
+		s.logger.Error("Failed to persist recipient registry", zap.Error(err))
+		return fmt.Errorf("failed to persist recipients: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"path/filepath"
+	"testing"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+func TestRecipientStore_CRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipients.json")
+
+	store, err := NewRecipientStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	created, err := store.Create(&shared.Recipient{
+		ID:       "user-1",
+		Contacts: shared.ContactPoints{TelegramChatID: 123},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create recipient: %v", err)
+	}
+	if created.CreatedAt == 0 {
+		t.Error("Expected non-zero createdAt")
+	}
+
+	if _, err := store.Create(&shared.Recipient{ID: "user-1"}); err != ErrRecipientExists {
+		t.Errorf("Expected ErrRecipientExists, got %v", err)
+	}
+
+	updated, err := store.Update("user-1", &shared.Recipient{
+		Contacts: shared.ContactPoints{Email: "user@example.com"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to update recipient: %v", err)
+	}
+	if updated.Contacts.Email != "user@example.com" {
+		t.Errorf("Expected updated email, got %s", updated.Contacts.Email)
+	}
+	if updated.CreatedAt != created.CreatedAt {
+		t.Error("Expected createdAt to be preserved")
+	}
+
+	if err := store.Delete("user-1"); err != nil {
+		t.Fatalf("Failed to delete recipient: %v", err)
+	}
+	if _, err := store.Get("user-1"); err != ErrRecipientNotFound {
+		t.Errorf("Expected ErrRecipientNotFound, got %v", err)
+	}
+}
+
+func TestRecipientStore_Persistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "recipients.json")
+
+	store, err := NewRecipientStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create store: %v", err)
+	}
+
+	if _, err := store.Create(&shared.Recipient{
+		ID:       "user-1",
+		Contacts: shared.ContactPoints{TelegramChatID: 123},
+	}); err != nil {
+		t.Fatalf("Failed to create recipient: %v", err)
+	}
+
+	reloaded, err := NewRecipientStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload store: %v", err)
+	}
+
+	recipient, err := reloaded.Get("user-1")
+	if err != nil {
+		t.Fatalf("Expected recipient after reload: %v", err)
+	}
+	if recipient.Contacts.TelegramChatID != 123 {
+		t.Errorf("Expected chatId 123, got %d", recipient.Contacts.TelegramChatID)
+	}
+}
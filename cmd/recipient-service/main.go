@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+	_ "time/tzdata" // часовые пояса для проверки окон тишины в образах без tzdata
+
+	"kafka-notification-system/cmd/recipient-service/internal/handler"
+	"kafka-notification-system/cmd/recipient-service/internal/service"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/health"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// @title Recipient Service API
+// @version 1.0
+// @description The Recipient registry API for Kafka Notification System
+// @host localhost:3003
+// @BasePath /
+func main() {
+	// Загружаем конфигурацию
+	appConfig := config.LoadAppConfig()
+	if appConfig.Port == "3000" {
+		appConfig.Port = "3003" // Устанавливаем порт по умолчанию для recipient
+	}
+	accessLogConfig := config.LoadAccessLogConfig()
+	adminConfig := config.LoadAdminConfig()
+	if adminConfig.Port == "" {
+		adminConfig.Port = "3103" // Порт административного API recipient по умолчанию
+	}
+
+	// Инициализируем логгер
+	logger.InitLogger(appConfig.Environment)
+	log := logger.GetLogger()
+
+	// Создаем хранилище получателей
+	store, err := service.NewRecipientStore(filepath.Join(appConfig.DataDir, "recipients.json"))
+	if err != nil {
+		log.Fatal("Failed to load recipient registry", zap.Error(err))
+	}
+
+	audienceStore, err := service.NewAudienceStore(filepath.Join(appConfig.DataDir, "audiences.json"))
+	if err != nil {
+		log.Fatal("Failed to load audiences", zap.Error(err))
+	}
+
+	// Создаем обработчики
+	recipientHandler := handler.NewRecipientHandler(store, log)
+	audienceHandler := handler.NewAudienceHandler(audienceStore, log)
+
+	// Проверка готовности: реестр хранится в файлах каталога состояния
+	checker := health.NewChecker(config.LoadHealthConfig().CheckTimeout)
+	checker.Add("storage", health.DirCheck(appConfig.DataDir))
+
+	// Настраиваем Gin
+	if appConfig.Environment == "production" {
+		gin.SetMode(gin.ReleaseMode)
+	}
+
+	// Один журнал запросов и один recovery: gin.Default добавил бы свои
+	router := gin.New()
+	router.Use(logger.RequestID())
+	router.Use(logger.AccessLog(log, accessLogConfig))
+	router.Use(gin.Recovery())
+
+	// Настраиваем маршруты
+	v1 := router.Group("/")
+	{
+		v1.GET("/recipients", recipientHandler.ListRecipients)
+		v1.POST("/recipients", recipientHandler.CreateRecipient)
+		v1.GET("/recipients/:id", recipientHandler.GetRecipient)
+		v1.PUT("/recipients/:id", recipientHandler.UpdateRecipient)
+		v1.DELETE("/recipients/:id", recipientHandler.DeleteRecipient)
+		v1.GET("/recipients/:id/preferences", recipientHandler.GetPreferences)
+		v1.PUT("/recipients/:id/preferences", recipientHandler.UpdatePreferences)
+		v1.GET("/audiences", audienceHandler.ListAudiences)
+		v1.POST("/audiences", audienceHandler.CreateAudience)
+		v1.GET("/audiences/:name", audienceHandler.GetAudience)
+		v1.PUT("/audiences/:name", audienceHandler.UpdateAudience)
+		v1.DELETE("/audiences/:name", audienceHandler.DeleteAudience)
+		v1.GET("/health", recipientHandler.Health)
+		v1.GET("/livez", checker.Livez)
+		v1.GET("/readyz", checker.Readyz)
+	}
+
+	// Административное API слушает отдельный порт
+	adminRouter := gin.New()
+	adminRouter.Use(logger.RequestID(), logger.AccessLog(log, accessLogConfig), gin.Recovery())
+	admin := adminRouter.Group("/")
+	{
+		admin.GET("/log-level", logger.Levels().GetLevel)
+		admin.PUT("/log-level", logger.Levels().PutLevel)
+	}
+
+	// Создаем HTTP серверы
+	srv := &http.Server{
+		Addr:    ":" + appConfig.Port,
+		Handler: router,
+	}
+	adminSrv := &http.Server{
+		Addr:    ":" + adminConfig.Port,
+		Handler: adminRouter,
+	}
+
+	// Запускаем сервер в горутине
+	go func() {
+		log.Info("Starting Recipient Service",
+			zap.String("port", appConfig.Port),
+			zap.String("data_dir", appConfig.DataDir))
+
+		fmt.Printf("📇 Recipient Service running on port %s\n", appConfig.Port)
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start server", zap.Error(err))
+		}
+	}()
+
+	go func() {
+		log.Info("Starting Recipient Service admin API", zap.String("port", adminConfig.Port))
+
+		if err := adminSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatal("Failed to start admin server", zap.Error(err))
+		}
+	}()
+
+	// Ожидаем сигнал завершения
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+
+	log.Info("Shutting down Recipient Service...")
+
+	// Graceful shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := adminSrv.Shutdown(ctx); err != nil {
+		log.Error("Admin server forced to shutdown", zap.Error(err))
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Fatal("Server forced to shutdown", zap.Error(err))
+	}
+
+	log.Info("Recipient Service stopped")
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+
+	"go.uber.org/zap"
+)
+
+// HeaderAPIKey — HTTP заголовок с API ключом
+const HeaderAPIKey = "X-API-Key"
+
+// keyPrefix начинает каждый API ключ: nk_<id>_<secret>
+const keyPrefix = "nk_"
+
+var (
+	// ErrKeyNotFound возвращается, если API ключ не найден
+	ErrKeyNotFound = errors.New("api key not found")
+	// ErrInvalidKey возвращается, если API ключ не совпадает ни с одним сохраненным
+	ErrInvalidKey = errors.New("invalid api key")
+)
+
+// Key описывает API ключ клиента. Сам ключ не хранится, сохраняется только
+// его SHA-256, поэтому ключ показывается один раз при создании
+type Key struct {
+	ID        string `json:"id" example:"3f9a1c2e"`
+	Name      string `json:"name" example:"billing"`
+	Hash      string `json:"hash,omitempty"`
+	Scopes    Scopes `json:"scopes"`
+	CreatedAt int64  `json:"createdAt"`
+	UpdatedAt int64  `json:"updatedAt"`
+}
+
+// KeyRequest представляет запрос на создание или изменение API ключа
+type KeyRequest struct {
+	Name   string `json:"name" binding:"required" example:"billing"`
+	Scopes Scopes `json:"scopes"`
+}
+
+// CreatedKey представляет созданный ключ вместе с самим API ключом
+type CreatedKey struct {
+	Key
+	APIKey string `json:"apiKey" example:"nk_3f9a1c2e_5b0c..."`
+}
+
+// HashKey возвращает SHA-256 API ключа в том виде, в котором он хранится в файле ключей
+func HashKey(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// KeyStore хранит API ключи в памяти и сохраняет их на диск. Файл можно
+// заполнить вручную: ключ вида nk_<id>_<secret> описывается записью с id
+// и hash, равным HashKey ключа
+type KeyStore struct {
+	mu     sync.RWMutex
+	keys   map[string]*Key
+	file   *storage.JSONFile
+	logger *zap.Logger
+}
+
+// NewKeyStore создает новый экземпляр KeyStore и загружает сохраненные ключи
+func NewKeyStore(path string) (*KeyStore, error) {
+	s := &KeyStore{
+		keys:   make(map[string]*Key),
+		file:   storage.NewJSONFile(path),
+		logger: logger.GetLogger(),
+	}
+
+	var saved []*Key
+	if err := s.file.Load(&saved); err != nil {
+		return nil, err
+	}
+	for _, k := range saved {
+		if err := k.Scopes.Validate(); err != nil {
+			return nil, fmt.Errorf("api key %s: %w", k.ID, err)
+		}
+		s.keys[k.ID] = k
+	}
+
+	s.logger.Info("API keys loaded", zap.String("path", path), zap.Int("keys", len(s.keys)))
+	return s, nil
+}
+
+// List возвращает все ключи без хешей, отсортированные по идентификатору
+func (s *KeyStore) List() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := make([]*Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		result = append(result, public(k))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// Get возвращает ключ без хеша по идентификатору
+func (s *KeyStore) Get(id string) (*Key, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	k, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+	return public(k), nil
+}
+
+// Create создает новый ключ со случайным идентификатором и секретом
+func (s *KeyStore) Create(req *KeyRequest) (*CreatedKey, error) {
+	if err := req.Scopes.Validate(); err != nil {
+		return nil, err
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	apiKey := keyPrefix + id + "_" + secret
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.keys[id]; exists {
+		return nil, fmt.Errorf("api key id collision: %s", id)
+	}
+
+	now := shared.GetCurrentTimestamp()
+	k := &Key{
+		ID:        id,
+		Name:      req.Name,
+		Hash:      HashKey(apiKey),
+		Scopes:    req.Scopes,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	s.keys[id] = k
+
+	if err := s.persist(); err != nil {
+		delete(s.keys, id)
+		return nil, err
+	}
+
+	return &CreatedKey{Key: *public(k), APIKey: apiKey}, nil
+}
+
+// Update меняет имя и скоупы ключа. Сам ключ не меняется
+func (s *KeyStore) Update(id string, req *KeyRequest) (*Key, error) {
+	if err := req.Scopes.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.keys[id]
+	if !ok {
+		return nil, ErrKeyNotFound
+	}
+
+	updated := *existing
+	updated.Name = req.Name
+	updated.Scopes = req.Scopes
+	updated.UpdatedAt = shared.GetCurrentTimestamp()
+	s.keys[id] = &updated
+
+	if err := s.persist(); err != nil {
+		s.keys[id] = existing
+		return nil, err
+	}
+	return public(&updated), nil
+}
+
+// Delete отзывает ключ
+func (s *KeyStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.keys[id]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	delete(s.keys, id)
+
+	if err := s.persist(); err != nil {
+		s.keys[id] = existing
+		return err
+	}
+	return nil
+}
+
+// Authenticate проверяет API ключ из заголовка X-API-Key
+func (s *KeyStore) Authenticate(r *http.Request) (*Principal, error) {
+	apiKey := r.Header.Get(HeaderAPIKey)
+	if apiKey == "" {
+		return nil, ErrNoCredentials
+	}
+
+	id, _, ok := strings.Cut(strings.TrimPrefix(apiKey, keyPrefix), "_")
+	if !ok || !strings.HasPrefix(apiKey, keyPrefix) {
+		return nil, ErrInvalidKey
+	}
+
+	s.mu.RLock()
+	k, found := s.keys[id]
+	s.mu.RUnlock()
+	if !found || subtle.ConstantTimeCompare([]byte(HashKey(apiKey)), []byte(k.Hash)) != 1 {
+		return nil, ErrInvalidKey
+	}
+
+	return &Principal{Method: MethodAPIKey, ID: k.ID, Name: k.Name, Grants: []Scopes{k.Scopes}}, nil
+}
+
+// persist сохраняет ключи на диск. Вызывается под блокировкой
+func (s *KeyStore) persist() error {
+	keys := make([]*Key, 0, len(s.keys))
+	for _, k := range s.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return s.file.Save(keys)
+}
+
+// public возвращает копию ключа без хеша
+func public(k *Key) *Key {
+	copied := *k
+	copied.Hash = ""
+	return &copied
+}
+
+// randomHex возвращает n случайных байт в шестнадцатеричном виде
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Способы аутентификации клиента
+const (
+	MethodAPIKey = "api_key"
+	MethodJWT    = "jwt"
+)
+
+var (
+	// ErrNoCredentials возвращается аутентификатором, если запрос не содержит его учетных данных
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrForbidden возвращается, если запрос выходит за скоупы клиента
+	ErrForbidden = errors.New("forbidden")
+)
+
+// Principal описывает аутентифицированного клиента API
+type Principal struct {
+	// Method — способ аутентификации
+	Method string
+	// ID и Name — идентификатор и имя клиента, например API ключа
+	ID   string
+	Name string
+	// Grants — наборы скоупов клиента. Запрос разрешен, если его разрешает
+	// хотя бы один набор. Клиент без наборов не может отправлять сообщения
+	Grants []Scopes
+}
+
+// Authenticator проверяет учетные данные запроса. Если их нет,
+// возвращается ErrNoCredentials, и проверку выполняет следующий аутентификатор
+type Authenticator interface {
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// Subject возвращает ключ клиента, уникальный среди способов аутентификации:
+// "<method>:<id>". Для nil возвращает пустую строку
+func (p *Principal) Subject() string {
+	if p == nil {
+		return ""
+	}
+	return p.Method + ":" + p.ID
+}
+
+type principalKey struct{}
+
+// WithPrincipal возвращает контекст с аутентифицированным клиентом
+func WithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext возвращает клиента из контекста или nil, если аутентификация выключена
+func PrincipalFromContext(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Middleware требует аутентификации запроса одним из authenticators. Клиент
+// сохраняется в контексте запроса, а его идентичность добавляется ко всем
+// записям лога запроса, включая журнал HTTP запросов
+func Middleware(authenticators ...Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, a := range authenticators {
+			p, err := a.Authenticate(c.Request)
+			if errors.Is(err, ErrNoCredentials) {
+				continue
+			}
+			if err != nil {
+				logger.FromContext(c.Request.Context()).Warn("Authentication failed", zap.Error(err))
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid credentials"})
+				return
+			}
+
+			ctx := WithPrincipal(c.Request.Context(), p)
+			ctx = logger.WithFields(ctx,
+				zap.String("authMethod", p.Method),
+				zap.String("clientId", p.ID),
+				zap.String("clientName", p.Name))
+			c.Request = c.Request.WithContext(ctx)
+			c.Next()
+			return
+		}
+
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authentication required"})
+	}
+}
+
+// Authorize проверяет запрос на создание сообщения по скоупам клиента из
+// контекста. Без клиента в контексте, то есть без аутентификации, запрос разрешен
+func Authorize(ctx context.Context, req *shared.CreateMessageRequest) error {
+	p := PrincipalFromContext(ctx)
+	if p == nil {
+		return nil
+	}
+	if len(p.Grants) == 0 {
+		return fmt.Errorf("%w: client has no scopes", ErrForbidden)
+	}
+
+	var firstErr error
+	for i := range p.Grants {
+		err := p.Grants[i].Authorize(req)
+		if err == nil {
+			return nil
+		}
+		if !errors.Is(err, ErrForbidden) {
+			return err
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestKeyStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "api-keys.json")
+	store, err := NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("Failed to create key store: %v", err)
+	}
+
+	if _, err := store.Create(&KeyRequest{Name: "bad", Scopes: Scopes{Channels: []string{"pager"}}}); err == nil {
+		t.Error("Expected error for unknown channel scope")
+	}
+
+	created, err := store.Create(&KeyRequest{Name: "billing", Scopes: Scopes{Types: []string{shared.MessageTypeNotification}}})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+	if created.Hash != "" {
+		t.Error("Expected key hash to be hidden")
+	}
+
+	// Ключ проверяется по хешу, сохраненному на диск
+	reloaded, err := NewKeyStore(path)
+	if err != nil {
+		t.Fatalf("Failed to reload key store: %v", err)
+	}
+
+	authenticate := func(apiKey string) (*Principal, error) {
+		req := httptest.NewRequest(http.MethodPost, "/messages", nil)
+		if apiKey != "" {
+			req.Header.Set(HeaderAPIKey, apiKey)
+		}
+		return reloaded.Authenticate(req)
+	}
+
+	p, err := authenticate(created.APIKey)
+	if err != nil {
+		t.Fatalf("Expected key to authenticate, got %v", err)
+	}
+	if p.ID != created.ID || p.Name != "billing" || p.Method != MethodAPIKey {
+		t.Errorf("Unexpected principal: %+v", p)
+	}
+
+	if _, err := authenticate(""); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Expected ErrNoCredentials, got %v", err)
+	}
+	if _, err := authenticate(created.APIKey + "0"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for wrong secret, got %v", err)
+	}
+	if _, err := authenticate("plain-key"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey for malformed key, got %v", err)
+	}
+
+	if err := reloaded.Delete(created.ID); err != nil {
+		t.Fatalf("Failed to delete key: %v", err)
+	}
+	if _, err := authenticate(created.APIKey); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+}
+
+func TestScopes_Authorize(t *testing.T) {
+	scopes := Scopes{
+		Types:      []string{shared.MessageTypeNotification, shared.MessageTypeBroadcast},
+		Channels:   []string{shared.ChannelEmail, shared.ChannelSlack},
+		Recipients: []string{"user:billing-*", "audience:billing"},
+	}
+
+	tests := []struct {
+		name    string
+		req     shared.CreateMessageRequest
+		allowed bool
+	}{
+		{
+			name: "notification to allowed user",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"userId": "billing-1", "text": "hi"}},
+			allowed: true,
+		},
+		{
+			name: "notification to other user",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"userId": "ops-1", "text": "hi"}},
+		},
+		{
+			name: "chat id requires telegram",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"chatId": 123, "text": "hi"}},
+		},
+		{
+			name: "requested channel outside scope",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"userId": "billing-1", "text": "hi", "channels": []string{"webhook"}}},
+		},
+		{
+			name: "escalation with restricted channels",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+				Payload: map[string]interface{}{"userId": "billing-1", "text": "hi", "escalationPolicy": "oncall"}},
+		},
+		{
+			name: "broadcast to allowed audience",
+			req: shared.CreateMessageRequest{Type: shared.MessageTypeBroadcast,
+				Payload: map[string]interface{}{"audience": "billing", "text": "hi"}},
+			allowed: true,
+		},
+		{
+			name: "unknown message type",
+			req:  shared.CreateMessageRequest{Type: "custom", Payload: map[string]interface{}{}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := scopes.Authorize(&tt.req)
+			if tt.allowed && err != nil {
+				t.Errorf("Expected request to be allowed, got %v", err)
+			}
+			if !tt.allowed && !errors.Is(err, ErrForbidden) {
+				t.Errorf("Expected ErrForbidden, got %v", err)
+			}
+		})
+	}
+
+	// Разрешенное уведомление ограничивается каналами скоупа
+	req := shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+		Payload: map[string]interface{}{"userId": "billing-1", "text": "hi"}}
+	if err := scopes.Authorize(&req); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	notification, ok := req.Payload.(shared.NotificationMessage)
+	if !ok || len(notification.Channels) != 2 {
+		t.Errorf("Expected payload restricted to scope channels, got %+v", req.Payload)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	store, err := NewKeyStore(filepath.Join(t.TempDir(), "api-keys.json"))
+	if err != nil {
+		t.Fatalf("Failed to create key store: %v", err)
+	}
+	created, err := store.Create(&KeyRequest{Name: "billing"})
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	var seen *Principal
+	router := gin.New()
+	router.Use(Middleware(store))
+	router.POST("/messages", func(c *gin.Context) {
+		seen = PrincipalFromContext(c.Request.Context())
+		c.Status(http.StatusCreated)
+	})
+
+	tests := []struct {
+		name     string
+		apiKey   string
+		expected int
+	}{
+		{name: "missing key", expected: http.StatusUnauthorized},
+		{name: "invalid key", apiKey: "nk_00000000_secret", expected: http.StatusUnauthorized},
+		{name: "valid key", apiKey: created.APIKey, expected: http.StatusCreated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/messages", nil)
+			if tt.apiKey != "" {
+				req.Header.Set(HeaderAPIKey, tt.apiKey)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.expected {
+				t.Errorf("Expected status %d, got %d", tt.expected, w.Code)
+			}
+		})
+	}
+
+	if seen == nil || seen.ID != created.ID {
+		t.Errorf("Expected principal in request context, got %+v", seen)
+	}
+	if Authorize(context.Background(), &shared.CreateMessageRequest{Type: "custom"}) != nil {
+		t.Error("Expected requests without principal to be allowed")
+	}
+}
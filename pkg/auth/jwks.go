@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/go-jose/go-jose/v4"
+	"go.uber.org/zap"
+)
+
+// minKeyRefetch ограничивает перечитывание JWKS при токенах с неизвестным kid
+const minKeyRefetch = time.Minute
+
+// jwksFetchTimeout ограничивает загрузку JWKS, не связанную с контекстом запроса
+const jwksFetchTimeout = 10 * time.Second
+
+// ErrUnknownKey возвращается, если ключ подписи токена не найден в JWKS
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySource возвращает открытый ключ проверки подписи по kid токена
+type KeySource interface {
+	Key(ctx context.Context, kid string) (*jose.JSONWebKey, error)
+}
+
+// JWKS хранит набор открытых ключей из локального файла или по адресу identity
+// provider. Ключи по адресу перечитываются раз в refresh и при неизвестном kid,
+// чтобы подхватить ротацию ключей. При ошибке загрузки используются прежние ключи.
+//
+// Загрузка выполняется в фоне одна на все запросы и не зависит от их контекста:
+// пока она идет, запросы проверяются загруженными ранее ключами. Ждут загрузки
+// только запросы без подходящего ключа
+type JWKS struct {
+	url     string
+	refresh time.Duration
+	client  *http.Client
+	logger  *zap.Logger
+
+	mu        sync.Mutex
+	keys      jose.JSONWebKeySet
+	fetchedAt time.Time
+	// fetching закрывается по завершении текущей загрузки; nil, если загрузки нет
+	fetching chan struct{}
+}
+
+// LoadJWKSFile загружает набор ключей из локального файла
+func LoadJWKSFile(path string) (*JWKS, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+
+	s := &JWKS{logger: logger.GetLogger()}
+	if err := json.Unmarshal(data, &s.keys); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file %s: %w", path, err)
+	}
+	if len(s.keys.Keys) == 0 {
+		return nil, fmt.Errorf("JWKS file %s has no keys", path)
+	}
+	return s, nil
+}
+
+// NewJWKSFromURL создает набор ключей, загружаемый по адресу url. Ключи
+// загружаются при первом запросе
+func NewJWKSFromURL(url string, refresh time.Duration) *JWKS {
+	return &JWKS{
+		url:     url,
+		refresh: refresh,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		logger:  logger.GetLogger(),
+	}
+}
+
+// Key возвращает ключ с идентификатором kid. Токен без kid проверяется
+// единственным ключом набора
+func (s *JWKS) Key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	s.mu.Lock()
+	if s.url != "" && time.Since(s.fetchedAt) >= s.refresh {
+		s.startFetch()
+	}
+	if key := s.lookup(kid); key != nil {
+		s.mu.Unlock()
+		return key, nil
+	}
+
+	// Неизвестный kid может означать ротацию ключей у identity provider
+	if s.url == "" || (s.fetching == nil && time.Since(s.fetchedAt) < minKeyRefetch) {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+	}
+	done := s.startFetch()
+	s.mu.Unlock()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if key := s.lookup(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+}
+
+// lookup ищет ключ в загруженном наборе. Вызывается под блокировкой
+func (s *JWKS) lookup(kid string) *jose.JSONWebKey {
+	if kid == "" {
+		if len(s.keys.Keys) == 1 {
+			return &s.keys.Keys[0]
+		}
+		return nil
+	}
+	if keys := s.keys.Key(kid); len(keys) > 0 {
+		return &keys[0]
+	}
+	return nil
+}
+
+// startFetch запускает фоновую загрузку, если она еще не идет, и возвращает
+// канал ее завершения. Вызывается под блокировкой
+func (s *JWKS) startFetch() <-chan struct{} {
+	if s.fetching != nil {
+		return s.fetching
+	}
+	// Повторная попытка после ошибки тоже не чаще minKeyRefetch
+	s.fetchedAt = time.Now()
+	done := make(chan struct{})
+	s.fetching = done
+	go s.fetch(done)
+	return done
+}
+
+// fetch загружает набор ключей по адресу и закрывает done
+func (s *JWKS) fetch(done chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), jwksFetchTimeout)
+	defer cancel()
+	keys, err := s.download(ctx)
+
+	s.mu.Lock()
+	if err == nil {
+		s.keys = keys
+	}
+	s.fetching = nil
+	s.mu.Unlock()
+	close(done)
+
+	if err != nil {
+		s.logger.Error("Failed to fetch JWKS", zap.String("url", s.url), zap.Error(err))
+		return
+	}
+	s.logger.Info("JWKS loaded", zap.String("url", s.url), zap.Int("keys", len(keys.Keys)))
+}
+
+func (s *JWKS) download(ctx context.Context) (jose.JSONWebKeySet, error) {
+	var keys jose.JSONWebKeySet
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return keys, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return keys, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return keys, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return keys, err
+	}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return keys, err
+	}
+	if len(keys.Keys) == 0 {
+		return keys, errors.New("JWKS has no keys")
+	}
+	return keys, nil
+}
@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// ErrInvalidToken возвращается, если bearer токен не прошел проверку
+var ErrInvalidToken = errors.New("invalid token")
+
+// signatureAlgorithms — принимаемые алгоритмы подписи. Симметричные HS*
+// не принимаются: ключи проверки берутся из публичного JWKS
+var signatureAlgorithms = []jose.SignatureAlgorithm{
+	jose.RS256, jose.RS384, jose.RS512, jose.PS256,
+	jose.ES256, jose.ES384, jose.ES512, jose.EdDSA,
+}
+
+// ScopeMapping сопоставляет значения claim со скоупами токена наборам
+// скоупов producer API, например "notifications:billing" → {"recipients": ["user:billing-*"]}
+type ScopeMapping map[string]Scopes
+
+// LoadScopeMapping загружает соответствие скоупов из JSON файла
+func LoadScopeMapping(path string) (ScopeMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWT scopes file: %w", err)
+	}
+
+	var mapping ScopeMapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, fmt.Errorf("invalid JWT scopes file %s: %w", path, err)
+	}
+	for name, scopes := range mapping {
+		if err := scopes.Validate(); err != nil {
+			return nil, fmt.Errorf("JWT scope %q: %w", name, err)
+		}
+	}
+	return mapping, nil
+}
+
+// JWTAuthenticator проверяет bearer токены identity provider: подпись по JWKS,
+// iss, aud и срок действия. Скоупы клиента получаются из claim со скоупами
+// токена через ScopeMapping. Без соответствия токен не ограничен скоупами
+type JWTAuthenticator struct {
+	keys     KeySource
+	mapping  ScopeMapping
+	issuer   string
+	audience string
+	leeway   time.Duration
+	claim    string
+	now      func() time.Time
+}
+
+// NewJWTAuthenticator создает новый экземпляр JWTAuthenticator
+func NewJWTAuthenticator(cfg *config.AuthConfig, keys KeySource, mapping ScopeMapping) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		keys:     keys,
+		mapping:  mapping,
+		issuer:   cfg.Issuer,
+		audience: cfg.Audience,
+		leeway:   cfg.Leeway,
+		claim:    cfg.ScopeClaim,
+		now:      time.Now,
+	}
+}
+
+// tokenClaims — claims токена, кроме стандартных
+type tokenClaims struct {
+	AuthorizedParty string `json:"azp"`
+	ClientID        string `json:"client_id"`
+}
+
+// Authenticate проверяет токен из заголовка Authorization: Bearer
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return nil, ErrNoCredentials
+	}
+	scheme, raw, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return nil, ErrNoCredentials
+	}
+
+	tok, err := jwt.ParseSigned(strings.TrimSpace(raw), signatureAlgorithms)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	key, err := a.keys.Key(r.Context(), tok.Headers[0].KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	var claims jwt.Claims
+	var extra tokenClaims
+	var all map[string]interface{}
+	if err := tok.Claims(key, &claims, &extra, &all); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: exp claim is required", ErrInvalidToken)
+	}
+	expected := jwt.Expected{Issuer: a.issuer, Time: a.now()}
+	if a.audience != "" {
+		expected.AnyAudience = jwt.Audience{a.audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, a.leeway); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	name := extra.AuthorizedParty
+	if name == "" {
+		name = extra.ClientID
+	}
+	return &Principal{
+		Method: MethodJWT,
+		ID:     claims.Subject,
+		Name:   name,
+		Grants: a.grants(tokenScopes(all[a.claim])),
+	}, nil
+}
+
+// grants возвращает наборы скоупов, соответствующие скоупам токена
+func (a *JWTAuthenticator) grants(scopes []string) []Scopes {
+	if len(a.mapping) == 0 {
+		return []Scopes{{}}
+	}
+
+	var grants []Scopes
+	for _, scope := range scopes {
+		if s, ok := a.mapping[scope]; ok {
+			grants = append(grants, s)
+		}
+	}
+	return grants
+}
+
+// tokenScopes разбирает claim со скоупами: строку через пробел (OAuth 2.0) или массив строк
+func tokenScopes(value interface{}) []string {
+	var scopes []string
+	switch v := value.(type) {
+	case string:
+		scopes = strings.Fields(v)
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				scopes = append(scopes, s)
+			}
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// testKey генерирует ключ подписи ES256 с идентификатором kid
+func testKey(t *testing.T, kid string) jose.JSONWebKey {
+	t.Helper()
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	return jose.JSONWebKey{Key: private, KeyID: kid, Algorithm: string(jose.ES256), Use: "sig"}
+}
+
+// signToken подписывает токен с claims ключом key
+func signToken(t *testing.T, key jose.JSONWebKey, claims interface{}) string {
+	t.Helper()
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT"))
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).Serialize()
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return token
+}
+
+// writeJWKS сохраняет открытые части ключей в JWKS файл
+func writeJWKS(t *testing.T, keys ...jose.JSONWebKey) string {
+	t.Helper()
+	var set jose.JSONWebKeySet
+	for _, k := range keys {
+		set.Keys = append(set.Keys, k.Public())
+	}
+	data, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("Failed to marshal JWKS: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func bearerRequest(token string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/messages", nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	key := testKey(t, "key-1")
+	other := testKey(t, "key-2")
+
+	jwks, err := LoadJWKSFile(writeJWKS(t, key))
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+
+	cfg := &config.AuthConfig{
+		Issuer:     "https://idp.example.com",
+		Audience:   "producer-api",
+		Leeway:     time.Minute,
+		ScopeClaim: "scope",
+	}
+	mapping := ScopeMapping{
+		"notifications:billing": {Recipients: []string{"user:billing-*"}},
+	}
+	authenticator := NewJWTAuthenticator(cfg, jwks, mapping)
+
+	now := time.Now()
+	claims := func(modify func(c *jwt.Claims)) jwt.Claims {
+		c := jwt.Claims{
+			Issuer:   cfg.Issuer,
+			Subject:  "billing-service",
+			Audience: jwt.Audience{cfg.Audience},
+			IssuedAt: jwt.NewNumericDate(now),
+			Expiry:   jwt.NewNumericDate(now.Add(5 * time.Minute)),
+		}
+		if modify != nil {
+			modify(&c)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		err   error
+	}{
+		{name: "missing token", err: ErrNoCredentials},
+		{name: "wrong audience", token: signToken(t, key, claims(func(c *jwt.Claims) { c.Audience = jwt.Audience{"other"} })), err: ErrInvalidToken},
+		{name: "wrong issuer", token: signToken(t, key, claims(func(c *jwt.Claims) { c.Issuer = "https://evil.example.com" })), err: ErrInvalidToken},
+		{name: "expired", token: signToken(t, key, claims(func(c *jwt.Claims) { c.Expiry = jwt.NewNumericDate(now.Add(-time.Hour)) })), err: ErrInvalidToken},
+		{name: "no expiry", token: signToken(t, key, claims(func(c *jwt.Claims) { c.Expiry = nil })), err: ErrInvalidToken},
+		{name: "unknown key", token: signToken(t, other, claims(nil)), err: ErrInvalidToken},
+		{name: "malformed token", token: "not-a-token", err: ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := authenticator.Authenticate(bearerRequest(tt.token)); !errors.Is(err, tt.err) {
+				t.Errorf("Expected %v, got %v", tt.err, err)
+			}
+		})
+	}
+
+	// Скоупы токена сопоставляются скоупам producer API
+	scoped := struct {
+		jwt.Claims
+		Scope    string `json:"scope"`
+		ClientID string `json:"client_id"`
+	}{Claims: claims(nil), Scope: "openid notifications:billing", ClientID: "billing"}
+
+	p, err := authenticator.Authenticate(bearerRequest(signToken(t, key, scoped)))
+	if err != nil {
+		t.Fatalf("Expected token to authenticate, got %v", err)
+	}
+	if p.Method != MethodJWT || p.ID != "billing-service" || p.Name != "billing" || len(p.Grants) != 1 {
+		t.Fatalf("Unexpected principal: %+v", p)
+	}
+
+	ctx := WithPrincipal(context.Background(), p)
+	allowed := &shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+		Payload: map[string]interface{}{"userId": "billing-1", "text": "hi"}}
+	if err := Authorize(ctx, allowed); err != nil {
+		t.Errorf("Expected request to be allowed, got %v", err)
+	}
+	denied := &shared.CreateMessageRequest{Type: shared.MessageTypeNotification,
+		Payload: map[string]interface{}{"userId": "ops-1", "text": "hi"}}
+	if err := Authorize(ctx, denied); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	// Токен без известных скоупов не может отправлять сообщения
+	p, err = authenticator.Authenticate(bearerRequest(signToken(t, key, claims(nil))))
+	if err != nil {
+		t.Fatalf("Expected token to authenticate, got %v", err)
+	}
+	if err := Authorize(WithPrincipal(context.Background(), p), allowed); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden without scopes, got %v", err)
+	}
+}
+
+func TestJWKS_URL(t *testing.T) {
+	first := testKey(t, "key-1")
+	second := testKey(t, "key-2")
+
+	current := []jose.JSONWebKey{first.Public()}
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: current})
+	}))
+	defer server.Close()
+
+	jwks := NewJWKSFromURL(server.URL, time.Hour)
+	ctx := context.Background()
+
+	if _, err := jwks.Key(ctx, "key-1"); err != nil {
+		t.Fatalf("Expected key to be fetched, got %v", err)
+	}
+	if _, err := jwks.Key(ctx, ""); err != nil {
+		t.Errorf("Expected single key for token without kid, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected keys to be cached, got %d requests", requests)
+	}
+
+	// После ротации неизвестный kid перечитывает JWKS не чаще minKeyRefetch
+	current = []jose.JSONWebKey{first.Public(), second.Public()}
+	if _, err := jwks.Key(ctx, "key-2"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Expected ErrUnknownKey right after fetch, got %v", err)
+	}
+
+	jwks.fetchedAt = time.Now().Add(-minKeyRefetch)
+	if _, err := jwks.Key(ctx, "key-2"); err != nil {
+		t.Errorf("Expected rotated key to be fetched, got %v", err)
+	}
+
+	// При недоступном JWKS используются прежние ключи
+	server.Close()
+	jwks.fetchedAt = time.Time{}
+	if _, err := jwks.Key(ctx, "key-1"); err != nil {
+		t.Errorf("Expected cached key after failed refresh, got %v", err)
+	}
+}
+
+func TestJWKS_RefreshDoesNotBlockRequests(t *testing.T) {
+	first := testKey(t, "key-1")
+	second := testKey(t, "key-2")
+
+	release := make(chan struct{})
+	var blocked atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if blocked.Load() {
+			<-release
+		}
+		_ = json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{first.Public(), second.Public()}})
+	}))
+	defer server.Close()
+	defer close(release)
+
+	jwks := NewJWKSFromURL(server.URL, time.Hour)
+	if _, err := jwks.Key(context.Background(), "key-1"); err != nil {
+		t.Fatalf("Expected key to be fetched, got %v", err)
+	}
+
+	// Пока идет обновление, запросы проверяются загруженными ключами
+	blocked.Store(true)
+	jwks.fetchedAt = time.Time{}
+	start := time.Now()
+	if _, err := jwks.Key(context.Background(), "key-2"); err != nil {
+		t.Fatalf("Expected cached key during refresh, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected cached key without waiting for refresh, took %v", elapsed)
+	}
+
+	// Запрос с неизвестным kid ждет обновления не дольше своего контекста,
+	// а отмена запроса не прерывает обновление
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := jwks.Key(ctx, "key-3"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected request deadline while refresh is running, got %v", err)
+	}
+	jwks.mu.Lock()
+	fetching := jwks.fetching != nil
+	jwks.mu.Unlock()
+	if !fetching {
+		t.Error("Expected refresh to continue after request was canceled")
+	}
+}
@@ -0,0 +1,160 @@
+package auth
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// Scopes ограничивает, какие сообщения может отправлять клиент. Пустой
+// список не ограничивает соответствующее измерение
+type Scopes struct {
+	// Types — разрешенные типы сообщений: notification, broadcast
+	Types []string `json:"types,omitempty" example:"notification"`
+	// Channels — разрешенные каналы доставки. Уведомления клиента доставляются
+	// только через эти каналы
+	Channels []string `json:"channels,omitempty" example:"email,slack"`
+	// Recipients — шаблоны адресатов в синтаксисе path.Match: user:<userId>,
+	// chat:<chatId> или audience:<name>, например user:billing-*
+	Recipients []string `json:"recipients,omitempty" example:"user:billing-*,audience:billing"`
+}
+
+// Validate проверяет, что скоупы содержат известные типы и каналы и корректные шаблоны
+func (s *Scopes) Validate() error {
+	for _, t := range s.Types {
+		if t != shared.MessageTypeNotification && t != shared.MessageTypeBroadcast {
+			return fmt.Errorf("unknown message type %q", t)
+		}
+	}
+	for _, channel := range s.Channels {
+		if !shared.IsValidChannel(channel) {
+			return fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+	for _, pattern := range s.Recipients {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid recipient pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// Authorize проверяет, что запрос не выходит за скоупы. Ограничение каналов
+// записывается в payload уведомления или рассылки, чтобы Notification Service
+// не доставил сообщение через другой канал. Запрос должен быть провалидирован
+func (s *Scopes) Authorize(req *shared.CreateMessageRequest) error {
+	if len(s.Types) > 0 && !contains(s.Types, req.Type) {
+		return fmt.Errorf("%w: message type %q is not allowed", ErrForbidden, req.Type)
+	}
+
+	switch req.Type {
+	case shared.MessageTypeNotification:
+		return s.authorizeNotification(req)
+	case shared.MessageTypeBroadcast:
+		return s.authorizeBroadcast(req)
+	}
+
+	// Адресат и каналы сообщений других типов неизвестны
+	if len(s.Recipients) > 0 || len(s.Channels) > 0 {
+		return fmt.Errorf("%w: message type %q is not allowed", ErrForbidden, req.Type)
+	}
+	return nil
+}
+
+func (s *Scopes) authorizeNotification(req *shared.CreateMessageRequest) error {
+	var notification shared.NotificationMessage
+	if err := decodePayload(req.Payload, &notification); err != nil {
+		return err
+	}
+
+	if err := s.authorizeRecipient(notification.Recipient()); err != nil {
+		return err
+	}
+
+	if len(s.Channels) == 0 {
+		return nil
+	}
+	if notification.UserID == "" && !contains(s.Channels, shared.ChannelTelegram) {
+		return fmt.Errorf("%w: channel %q is not allowed", ErrForbidden, shared.ChannelTelegram)
+	}
+	// Каналы шагов эскалации задает политика, поэтому ограничить их нельзя
+	if notification.EscalationPolicy != "" {
+		return fmt.Errorf("%w: escalation policies require unrestricted channels", ErrForbidden)
+	}
+	channels, err := s.restrictChannels(notification.Channels)
+	if err != nil {
+		return err
+	}
+	notification.Channels = channels
+	req.Payload = notification
+	return nil
+}
+
+func (s *Scopes) authorizeBroadcast(req *shared.CreateMessageRequest) error {
+	var broadcast shared.BroadcastMessage
+	if err := decodePayload(req.Payload, &broadcast); err != nil {
+		return err
+	}
+
+	if err := s.authorizeRecipient(broadcast.Recipient()); err != nil {
+		return err
+	}
+
+	if len(s.Channels) == 0 {
+		return nil
+	}
+	channels, err := s.restrictChannels(broadcast.Channels)
+	if err != nil {
+		return err
+	}
+	broadcast.Channels = channels
+	req.Payload = broadcast
+	return nil
+}
+
+// authorizeRecipient проверяет адресата по шаблонам Recipients
+func (s *Scopes) authorizeRecipient(recipient string) error {
+	if len(s.Recipients) == 0 {
+		return nil
+	}
+	for _, pattern := range s.Recipients {
+		if ok, _ := path.Match(pattern, recipient); ok {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: recipient %q is not allowed", ErrForbidden, recipient)
+}
+
+// restrictChannels возвращает ограничение каналов сообщения: запрошенные
+// каналы должны входить в скоуп, без запрошенных действует весь скоуп
+func (s *Scopes) restrictChannels(requested []string) ([]string, error) {
+	if len(requested) == 0 {
+		return append([]string(nil), s.Channels...), nil
+	}
+	for _, channel := range requested {
+		if !contains(s.Channels, channel) {
+			return nil, fmt.Errorf("%w: channel %q is not allowed", ErrForbidden, channel)
+		}
+	}
+	return requested, nil
+}
+
+// decodePayload преобразует payload запроса в структуру v
+func decodePayload(payload interface{}, v interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
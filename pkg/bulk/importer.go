@@ -0,0 +1,197 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+	"kafka-notification-system/pkg/storage"
+)
+
+// Статусы строк в отчете импорта
+const (
+	StatusSent    = "sent"
+	StatusInvalid = "invalid"
+	StatusFailed  = "failed"
+)
+
+// MaxLineBytes ограничивает длину одной строки файла импорта
+const MaxLineBytes = 1 << 20
+
+// ReportLine представляет результат обработки одной строки файла импорта.
+// Hash — SHA-256 содержимого строки: строка пропускается при повторном запуске,
+// только если она уже отправлена и не изменилась
+type ReportLine struct {
+	Line      int    `json:"line"`
+	Hash      string `json:"hash"`
+	Status    string `json:"status"`
+	ID        string `json:"id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// Summary содержит итоги запуска импорта
+type Summary struct {
+	Lines   int `json:"lines"`
+	Sent    int `json:"sent"`
+	Skipped int `json:"skipped"`
+	Invalid int `json:"invalid"`
+	Failed  int `json:"failed"`
+}
+
+// ImportResponse представляет ответ Producer Service на загрузку файла импорта
+type ImportResponse struct {
+	ImportID string   `json:"importId"`
+	Summary  *Summary `json:"summary,omitempty"`
+	Report   string   `json:"report"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// PublishFunc публикует одно сообщение и возвращает его идентификатор
+type PublishFunc func(ctx context.Context, req *shared.CreateMessageRequest) (string, error)
+
+// Importer отправляет сообщения из JSONL потока, по одному запросу
+// CreateMessageRequest на строку. Результат каждой строки дописывается в отчет,
+// по которому повторный запуск пропускает уже отправленные строки
+type Importer struct {
+	report *storage.JSONLines
+	rate   float64
+}
+
+// NewImporter создает новый экземпляр Importer. rate ограничивает число
+// публикуемых сообщений в секунду, ноль снимает ограничение
+func NewImporter(reportPath string, rate float64) *Importer {
+	return &Importer{
+		report: storage.NewJSONLines(reportPath),
+		rate:   rate,
+	}
+}
+
+// Run читает строки из r и публикует корректные запросы через publish.
+// Некорректные строки и ошибки публикации не прерывают импорт и попадают в отчет.
+// Пустые строки пропускаются. Отправленная строка записывается в отчет после
+// публикации, поэтому при сбое между ними строка может быть отправлена повторно
+func (im *Importer) Run(ctx context.Context, r io.Reader, publish PublishFunc) (*Summary, error) {
+	sent, err := im.sentLines()
+	if err != nil {
+		return nil, err
+	}
+
+	var throttle <-chan time.Time
+	if im.rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / im.rate))
+		defer ticker.Stop()
+		throttle = ticker.C
+	}
+
+	summary := &Summary{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), MaxLineBytes)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+		summary.Lines++
+
+		hash := lineHash(data)
+		if sent[line] == hash {
+			summary.Skipped++
+			continue
+		}
+
+		result := ReportLine{Line: line, Hash: hash}
+		req, err := parseRequest(data)
+		if err != nil {
+			result.Status = StatusInvalid
+			result.Error = err.Error()
+			summary.Invalid++
+		} else {
+			if throttle != nil {
+				select {
+				case <-ctx.Done():
+					return summary, ctx.Err()
+				case <-throttle:
+				}
+			}
+
+			id, err := publish(ctx, req)
+			if err != nil {
+				if ctx.Err() != nil {
+					return summary, ctx.Err()
+				}
+				result.Status = StatusFailed
+				result.Error = err.Error()
+				summary.Failed++
+			} else {
+				result.Status = StatusSent
+				result.ID = id
+				summary.Sent++
+			}
+		}
+
+		result.Timestamp = shared.GetCurrentTimestamp()
+		if err := im.report.Append(result); err != nil {
+			return summary, fmt.Errorf("failed to write import report: %w", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		if errors.Is(err, bufio.ErrTooLong) {
+			return summary, fmt.Errorf("line %d exceeds %d bytes", line+1, MaxLineBytes)
+		}
+		return summary, fmt.Errorf("failed to read import: %w", err)
+	}
+	return summary, nil
+}
+
+// sentLines возвращает хеши строк, уже отправленных по данным отчета
+func (im *Importer) sentLines() (map[int]string, error) {
+	sent := make(map[int]string)
+	err := im.report.ReadAll(func(data []byte) error {
+		var line ReportLine
+		if err := json.Unmarshal(data, &line); err != nil {
+			return err
+		}
+		if line.Status == StatusSent {
+			sent[line.Line] = line.Hash
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read import report: %w", err)
+	}
+	return sent, nil
+}
+
+// parseRequest разбирает и проверяет запрос из строки импорта
+func parseRequest(data []byte) (*shared.CreateMessageRequest, error) {
+	var req shared.CreateMessageRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if req.Type == "" || req.Payload == nil {
+		return nil, errors.New("type and payload are required")
+	}
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// lineHash возвращает SHA-256 содержимого строки
+func lineHash(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/shared"
+)
+
+// recordingPublisher запоминает опубликованные сообщения и отклоняет тексты из fail
+type recordingPublisher struct {
+	texts []string
+	fail  map[string]bool
+}
+
+func (p *recordingPublisher) publish(_ context.Context, req *shared.CreateMessageRequest) (string, error) {
+	payload, _ := req.Payload.(map[string]interface{})
+	text, _ := payload["text"].(string)
+	if p.fail[text] {
+		return "", errors.New("kafka unavailable")
+	}
+	p.texts = append(p.texts, text)
+	return fmt.Sprintf("id-%s", text), nil
+}
+
+func readReport(t *testing.T, path string) []ReportLine {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read report: %v", err)
+	}
+
+	var lines []ReportLine
+	for _, raw := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		var line ReportLine
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("Failed to decode report line: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+const testImport = `{"type":"notification","payload":{"chatId":1,"text":"one"}}
+
+{"type":"notification","payload":{"text":"no recipient"}}
+not json
+{"type":"notification","payload":{"chatId":2,"text":"two"}}
+`
+
+func TestImporter_RunWritesReport(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.jsonl")
+	publisher := &recordingPublisher{fail: map[string]bool{"two": true}}
+
+	summary, err := NewImporter(reportPath, 0).Run(context.Background(), strings.NewReader(testImport), publisher.publish)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expected := Summary{Lines: 4, Sent: 1, Invalid: 2, Failed: 1}
+	if *summary != expected {
+		t.Errorf("Expected summary %+v, got %+v", expected, *summary)
+	}
+
+	report := readReport(t, reportPath)
+	statuses := []struct {
+		line   int
+		status string
+	}{{1, StatusSent}, {3, StatusInvalid}, {4, StatusInvalid}, {5, StatusFailed}}
+	if len(report) != len(statuses) {
+		t.Fatalf("Expected %d report lines, got %d", len(statuses), len(report))
+	}
+	for i, s := range statuses {
+		if report[i].Line != s.line || report[i].Status != s.status {
+			t.Errorf("Expected line %d %s, got %+v", s.line, s.status, report[i])
+		}
+	}
+	if report[0].ID != "id-one" {
+		t.Errorf("Expected message id in report, got %q", report[0].ID)
+	}
+	if report[3].Error != "kafka unavailable" {
+		t.Errorf("Expected publish error in report, got %q", report[3].Error)
+	}
+}
+
+func TestImporter_ResumeSkipsSentLines(t *testing.T) {
+	reportPath := filepath.Join(t.TempDir(), "report.jsonl")
+
+	first := &recordingPublisher{fail: map[string]bool{"two": true}}
+	if _, err := NewImporter(reportPath, 0).Run(context.Background(), strings.NewReader(testImport), first.publish); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	second := &recordingPublisher{}
+	summary, err := NewImporter(reportPath, 0).Run(context.Background(), strings.NewReader(testImport), second.publish)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(second.texts) != 1 || second.texts[0] != "two" {
+		t.Errorf("Expected only the failed line to be sent again, got %v", second.texts)
+	}
+	if summary.Skipped != 1 || summary.Sent != 1 {
+		t.Errorf("Unexpected summary: %+v", *summary)
+	}
+
+	// Измененная строка с тем же номером отправляется заново
+	changed := strings.Replace(testImport, `"text":"one"`, `"text":"one again"`, 1)
+	third := &recordingPublisher{}
+	if _, err := NewImporter(reportPath, 0).Run(context.Background(), strings.NewReader(changed), third.publish); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(third.texts) != 1 || third.texts[0] != "one again" {
+		t.Errorf("Expected changed line to be sent, got %v", third.texts)
+	}
+}
+
+func TestImporter_RunThrottles(t *testing.T) {
+	input := strings.Repeat(`{"type":"notification","payload":{"chatId":1,"text":"hi"}}`+"\n", 3)
+	publisher := &recordingPublisher{}
+
+	start := time.Now()
+	_, err := NewImporter(filepath.Join(t.TempDir(), "report.jsonl"), 50).
+		Run(context.Background(), strings.NewReader(input), publisher.publish)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("Expected 3 messages at 50/s to take at least 60ms, took %s", elapsed)
+	}
+}
+
+func TestImporter_RunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := NewImporter(filepath.Join(t.TempDir(), "report.jsonl"), 1).
+		Run(ctx, strings.NewReader(testImport), (&recordingPublisher{}).publish)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+}
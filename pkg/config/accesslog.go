@@ -0,0 +1,36 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// AccessLogConfig содержит настройки журнала HTTP запросов
+type AccessLogConfig struct {
+	// SkipPaths — пути, успешные запросы к которым не пишутся в журнал (пробы, метрики)
+	SkipPaths []string `mapstructure:"access_log_skip_paths"`
+	// SampleRate — доля успешных запросов, попадающих в журнал, от 0 до 1.
+	// Запросы с ответом 4xx и 5xx пишутся всегда
+	SampleRate float64 `mapstructure:"access_log_sample_rate"`
+}
+
+// LoadAccessLogConfig загружает конфигурацию журнала HTTP запросов
+func LoadAccessLogConfig() *AccessLogConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("access_log_skip_paths", "/health,/livez,/readyz,/metrics")
+	viper.SetDefault("access_log_sample_rate", 1.0)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	cfg := &AccessLogConfig{
+		SkipPaths:  splitList(viper.GetString("access_log_skip_paths")),
+		SampleRate: viper.GetFloat64("access_log_sample_rate"),
+	}
+	if cfg.SampleRate < 0 {
+		cfg.SampleRate = 0
+	}
+	if cfg.SampleRate > 1 {
+		cfg.SampleRate = 1
+	}
+	return cfg
+}
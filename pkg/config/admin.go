@@ -0,0 +1,37 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// AdminConfig содержит настройки административного API сервиса
+type AdminConfig struct {
+	Port       string `mapstructure:"admin_port"`
+	MaxReplays int    `mapstructure:"dlq_max_replays"`
+	// DeadLetterMaxEntries — сколько последних сообщений каждой партиции dead letter topic держать в памяти
+	DeadLetterMaxEntries int `mapstructure:"dlq_index_max_entries"`
+	// DeadLetterMaxAge — сообщения dead letter topic старше этого возраста не загружаются в память
+	DeadLetterMaxAge time.Duration `mapstructure:"dlq_index_max_age"`
+}
+
+// LoadAdminConfig загружает конфигурацию административного API.
+// Порт по умолчанию задает каждый сервис
+func LoadAdminConfig() *AdminConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("dlq_max_replays", 3)
+	viper.SetDefault("dlq_index_max_entries", 10000)
+	viper.SetDefault("dlq_index_max_age", "168h")
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &AdminConfig{
+		Port:       viper.GetString("admin_port"),
+		MaxReplays: viper.GetInt("dlq_max_replays"),
+
+		DeadLetterMaxEntries: viper.GetInt("dlq_index_max_entries"),
+		DeadLetterMaxAge:     viper.GetDuration("dlq_index_max_age"),
+	}
+}
@@ -0,0 +1,69 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// AuthConfig содержит настройки аутентификации producer API. API ключи и
+// JWT включаются независимо: при обоих включенных принимается любой способ
+type AuthConfig struct {
+	// APIKeysEnabled требует API ключ в заголовке X-API-Key. По умолчанию
+	// включено в production
+	APIKeysEnabled bool `mapstructure:"api_keys_enabled"`
+	// APIKeysFile — файл с хешами API ключей и их скоупами.
+	// По умолчанию api-keys.json в каталоге данных сервиса
+	APIKeysFile string `mapstructure:"api_keys_file"`
+
+	// JWTEnabled принимает bearer токены identity provider в заголовке Authorization
+	JWTEnabled bool `mapstructure:"jwt_enabled"`
+	// JWKSFile и JWKSURL — источник открытых ключей подписи токенов: локальный
+	// файл или адрес JWKS identity provider. Задается один из них
+	JWKSFile string `mapstructure:"jwt_jwks_file"`
+	JWKSURL  string `mapstructure:"jwt_jwks_url"`
+	// JWKSRefresh — как часто перечитывать JWKS по адресу
+	JWKSRefresh time.Duration `mapstructure:"jwt_jwks_refresh"`
+	// Issuer и Audience — ожидаемые значения claims iss и aud
+	Issuer   string `mapstructure:"jwt_issuer"`
+	Audience string `mapstructure:"jwt_audience"`
+	// Leeway — допустимое расхождение часов при проверке exp и nbf
+	Leeway time.Duration `mapstructure:"jwt_leeway"`
+	// ScopeClaim — claim со скоупами токена: строка через пробел или массив
+	ScopeClaim string `mapstructure:"jwt_scope_claim"`
+	// ScopesFile — JSON файл соответствия значений ScopeClaim скоупам producer API
+	ScopesFile string `mapstructure:"jwt_scopes_file"`
+}
+
+// LoadAuthConfig загружает конфигурацию аутентификации для окружения environment
+func LoadAuthConfig(environment string) *AuthConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("api_keys_enabled", environment == "production")
+	viper.SetDefault("api_keys_file", "")
+	viper.SetDefault("jwt_enabled", false)
+	viper.SetDefault("jwt_jwks_file", "")
+	viper.SetDefault("jwt_jwks_url", "")
+	viper.SetDefault("jwt_jwks_refresh", 10*time.Minute)
+	viper.SetDefault("jwt_issuer", "")
+	viper.SetDefault("jwt_audience", "")
+	viper.SetDefault("jwt_leeway", time.Minute)
+	viper.SetDefault("jwt_scope_claim", "scope")
+	viper.SetDefault("jwt_scopes_file", "")
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &AuthConfig{
+		APIKeysEnabled: viper.GetBool("api_keys_enabled"),
+		APIKeysFile:    viper.GetString("api_keys_file"),
+		JWTEnabled:     viper.GetBool("jwt_enabled"),
+		JWKSFile:       viper.GetString("jwt_jwks_file"),
+		JWKSURL:        viper.GetString("jwt_jwks_url"),
+		JWKSRefresh:    viper.GetDuration("jwt_jwks_refresh"),
+		Issuer:         viper.GetString("jwt_issuer"),
+		Audience:       viper.GetString("jwt_audience"),
+		Leeway:         viper.GetDuration("jwt_leeway"),
+		ScopeClaim:     viper.GetString("jwt_scope_claim"),
+		ScopesFile:     viper.GetString("jwt_scopes_file"),
+	}
+}
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// BreakerConfig содержит настройки circuit breaker каналов доставки
+type BreakerConfig struct {
+	// FailureThreshold — число ошибок подряд, после которого канал отключается
+	FailureThreshold int `mapstructure:"breaker_failure_threshold"`
+	// OpenTimeout — время, на которое канал отключается перед пробной отправкой
+	OpenTimeout time.Duration `mapstructure:"breaker_open_timeout"`
+	// HalfOpenRequests — число успешных пробных отправок для включения канала
+	HalfOpenRequests int `mapstructure:"breaker_half_open_requests"`
+}
+
+// LoadBreakerConfig загружает конфигурацию circuit breaker
+func LoadBreakerConfig() *BreakerConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("breaker_failure_threshold", 5)
+	viper.SetDefault("breaker_open_timeout", 30*time.Second)
+	viper.SetDefault("breaker_half_open_requests", 1)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	cfg := &BreakerConfig{
+		FailureThreshold: viper.GetInt("breaker_failure_threshold"),
+		OpenTimeout:      viper.GetDuration("breaker_open_timeout"),
+		HalfOpenRequests: viper.GetInt("breaker_half_open_requests"),
+	}
+	if cfg.FailureThreshold < 1 {
+		cfg.FailureThreshold = 1
+	}
+	if cfg.HalfOpenRequests < 1 {
+		cfg.HalfOpenRequests = 1
+	}
+	return cfg
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// BulkConfig содержит настройки массового импорта сообщений
+type BulkConfig struct {
+	Rate float64 `mapstructure:"bulk_rate"`
+}
+
+// LoadBulkConfig загружает конфигурацию массового импорта
+func LoadBulkConfig() *BulkConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("bulk_rate", 50)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &BulkConfig{
+		Rate: viper.GetFloat64("bulk_rate"),
+	}
+}
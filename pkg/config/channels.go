@@ -0,0 +1,38 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// ChannelsConfig содержит настройки каналов доставки помимо Telegram
+type ChannelsConfig struct {
+	SMTPHost       string        `mapstructure:"smtp_host"`
+	SMTPPort       int           `mapstructure:"smtp_port"`
+	SMTPUsername   string        `mapstructure:"smtp_username"`
+	SMTPPassword   string        `mapstructure:"smtp_password"`
+	SMTPFrom       string        `mapstructure:"smtp_from"`
+	SlackBotToken  string        `mapstructure:"slack_bot_token"`
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"`
+}
+
+// LoadChannelsConfig загружает конфигурацию каналов доставки
+func LoadChannelsConfig() *ChannelsConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("smtp_port", 587)
+	viper.SetDefault("webhook_timeout", 10*time.Second)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &ChannelsConfig{
+		SMTPHost:       viper.GetString("smtp_host"),
+		SMTPPort:       viper.GetInt("smtp_port"),
+		SMTPUsername:   viper.GetString("smtp_username"),
+		SMTPPassword:   viper.GetString("smtp_password"),
+		SMTPFrom:       viper.GetString("smtp_from"),
+		SlackBotToken:  viper.GetString("slack_bot_token"),
+		WebhookTimeout: viper.GetDuration("webhook_timeout"),
+	}
+}
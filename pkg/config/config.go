@@ -8,9 +8,11 @@ import (
 
 // AppConfig содержит общую конфигурацию приложения
 type AppConfig struct {
-	Port             string `mapstructure:"port"`
-	TelegramBotToken string `mapstructure:"telegram_bot_token"`
-	Environment      string `mapstructure:"environment"`
+	Port                string `mapstructure:"port"`
+	TelegramBotToken    string `mapstructure:"telegram_bot_token"`
+	Environment         string `mapstructure:"environment"`
+	DataDir             string `mapstructure:"data_dir"`
+	RecipientServiceURL string `mapstructure:"recipient_service_url"`
 }
 
 // LoadAppConfig загружает конфигурацию приложения
@@ -22,6 +24,8 @@ func LoadAppConfig() *AppConfig {
 	// Устанавливаем значения по умолчанию
 	viper.SetDefault("port", "3000")
 	viper.SetDefault("environment", "development")
+	viper.SetDefault("data_dir", "data")
+	viper.SetDefault("recipient_service_url", "http://localhost:3003")
 
 	// Пытаемся прочитать .env файл (не критично если его нет)
 	if err := viper.ReadInConfig(); err != nil {
@@ -29,9 +33,11 @@ func LoadAppConfig() *AppConfig {
 	}
 
 	config := &AppConfig{
-		Port:             viper.GetString("port"),
-		TelegramBotToken: viper.GetString("telegram_bot_token"),
-		Environment:      viper.GetString("environment"),
+		Port:                viper.GetString("port"),
+		TelegramBotToken:    viper.GetString("telegram_bot_token"),
+		Environment:         viper.GetString("environment"),
+		DataDir:             viper.GetString("data_dir"),
+		RecipientServiceURL: viper.GetString("recipient_service_url"),
 	}
 
 	return config
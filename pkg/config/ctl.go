@@ -0,0 +1,41 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// CtlConfig содержит адреса сервисов для утилиты notifyctl
+type CtlConfig struct {
+	ProducerURL          string `mapstructure:"producer_url"`
+	ConsumerURL          string `mapstructure:"consumer_url"`
+	NotificationURL      string `mapstructure:"notification_url"`
+	NotificationAdminURL string `mapstructure:"notification_admin_url"`
+	RecipientServiceURL  string `mapstructure:"recipient_service_url"`
+	FanoutURL            string `mapstructure:"fanout_url"`
+	// ProducerAPIKey — API ключ для отправки сообщений через Producer Service
+	ProducerAPIKey string `mapstructure:"producer_api_key"`
+}
+
+// LoadCtlConfig загружает конфигурацию notifyctl
+func LoadCtlConfig() *CtlConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("producer_url", "http://localhost:3000")
+	viper.SetDefault("consumer_url", "http://localhost:3001")
+	viper.SetDefault("notification_url", "http://localhost:3002")
+	viper.SetDefault("notification_admin_url", "http://localhost:3102")
+	viper.SetDefault("recipient_service_url", "http://localhost:3003")
+	viper.SetDefault("fanout_url", "http://localhost:3004")
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &CtlConfig{
+		ProducerURL:          viper.GetString("producer_url"),
+		ProducerAPIKey:       viper.GetString("producer_api_key"),
+		ConsumerURL:          viper.GetString("consumer_url"),
+		NotificationURL:      viper.GetString("notification_url"),
+		NotificationAdminURL: viper.GetString("notification_admin_url"),
+		RecipientServiceURL:  viper.GetString("recipient_service_url"),
+		FanoutURL:            viper.GetString("fanout_url"),
+	}
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// DeliveryConfig содержит настройки правил доставки в Notification Service
+type DeliveryConfig struct {
+	CriticalCategories []string      `mapstructure:"critical_categories"`
+	SchedulerInterval  time.Duration `mapstructure:"scheduler_interval"`
+	DigestWindow       time.Duration `mapstructure:"digest_window"`
+	DigestMaxCount     int           `mapstructure:"digest_max_count"`
+	PriorityMode       string        `mapstructure:"priority_mode"`
+	PriorityWeights    []int         `mapstructure:"priority_weights"`
+	Workers            int           `mapstructure:"delivery_workers"`
+	// DedupeWindow и DedupeMaxEntries — сколько и как долго помнить обработанные
+	// уведомления, чтобы не доставлять повторно опубликованные
+	DedupeWindow     time.Duration `mapstructure:"dedupe_window"`
+	DedupeMaxEntries int           `mapstructure:"dedupe_max_entries"`
+}
+
+// Режимы выбора очереди приоритетов
+const (
+	PriorityModeStrict   = "strict"
+	PriorityModeWeighted = "weighted"
+)
+
+// defaultPriorityWeights задает доли high, normal и low в режиме weighted
+var defaultPriorityWeights = []int{6, 3, 1}
+
+// LoadDeliveryConfig загружает конфигурацию правил доставки
+func LoadDeliveryConfig() *DeliveryConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("critical_categories", "security")
+	viper.SetDefault("scheduler_interval", 15*time.Second)
+	viper.SetDefault("digest_window", 5*time.Minute)
+	viper.SetDefault("digest_max_count", 20)
+	viper.SetDefault("priority_mode", PriorityModeWeighted)
+	viper.SetDefault("priority_weights", "6,3,1")
+	viper.SetDefault("delivery_workers", 4)
+	viper.SetDefault("dedupe_window", time.Hour)
+	viper.SetDefault("dedupe_max_entries", 100000)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &DeliveryConfig{
+		CriticalCategories: splitList(viper.GetString("critical_categories")),
+		SchedulerInterval:  viper.GetDuration("scheduler_interval"),
+		DigestWindow:       viper.GetDuration("digest_window"),
+		DigestMaxCount:     viper.GetInt("digest_max_count"),
+		PriorityMode:       viper.GetString("priority_mode"),
+		PriorityWeights:    parseWeights(viper.GetString("priority_weights")),
+		Workers:            viper.GetInt("delivery_workers"),
+		DedupeWindow:       viper.GetDuration("dedupe_window"),
+		DedupeMaxEntries:   viper.GetInt("dedupe_max_entries"),
+	}
+}
+
+// parseWeights разбирает веса приоритетов high,normal,low. При ошибке
+// возвращаются веса по умолчанию; каждый вес не меньше 1, чтобы ни одна
+// очередь не простаивала бесконечно
+func parseWeights(value string) []int {
+	items := splitList(value)
+	if len(items) != len(defaultPriorityWeights) {
+		return defaultPriorityWeights
+	}
+
+	weights := make([]int, len(items))
+	for i, item := range items {
+		weight, err := strconv.Atoi(item)
+		if err != nil || weight < 1 {
+			return defaultPriorityWeights
+		}
+		weights[i] = weight
+	}
+	return weights
+}
+
+// splitList разбирает список значений, разделенных запятыми
+func splitList(value string) []string {
+	var result []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
@@ -0,0 +1,23 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// FanoutConfig содержит настройки стадии развертывания рассылок
+type FanoutConfig struct {
+	BatchSize int `mapstructure:"fanout_batch_size"`
+}
+
+// LoadFanoutConfig загружает конфигурацию стадии развертывания рассылок
+func LoadFanoutConfig() *FanoutConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("fanout_batch_size", 100)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &FanoutConfig{
+		BatchSize: viper.GetInt("fanout_batch_size"),
+	}
+}
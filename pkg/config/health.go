@@ -0,0 +1,31 @@
+package config
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// HealthConfig содержит настройки проверок готовности /readyz
+type HealthConfig struct {
+	// CheckTimeout — таймаут одной проверки зависимости
+	CheckTimeout time.Duration `mapstructure:"health_check_timeout"`
+	// PollTimeout — сколько consumer может не возвращаться к чтению сообщений,
+	// прежде чем он считается зависшим
+	PollTimeout time.Duration `mapstructure:"health_poll_timeout"`
+}
+
+// LoadHealthConfig загружает конфигурацию проверок готовности
+func LoadHealthConfig() *HealthConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("health_check_timeout", 5*time.Second)
+	viper.SetDefault("health_poll_timeout", 5*time.Minute)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &HealthConfig{
+		CheckTimeout: viper.GetDuration("health_check_timeout"),
+		PollTimeout:  viper.GetDuration("health_poll_timeout"),
+	}
+}
@@ -1,23 +1,39 @@
 package config
 
 import (
+	"fmt"
 	"strings"
 	"time"
 
+	"kafka-notification-system/pkg/shared"
+
 	"github.com/spf13/viper"
 )
 
 // KafkaConfig содержит конфигурацию для Kafka
 type KafkaConfig struct {
-	Brokers              []string      `mapstructure:"brokers"`
-	ClientID             string        `mapstructure:"client_id"`
-	GroupID              string        `mapstructure:"group_id"`
-	RetryInitialTime     time.Duration `mapstructure:"retry_initial_time"`
-	RetryMaxAttempts     int           `mapstructure:"retry_max_attempts"`
-	NotificationsTopic   string        `mapstructure:"notifications_topic"`
-	DeadLetterTopic      string        `mapstructure:"dead_letter_topic"`
+	Brokers            []string        `mapstructure:"brokers"`
+	ClientID           string          `mapstructure:"client_id"`
+	GroupID            string          `mapstructure:"group_id"`
+	RetryInitialTime   time.Duration   `mapstructure:"retry_initial_time"`
+	RetryMaxAttempts   int             `mapstructure:"retry_max_attempts"`
+	NotificationsTopic string          `mapstructure:"notifications_topic"`
+	DeadLetterTopic    string          `mapstructure:"dead_letter_topic"`
+	BroadcastsTopic    string          `mapstructure:"broadcasts_topic"`
+	HighPriorityTopic  string          `mapstructure:"high_priority_topic"`
+	LowPriorityTopic   string          `mapstructure:"low_priority_topic"`
+	RetryTiers         []time.Duration `mapstructure:"retry_tiers"`
+}
+
+// RetryTier описывает уровень повторной обработки: топик и задержку перед обработкой
+type RetryTier struct {
+	Topic string
+	Delay time.Duration
 }
 
+// defaultRetryTiers задает задержки уровней повторной обработки по умолчанию
+var defaultRetryTiers = []time.Duration{30 * time.Second, 5 * time.Minute, time.Hour}
+
 // LoadKafkaConfig загружает конфигурацию Kafka
 func LoadKafkaConfig(clientID, groupID string) *KafkaConfig {
 	// Устанавливаем значения по умолчанию
@@ -26,6 +42,10 @@ func LoadKafkaConfig(clientID, groupID string) *KafkaConfig {
 	viper.SetDefault("retry_max_attempts", 8)
 	viper.SetDefault("notifications_topic", "notifications")
 	viper.SetDefault("dead_letter_topic", "dead-letter")
+	viper.SetDefault("broadcasts_topic", "broadcasts")
+	viper.SetDefault("high_priority_topic", "notifications-high")
+	viper.SetDefault("low_priority_topic", "notifications-low")
+	viper.SetDefault("retry_tiers", "30s,5m,1h")
 
 	// Читаем переменные окружения
 	viper.AutomaticEnv()
@@ -34,12 +54,75 @@ func LoadKafkaConfig(clientID, groupID string) *KafkaConfig {
 	brokers := strings.Split(brokersStr, ",")
 
 	return &KafkaConfig{
-		Brokers:              brokers,
-		ClientID:             clientID,
-		GroupID:              groupID,
-		RetryInitialTime:     viper.GetDuration("retry_initial_time"),
-		RetryMaxAttempts:     viper.GetInt("retry_max_attempts"),
-		NotificationsTopic:   viper.GetString("notifications_topic"),
-		DeadLetterTopic:      viper.GetString("dead_letter_topic"),
+		Brokers:            brokers,
+		ClientID:           clientID,
+		GroupID:            groupID,
+		RetryInitialTime:   viper.GetDuration("retry_initial_time"),
+		RetryMaxAttempts:   viper.GetInt("retry_max_attempts"),
+		NotificationsTopic: viper.GetString("notifications_topic"),
+		DeadLetterTopic:    viper.GetString("dead_letter_topic"),
+		BroadcastsTopic:    viper.GetString("broadcasts_topic"),
+		HighPriorityTopic:  viper.GetString("high_priority_topic"),
+		LowPriorityTopic:   viper.GetString("low_priority_topic"),
+		RetryTiers:         parseRetryTiers(viper.GetString("retry_tiers")),
+	}
+}
+
+// RetryTopics возвращает уровни повторной обработки уведомлений. Топик уровня
+// называется по топику уведомлений и задержке, например notifications-retry-5m
+func (c *KafkaConfig) RetryTopics() []RetryTier {
+	tiers := make([]RetryTier, 0, len(c.RetryTiers))
+	for _, delay := range c.RetryTiers {
+		tiers = append(tiers, RetryTier{
+			Topic: c.NotificationsTopic + "-retry-" + durationLabel(delay),
+			Delay: delay,
+		})
+	}
+	return tiers
+}
+
+// parseRetryTiers разбирает список задержек уровней повторной обработки.
+// При ошибке возвращаются задержки по умолчанию
+func parseRetryTiers(value string) []time.Duration {
+	var tiers []time.Duration
+	for _, item := range splitList(value) {
+		delay, err := time.ParseDuration(item)
+		if err != nil || delay <= 0 {
+			return defaultRetryTiers
+		}
+		tiers = append(tiers, delay)
+	}
+	return tiers
+}
+
+// durationLabel возвращает короткую запись длительности для имени топика: 30s, 5m, 1h
+func durationLabel(d time.Duration) string {
+	switch {
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
+
+// PriorityTopic возвращает топик уведомлений для приоритета.
+// Обычный и пустой приоритет используют NotificationsTopic
+func (c *KafkaConfig) PriorityTopic(priority string) string {
+	switch priority {
+	case shared.PriorityHigh:
+		return c.HighPriorityTopic
+	case shared.PriorityLow:
+		return c.LowPriorityTopic
+	}
+	return c.NotificationsTopic
+}
+
+// NotificationTopics возвращает топики уведомлений всех приоритетов, начиная с высокого
+func (c *KafkaConfig) NotificationTopics() []string {
+	topics := make([]string, 0, len(shared.Priorities))
+	for _, priority := range shared.Priorities {
+		topics = append(topics, c.PriorityTopic(priority))
 	}
+	return topics
 }
@@ -0,0 +1,64 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// LoggingConfig содержит настройки логгера. Значения по умолчанию зависят
+// от окружения: в production JSON с уровнем info и выборкой повторяющихся
+// записей, в остальных окружениях консольный вывод с уровнем debug
+type LoggingConfig struct {
+	// Level — минимальный уровень записей: debug, info, warn или error.
+	// Меняется во время работы через административное API
+	Level string `mapstructure:"log_level"`
+	// Encoding — формат записей: json или console
+	Encoding string `mapstructure:"log_encoding"`
+	// OutputPaths — куда писать записи: stdout, stderr или пути к файлам
+	OutputPaths []string `mapstructure:"log_output_paths"`
+	// ErrorOutputPaths — куда писать ошибки самого логгера
+	ErrorOutputPaths []string `mapstructure:"log_error_output_paths"`
+	// SamplingInitial и SamplingThereafter — из одинаковых записей за секунду
+	// пишутся первые SamplingInitial и далее каждая SamplingThereafter.
+	// SamplingInitial, равный 0, выключает выборку
+	SamplingInitial    int `mapstructure:"log_sampling_initial"`
+	SamplingThereafter int `mapstructure:"log_sampling_thereafter"`
+	// Caller — добавлять ли в записи файл и строку вызова
+	Caller bool `mapstructure:"log_caller"`
+	// StacktraceLevel — уровень, начиная с которого к записям добавляется стек
+	StacktraceLevel string `mapstructure:"log_stacktrace_level"`
+}
+
+// LoadLoggingConfig загружает конфигурацию логгера для окружения environment
+func LoadLoggingConfig(environment string) *LoggingConfig {
+	// Устанавливаем значения по умолчанию
+	if environment == "production" {
+		viper.SetDefault("log_level", "info")
+		viper.SetDefault("log_encoding", "json")
+		viper.SetDefault("log_sampling_initial", 100)
+		viper.SetDefault("log_sampling_thereafter", 100)
+		viper.SetDefault("log_stacktrace_level", "error")
+	} else {
+		viper.SetDefault("log_level", "debug")
+		viper.SetDefault("log_encoding", "console")
+		viper.SetDefault("log_sampling_initial", 0)
+		viper.SetDefault("log_sampling_thereafter", 0)
+		viper.SetDefault("log_stacktrace_level", "warn")
+	}
+	viper.SetDefault("log_output_paths", "stderr")
+	viper.SetDefault("log_error_output_paths", "stderr")
+	viper.SetDefault("log_caller", true)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &LoggingConfig{
+		Level:              viper.GetString("log_level"),
+		Encoding:           viper.GetString("log_encoding"),
+		OutputPaths:        splitList(viper.GetString("log_output_paths")),
+		ErrorOutputPaths:   splitList(viper.GetString("log_error_output_paths")),
+		SamplingInitial:    viper.GetInt("log_sampling_initial"),
+		SamplingThereafter: viper.GetInt("log_sampling_thereafter"),
+		Caller:             viper.GetBool("log_caller"),
+		StacktraceLevel:    viper.GetString("log_stacktrace_level"),
+	}
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// RateLimitConfig содержит ограничения частоты отправки сообщений в producer API.
+// Лимиты задаются в виде <запросов>/<период>, например 600/1m. Пустой лимит
+// или 0 отключает ограничение
+type RateLimitConfig struct {
+	// Store — хранилище счетчиков: memory
+	Store string `mapstructure:"rate_limit_store"`
+	// Client — лимит запросов одного клиента: API ключа, субъекта токена или IP адреса
+	Client string `mapstructure:"rate_limit_client"`
+	// Recipient — лимит сообщений одному адресату: пользователю, чату или аудитории
+	Recipient string `mapstructure:"rate_limit_recipient"`
+	// TrustedProxies — адреса и подсети прокси, которым доверяется заголовок
+	// X-Forwarded-For при определении IP клиента. Пустой список: IP берется из соединения
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+}
+
+// LoadRateLimitConfig загружает конфигурацию ограничения частоты запросов
+func LoadRateLimitConfig() *RateLimitConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("rate_limit_store", "memory")
+	viper.SetDefault("rate_limit_client", "600/1m")
+	viper.SetDefault("rate_limit_recipient", "60/1m")
+	viper.SetDefault("trusted_proxies", "")
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &RateLimitConfig{
+		Store:     viper.GetString("rate_limit_store"),
+		Client:    viper.GetString("rate_limit_client"),
+		Recipient: viper.GetString("rate_limit_recipient"),
+
+		TrustedProxies: splitList(viper.GetString("trusted_proxies")),
+	}
+}
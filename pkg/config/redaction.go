@@ -0,0 +1,66 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// Политики скрытия персональных данных в логах
+const (
+	// RedactFull — значение пишется в лог как есть
+	RedactFull = "full"
+	// RedactHash — вместо значения пишется его хеш, по которому можно сопоставлять записи
+	RedactHash = "hash"
+	// RedactTruncate — пишется начало значения и его длина
+	RedactTruncate = "truncate"
+	// RedactDrop — поле не пишется в лог
+	RedactDrop = "drop"
+)
+
+// RedactionConfig содержит политики скрытия данных пользователей в логах
+// по типам полей. В production по умолчанию данные скрываются, в остальных
+// окружениях пишутся полностью
+type RedactionConfig struct {
+	// Text — политика для текста уведомлений
+	Text string `mapstructure:"log_redact_text"`
+	// ChatID — политика для идентификаторов чатов Telegram
+	ChatID string `mapstructure:"log_redact_chat_id"`
+	// Contact — политика для контактов получателей: email, Slack, адреса webhook
+	Contact string `mapstructure:"log_redact_contact"`
+	// Payload — политика для сырого содержимого сообщений Kafka
+	Payload string `mapstructure:"log_redact_payload"`
+	// TruncateLength — сколько символов оставлять политике truncate
+	TruncateLength int `mapstructure:"log_redact_truncate_length"`
+	// HashKey — ключ HMAC для политики hash. Без ключа короткие значения,
+	// например идентификаторы чатов, можно подобрать перебором
+	HashKey string `mapstructure:"log_redact_hash_key"`
+}
+
+// LoadRedactionConfig загружает политики скрытия данных для окружения environment
+func LoadRedactionConfig(environment string) *RedactionConfig {
+	// Устанавливаем значения по умолчанию
+	if environment == "production" {
+		viper.SetDefault("log_redact_text", RedactDrop)
+		viper.SetDefault("log_redact_chat_id", RedactHash)
+		viper.SetDefault("log_redact_contact", RedactHash)
+		viper.SetDefault("log_redact_payload", RedactDrop)
+	} else {
+		viper.SetDefault("log_redact_text", RedactFull)
+		viper.SetDefault("log_redact_chat_id", RedactFull)
+		viper.SetDefault("log_redact_contact", RedactFull)
+		viper.SetDefault("log_redact_payload", RedactFull)
+	}
+	viper.SetDefault("log_redact_truncate_length", 16)
+	viper.SetDefault("log_redact_hash_key", "")
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &RedactionConfig{
+		Text:           viper.GetString("log_redact_text"),
+		ChatID:         viper.GetString("log_redact_chat_id"),
+		Contact:        viper.GetString("log_redact_contact"),
+		Payload:        viper.GetString("log_redact_payload"),
+		TruncateLength: viper.GetInt("log_redact_truncate_length"),
+		HashKey:        viper.GetString("log_redact_hash_key"),
+	}
+}
@@ -0,0 +1,39 @@
+package config
+
+import (
+	"github.com/spf13/viper"
+)
+
+// Экспортеры трассировки
+const (
+	TracingExporterNone   = "none"
+	TracingExporterStdout = "stdout"
+	TracingExporterOTLP   = "otlp"
+)
+
+// TracingConfig содержит настройки трассировки OpenTelemetry
+type TracingConfig struct {
+	// Exporter — куда отправлять спаны: none (трассировка выключена), stdout или otlp
+	Exporter string `mapstructure:"tracing_exporter"`
+	// OTLPEndpoint — адрес OTLP/HTTP коллектора, например otel-collector:4318
+	OTLPEndpoint string `mapstructure:"tracing_otlp_endpoint"`
+	// SampleRatio — доля трассируемых запросов от 0 до 1
+	SampleRatio float64 `mapstructure:"tracing_sample_ratio"`
+}
+
+// LoadTracingConfig загружает конфигурацию трассировки
+func LoadTracingConfig() *TracingConfig {
+	// Устанавливаем значения по умолчанию
+	viper.SetDefault("tracing_exporter", TracingExporterNone)
+	viper.SetDefault("tracing_otlp_endpoint", "localhost:4318")
+	viper.SetDefault("tracing_sample_ratio", 1.0)
+
+	// Читаем переменные окружения
+	viper.AutomaticEnv()
+
+	return &TracingConfig{
+		Exporter:     viper.GetString("tracing_exporter"),
+		OTLPEndpoint: viper.GetString("tracing_otlp_endpoint"),
+		SampleRatio:  viper.GetFloat64("tracing_sample_ratio"),
+	}
+}
@@ -0,0 +1,189 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrDraining возвращается Wait после начала остановки потребления
+var ErrDraining = errors.New("consumer is draining")
+
+// Состояния потребления
+const (
+	StateRunning  = "running"
+	StatePaused   = "paused"
+	StateDraining = "draining"
+)
+
+// Status описывает состояние потребления и число сообщений в обработке
+type Status struct {
+	State    string `json:"state"`
+	PausedAt int64  `json:"pausedAt,omitempty"`
+	Actor    string `json:"actor,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	// Holds — причины автоматической паузы, например отключенный канал доставки
+	Holds    []string `json:"holds,omitempty"`
+	InFlight int64    `json:"inFlight"`
+}
+
+// PauseRequest представляет запрос на приостановку потребления
+type PauseRequest struct {
+	Actor  string `json:"actor,omitempty"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Control управляет потреблением сообщений: приостановкой, возобновлением и
+// остановкой с завершением обработки (drain). Во время паузы consumer не берет
+// новые сообщения, но остается в consumer group, поэтому перебалансировки нет.
+// Кроме паузы оператора сервис может удерживать потребление сам (Hold): оно
+// возобновляется, только когда сняты пауза оператора и все удержания
+type Control struct {
+	mu        sync.Mutex
+	status    Status
+	paused    bool
+	holds     map[string]bool
+	resumed   chan struct{}
+	draining  chan struct{}
+	drainOnce sync.Once
+	inFlight  atomic.Int64
+}
+
+// NewControl создает новый экземпляр Control в состоянии running
+func NewControl() *Control {
+	resumed := make(chan struct{})
+	close(resumed)
+	return &Control{
+		status:   Status{State: StateRunning},
+		holds:    make(map[string]bool),
+		resumed:  resumed,
+		draining: make(chan struct{}),
+	}
+}
+
+// Pause приостанавливает потребление. Сообщения, уже взятые в обработку, завершаются
+func (c *Control) Pause(actor, reason string) Status {
+	c.mu.Lock()
+	if c.status.State != StateDraining && !c.paused {
+		c.paused = true
+		c.status.PausedAt = time.Now().UnixMilli()
+		c.status.Actor = actor
+		c.status.Reason = reason
+		c.update()
+	}
+	c.mu.Unlock()
+	return c.Status()
+}
+
+// Resume снимает паузу оператора. Потребление возобновляется, если нет удержаний
+func (c *Control) Resume() Status {
+	c.mu.Lock()
+	if c.status.State != StateDraining && c.paused {
+		c.paused = false
+		c.status.PausedAt = 0
+		c.status.Actor = ""
+		c.status.Reason = ""
+		c.update()
+	}
+	c.mu.Unlock()
+	return c.Status()
+}
+
+// Hold приостанавливает потребление по причине reason независимо от паузы оператора
+func (c *Control) Hold(reason string) {
+	c.mu.Lock()
+	if c.status.State != StateDraining {
+		c.holds[reason] = true
+		c.update()
+	}
+	c.mu.Unlock()
+}
+
+// Release снимает удержание reason
+func (c *Control) Release(reason string) {
+	c.mu.Lock()
+	if c.status.State != StateDraining {
+		delete(c.holds, reason)
+		c.update()
+	}
+	c.mu.Unlock()
+}
+
+// update приводит состояние в соответствие с паузой оператора и удержаниями.
+// Вызывается под блокировкой
+func (c *Control) update() {
+	blocked := c.paused || len(c.holds) > 0
+	switch {
+	case blocked && c.status.State == StateRunning:
+		c.status.State = StatePaused
+		c.resumed = make(chan struct{})
+	case !blocked && c.status.State == StatePaused:
+		c.status.State = StateRunning
+		close(c.resumed)
+	}
+}
+
+// Drain начинает остановку: новые сообщения не берутся, а Wait возвращает ErrDraining
+func (c *Control) Drain() {
+	c.drainOnce.Do(func() {
+		c.mu.Lock()
+		c.status.State = StateDraining
+		c.mu.Unlock()
+		close(c.draining)
+	})
+}
+
+// Draining возвращает канал, закрываемый при начале остановки
+func (c *Control) Draining() <-chan struct{} {
+	return c.draining
+}
+
+// Wait ждет, пока потребление приостановлено. Возвращает ErrDraining после
+// начала остановки или ошибку контекста
+func (c *Control) Wait(ctx context.Context) error {
+	select {
+	case <-c.draining:
+		return ErrDraining
+	default:
+	}
+
+	c.mu.Lock()
+	resumed := c.resumed
+	c.mu.Unlock()
+
+	select {
+	case <-resumed:
+		return nil
+	case <-c.draining:
+		return ErrDraining
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Begin отмечает начало обработки сообщения
+func (c *Control) Begin() {
+	c.inFlight.Add(1)
+}
+
+// Done отмечает завершение обработки сообщения
+func (c *Control) Done() {
+	c.inFlight.Add(-1)
+}
+
+// Status возвращает текущее состояние потребления
+func (c *Control) Status() Status {
+	c.mu.Lock()
+	status := c.status
+	for reason := range c.holds {
+		status.Holds = append(status.Holds, reason)
+	}
+	c.mu.Unlock()
+	sort.Strings(status.Holds)
+
+	status.InFlight = c.inFlight.Load()
+	return status
+}
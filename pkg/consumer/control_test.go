@@ -0,0 +1,116 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestControl_PauseBlocksUntilResume(t *testing.T) {
+	control := NewControl()
+
+	if err := control.Wait(context.Background()); err != nil {
+		t.Fatalf("Expected running control not to block, got %v", err)
+	}
+
+	status := control.Pause("ops", "telegram incident")
+	if status.State != StatePaused || status.Actor != "ops" || status.PausedAt == 0 {
+		t.Errorf("Unexpected status after pause: %+v", status)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- control.Wait(context.Background()) }()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Wait to block while paused, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if status := control.Resume(); status.State != StateRunning || status.Reason != "" {
+		t.Errorf("Unexpected status after resume: %+v", status)
+	}
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Expected nil after resume, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Wait to return after resume")
+	}
+}
+
+func TestControl_DrainReleasesPausedWaiters(t *testing.T) {
+	control := NewControl()
+	control.Pause("ops", "")
+
+	done := make(chan error, 1)
+	go func() { done <- control.Wait(context.Background()) }()
+
+	control.Drain()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrDraining) {
+			t.Errorf("Expected ErrDraining, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Wait to return after drain")
+	}
+
+	// После начала остановки пауза и возобновление не меняют состояние
+	control.Resume()
+	if status := control.Pause("ops", ""); status.State != StateDraining {
+		t.Errorf("Expected draining state, got %s", status.State)
+	}
+}
+
+func TestControl_WaitStopsOnContextCancel(t *testing.T) {
+	control := NewControl()
+	control.Pause("ops", "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := control.Wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context canceled, got %v", err)
+	}
+}
+
+func TestControl_InFlight(t *testing.T) {
+	control := NewControl()
+	control.Begin()
+	control.Begin()
+	control.Done()
+
+	if status := control.Status(); status.InFlight != 1 {
+		t.Errorf("Expected 1 message in flight, got %d", status.InFlight)
+	}
+}
+
+func TestControl_HoldIsIndependentOfOperatorPause(t *testing.T) {
+	control := NewControl()
+
+	control.Hold("circuit:telegram")
+	if status := control.Status(); status.State != StatePaused || len(status.Holds) != 1 || status.Actor != "" {
+		t.Fatalf("Unexpected status after hold: %+v", status)
+	}
+
+	// Возобновление оператором не снимает удержание
+	control.Pause("ops", "maintenance")
+	control.Resume()
+	if status := control.Status(); status.State != StatePaused {
+		t.Fatalf("Expected hold to keep consumer paused, got %+v", status)
+	}
+
+	// Снятие удержания не снимает паузу оператора
+	control.Pause("ops", "maintenance")
+	control.Release("circuit:telegram")
+	if status := control.Status(); status.State != StatePaused || status.Actor != "ops" || len(status.Holds) != 0 {
+		t.Fatalf("Expected operator pause to remain, got %+v", status)
+	}
+
+	control.Resume()
+	if err := control.Wait(context.Background()); err != nil {
+		t.Errorf("Expected running control not to block, got %v", err)
+	}
+}
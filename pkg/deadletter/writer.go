@@ -0,0 +1,133 @@
+package deadletter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// MessageWriter определяет интерфейс публикации сообщений в Kafka
+type MessageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// Writer отправляет необработанные сообщения в dead letter topic, дополняя их
+// заголовками с причиной ошибки, числом попыток, исходной позицией и сервисом
+type Writer struct {
+	writer  MessageWriter
+	service string
+}
+
+// NewWriter создает Writer для dead letter topic из конфигурации Kafka
+func NewWriter(kafkaConfig *config.KafkaConfig, service string) *Writer {
+	return New(&kafka.Writer{
+		Addr:         kafka.TCP(kafkaConfig.Brokers...),
+		Topic:        kafkaConfig.DeadLetterTopic,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireOne,
+		Async:        false,
+	}, service)
+}
+
+// New создает Writer поверх произвольного MessageWriter
+func New(writer MessageWriter, service string) *Writer {
+	return &Writer{writer: writer, service: service}
+}
+
+// Send отправляет исходное сообщение в dead letter topic вместе с причиной ошибки.
+// Заголовки исходного сообщения сохраняются
+func (w *Writer) Send(ctx context.Context, original kafka.Message, cause error) error {
+	return w.writer.WriteMessages(ctx, kafka.Message{
+		Key:     original.Key,
+		Value:   original.Value,
+		Headers: w.headers(original, cause),
+	})
+}
+
+// Stats возвращает статистику kafka.Writer dead letter topic для метрик
+func (w *Writer) Stats() kafka.WriterStats {
+	if writer, ok := w.writer.(*kafka.Writer); ok {
+		return writer.Stats()
+	}
+	return kafka.WriterStats{}
+}
+
+// headers дополняет заголовки исходного сообщения метаданными ошибки
+func (w *Writer) headers(original kafka.Message, cause error) []kafka.Header {
+	headers := make([]kafka.Header, 0, len(original.Headers)+8)
+	for _, h := range original.Headers {
+		if !isErrorHeader(h.Key) {
+			headers = append(headers, h)
+		}
+	}
+
+	add := func(key, value string) {
+		headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+	}
+
+	add(shared.HeaderErrorTimestamp, strconv.FormatInt(shared.GetCurrentTimestamp(), 10))
+	add(shared.HeaderErrorService, w.service)
+	add(shared.HeaderErrorAttempts, strconv.Itoa(Attempts(original)))
+	if cause != nil {
+		add(shared.HeaderErrorClass, Classify(cause))
+		add(shared.HeaderErrorMessage, cause.Error())
+	}
+
+	// Позиция известна только для сообщений, прочитанных из Kafka
+	if original.Topic != "" {
+		add(shared.HeaderSourceTopic, original.Topic)
+		add(shared.HeaderSourcePartition, strconv.Itoa(original.Partition))
+		add(shared.HeaderSourceOffset, strconv.FormatInt(original.Offset, 10))
+	}
+	return headers
+}
+
+// Close закрывает соединение с Kafka
+func (w *Writer) Close() error {
+	return w.writer.Close()
+}
+
+// Attempts возвращает число попыток обработки сообщения: номер последней
+// повторной попытки из заголовка retry-attempt или 1, если повторов не было
+func Attempts(message kafka.Message) int {
+	for _, h := range message.Headers {
+		if h.Key == shared.HeaderRetryAttempt {
+			if attempt, err := strconv.Atoi(string(h.Value)); err == nil && attempt > 0 {
+				return attempt
+			}
+		}
+	}
+	return 1
+}
+
+// Classify возвращает класс ошибки — тип самой вложенной ошибки в цепочке,
+// например "url.Error" или "json.SyntaxError"
+func Classify(err error) string {
+	for {
+		next := errors.Unwrap(err)
+		if next == nil {
+			break
+		}
+		err = next
+	}
+	return strings.TrimPrefix(fmt.Sprintf("%T", err), "*")
+}
+
+// isErrorHeader проверяет, что заголовок заполняется Writer и должен быть перезаписан
+func isErrorHeader(key string) bool {
+	switch key {
+	case shared.HeaderErrorTimestamp, shared.HeaderErrorService, shared.HeaderErrorAttempts,
+		shared.HeaderErrorClass, shared.HeaderErrorMessage,
+		shared.HeaderSourceTopic, shared.HeaderSourcePartition, shared.HeaderSourceOffset:
+		return true
+	}
+	return false
+}
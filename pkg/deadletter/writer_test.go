@@ -0,0 +1,114 @@
+package deadletter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// mockWriter запоминает опубликованные сообщения
+type mockWriter struct {
+	messages []kafka.Message
+}
+
+func (m *mockWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	m.messages = append(m.messages, msgs...)
+	return nil
+}
+
+func (m *mockWriter) Close() error {
+	return nil
+}
+
+func headerValue(message kafka.Message, key string) string {
+	for _, h := range message.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestWriter_Send(t *testing.T) {
+	mock := &mockWriter{}
+	writer := New(mock, "notification-service")
+
+	original := kafka.Message{
+		Topic:     "notifications",
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("key"),
+		Value:     []byte(`{"id":"1"}`),
+		Headers:   []kafka.Header{{Key: shared.HeaderMessageType, Value: []byte("notification")}},
+	}
+	cause := fmt.Errorf("failed to send: %w", errors.New("bot was blocked"))
+
+	if err := writer.Send(context.Background(), original, cause); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(mock.messages) != 1 {
+		t.Fatalf("Expected 1 dead letter, got %d", len(mock.messages))
+	}
+
+	dead := mock.messages[0]
+	expected := map[string]string{
+		shared.HeaderMessageType:     "notification",
+		shared.HeaderErrorService:    "notification-service",
+		shared.HeaderErrorMessage:    "failed to send: bot was blocked",
+		shared.HeaderErrorClass:      "errors.errorString",
+		shared.HeaderErrorAttempts:   "1",
+		shared.HeaderSourceTopic:     "notifications",
+		shared.HeaderSourcePartition: "2",
+		shared.HeaderSourceOffset:    "42",
+	}
+	for key, value := range expected {
+		if got := headerValue(dead, key); got != value {
+			t.Errorf("Expected header %s=%q, got %q", key, value, got)
+		}
+	}
+	if headerValue(dead, shared.HeaderErrorTimestamp) == "" {
+		t.Error("Expected error timestamp header")
+	}
+	if string(dead.Value) != string(original.Value) || string(dead.Key) != string(original.Key) {
+		t.Error("Expected original key and value to be preserved")
+	}
+}
+
+func TestWriter_SendWithoutSourcePosition(t *testing.T) {
+	mock := &mockWriter{}
+	writer := New(mock, "notification-service")
+
+	original := kafka.Message{
+		Value:   []byte(`{}`),
+		Headers: []kafka.Header{{Key: shared.HeaderRetryAttempt, Value: []byte("4")}},
+	}
+	if err := writer.Send(context.Background(), original, errors.New("gave up")); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dead := mock.messages[0]
+	if got := headerValue(dead, shared.HeaderErrorAttempts); got != "4" {
+		t.Errorf("Expected attempts from retry header, got %q", got)
+	}
+	if got := headerValue(dead, shared.HeaderSourceTopic); got != "" {
+		t.Errorf("Expected no source topic, got %q", got)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	var syntaxErr *json.SyntaxError
+	err := json.Unmarshal([]byte("{"), &struct{}{})
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("Expected json syntax error, got %T", err)
+	}
+
+	if class := Classify(fmt.Errorf("failed to unmarshal: %w", err)); class != "json.SyntaxError" {
+		t.Errorf("Expected json.SyntaxError, got %s", class)
+	}
+}
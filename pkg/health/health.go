@@ -0,0 +1,149 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Статусы проверок и сервиса
+const (
+	StatusOK          = "ok"
+	StatusFail        = "fail"
+	StatusUnavailable = "unavailable"
+)
+
+// Check проверяет одну зависимость сервиса. Возвращает описание состояния
+// для ответа /readyz или ошибку, если зависимость недоступна
+type Check func(ctx context.Context) (string, error)
+
+// Result описывает результат одной проверки
+type Result struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	Detail     string `json:"detail,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
+
+// Report представляет ответ /readyz: общий статус и результаты всех проверок
+type Report struct {
+	Status string   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// namedCheck хранит проверку вместе с ее именем
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker выполняет проверки готовности сервиса. Проверки выполняются
+// параллельно, каждая ограничена таймаутом
+type Checker struct {
+	mu      sync.Mutex
+	checks  []namedCheck
+	timeout time.Duration
+}
+
+// NewChecker создает новый экземпляр Checker с таймаутом одной проверки
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout}
+}
+
+// Add добавляет проверку под именем name
+func (c *Checker) Add(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Run выполняет все проверки. Сервис готов, только если прошли все проверки
+func (c *Checker) Run(ctx context.Context) *Report {
+	c.mu.Lock()
+	checks := append([]namedCheck(nil), c.checks...)
+	c.mu.Unlock()
+
+	report := &Report{Status: StatusOK, Checks: make([]Result, len(checks))}
+	var wg sync.WaitGroup
+	for i, nc := range checks {
+		wg.Add(1)
+		go func(i int, nc namedCheck) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx, nc)
+		}(i, nc)
+	}
+	wg.Wait()
+
+	for _, r := range report.Checks {
+		if r.Status != StatusOK {
+			report.Status = StatusUnavailable
+		}
+	}
+	return report
+}
+
+// run выполняет проверку с таймаутом. Проверка, не уложившаяся в таймаут,
+// считается неудачной, даже если клиент зависимости не учитывает контекст
+func (c *Checker) run(ctx context.Context, nc namedCheck) Result {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	start := time.Now()
+	go func() {
+		detail, err := nc.check(ctx)
+		done <- outcome{detail: detail, err: err}
+	}()
+
+	result := Result{Name: nc.name, Status: StatusOK}
+	select {
+	case o := <-done:
+		result.Detail = o.detail
+		if o.err != nil {
+			result.Status = StatusFail
+			result.Error = o.err.Error()
+		}
+	case <-ctx.Done():
+		result.Status = StatusFail
+		result.Error = "check timed out after " + c.timeout.String()
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result
+}
+
+// Livez отвечает, что процесс жив и обслуживает HTTP запросы. Зависимости
+// не проверяются, чтобы недоступность Kafka не приводила к перезапуску сервиса
+// @Summary Проверка жизнеспособности
+// @Description Возвращает ok, пока процесс обслуживает HTTP запросы
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string
+// @Router /livez [get]
+func (c *Checker) Livez(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": StatusOK})
+}
+
+// Readyz выполняет проверки зависимостей и отвечает 503, если хотя бы одна не прошла
+// @Summary Проверка готовности
+// @Description Проверяет Kafka, consumer group, чтение сообщений и каналы доставки
+// @Tags health
+// @Produce json
+// @Success 200 {object} Report
+// @Failure 503 {object} Report
+// @Router /readyz [get]
+func (c *Checker) Readyz(ctx *gin.Context) {
+	report := c.Run(ctx.Request.Context())
+	status := http.StatusOK
+	if report.Status != StatusOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, report)
+}
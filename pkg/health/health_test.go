@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/consumer"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestChecker_Readyz(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	checker := NewChecker(50 * time.Millisecond)
+	checker.Add("kafka", func(ctx context.Context) (string, error) { return "1 brokers, 3 topics", nil })
+	checker.Add("telegram", func(ctx context.Context) (string, error) { return "", errors.New("getMe failed") })
+	checker.Add("slow", func(ctx context.Context) (string, error) {
+		time.Sleep(time.Second)
+		return "", nil
+	})
+
+	router := gin.New()
+	router.GET("/livez", checker.Livez)
+	router.GET("/readyz", checker.Readyz)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected livez status 200, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("Expected readyz status 503, got %d", w.Code)
+	}
+
+	var report Report
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("Failed to decode report: %v", err)
+	}
+	if report.Status != StatusUnavailable || len(report.Checks) != 3 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+
+	expected := map[string]string{"kafka": StatusOK, "telegram": StatusFail, "slow": StatusFail}
+	for _, r := range report.Checks {
+		if r.Status != expected[r.Name] {
+			t.Errorf("Expected check %s to be %s, got %s (%s)", r.Name, expected[r.Name], r.Status, r.Error)
+		}
+	}
+	if report.Checks[0].Detail != "1 brokers, 3 topics" {
+		t.Errorf("Expected kafka detail, got %q", report.Checks[0].Detail)
+	}
+}
+
+func TestPollCheck(t *testing.T) {
+	now := time.Now()
+	tracker := NewPollTracker()
+	tracker.now = func() time.Time { return now }
+	control := consumer.NewControl()
+	check := PollCheck(tracker, control, time.Minute)
+
+	tracker.Waiting()
+	now = now.Add(time.Hour)
+	if _, err := check(context.Background()); err != nil {
+		t.Errorf("Expected waiting consumer to be ready, got %v", err)
+	}
+
+	tracker.Polled()
+	now = now.Add(30 * time.Second)
+	if _, err := check(context.Background()); err != nil {
+		t.Errorf("Expected consumer processing within timeout to be ready, got %v", err)
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := check(context.Background()); err == nil {
+		t.Error("Expected stuck consumer to fail readiness")
+	}
+
+	control.Pause("ops", "maintenance")
+	if _, err := check(context.Background()); err != nil {
+		t.Errorf("Expected paused consumer to be ready, got %v", err)
+	}
+
+	control.Resume()
+	control.Drain()
+	tracker.Waiting()
+	if _, err := check(context.Background()); err == nil {
+		t.Error("Expected draining consumer to fail readiness")
+	}
+}
+
+func TestDirCheck(t *testing.T) {
+	if _, err := DirCheck(t.TempDir())(context.Background()); err != nil {
+		t.Errorf("Expected writable directory, got %v", err)
+	}
+	if _, err := DirCheck(filepath.Join(t.TempDir(), "missing"))(context.Background()); err == nil {
+		t.Error("Expected error for missing directory")
+	}
+}
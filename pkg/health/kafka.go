@@ -0,0 +1,61 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// KafkaCheck проверяет связь с брокерами и наличие топиков. Метаданные
+// запрашиваются без автосоздания топиков, поэтому отсутствующий топик
+// остается ошибкой, а не создается проверкой
+func KafkaCheck(brokers []string, topics []string) Check {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...)}
+
+	return func(ctx context.Context) (string, error) {
+		metadata, err := client.Metadata(ctx, &kafka.MetadataRequest{Topics: topics})
+		if err != nil {
+			return "", fmt.Errorf("brokers unreachable: %w", err)
+		}
+
+		var missing []string
+		for _, topic := range metadata.Topics {
+			if topic.Error != nil {
+				missing = append(missing, topic.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return "", fmt.Errorf("topics not found: %s", strings.Join(missing, ", "))
+		}
+
+		return fmt.Sprintf("%d brokers, %d topics", len(metadata.Brokers), len(metadata.Topics)), nil
+	}
+}
+
+// GroupCheck проверяет, что в consumer group есть активные участники.
+// Группа без участников означает, что reader не вступил в нее или потерял сессию
+func GroupCheck(brokers []string, groupID string) Check {
+	client := &kafka.Client{Addr: kafka.TCP(brokers...)}
+
+	return func(ctx context.Context) (string, error) {
+		resp, err := client.DescribeGroups(ctx, &kafka.DescribeGroupsRequest{GroupIDs: []string{groupID}})
+		if err != nil {
+			return "", fmt.Errorf("failed to describe group %s: %w", groupID, err)
+		}
+		if len(resp.Groups) == 0 {
+			return "", fmt.Errorf("group %s not found", groupID)
+		}
+
+		group := resp.Groups[0]
+		if group.Error != nil {
+			return "", fmt.Errorf("group %s: %w", groupID, group.Error)
+		}
+		detail := fmt.Sprintf("group %s: state %s, %d members", groupID, group.GroupState, len(group.Members))
+		if len(group.Members) == 0 {
+			return detail, fmt.Errorf("group %s has no active members", groupID)
+		}
+		return detail, nil
+	}
+}
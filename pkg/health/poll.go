@@ -0,0 +1,76 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"kafka-notification-system/pkg/consumer"
+)
+
+// PollTracker отслеживает цикл чтения сообщений consumer. Цикл отмечает
+// ожидание новых сообщений и каждое полученное сообщение. Если цикл не
+// вернулся к чтению дольше допустимого, consumer считается зависшим
+type PollTracker struct {
+	mu       sync.Mutex
+	lastPoll time.Time
+	waiting  bool
+	now      func() time.Time
+}
+
+// NewPollTracker создает новый экземпляр PollTracker
+func NewPollTracker() *PollTracker {
+	return &PollTracker{lastPoll: time.Now(), now: time.Now}
+}
+
+// Waiting отмечает, что цикл ждет новые сообщения от брокера
+func (t *PollTracker) Waiting() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting = true
+	t.lastPoll = t.now()
+}
+
+// Polled отмечает получение сообщения: цикл переходит к его обработке
+func (t *PollTracker) Polled() {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.waiting = false
+	t.lastPoll = t.now()
+}
+
+// PollCheck проверяет, что цикл чтения не завис: он ждет сообщений или
+// вернулся к чтению не позже maxAge назад. Приостановленный через control
+// consumer считается готовым, а останавливающийся — нет. control может быть nil
+func PollCheck(tracker *PollTracker, control *consumer.Control, maxAge time.Duration) Check {
+	return func(ctx context.Context) (string, error) {
+		tracker.mu.Lock()
+		waiting, lastPoll := tracker.waiting, tracker.lastPoll
+		since := tracker.now().Sub(lastPoll).Round(time.Second)
+		tracker.mu.Unlock()
+
+		if control != nil {
+			switch control.Status().State {
+			case consumer.StatePaused:
+				return fmt.Sprintf("paused, last poll %s ago", since), nil
+			case consumer.StateDraining:
+				return "", fmt.Errorf("consumer is draining")
+			}
+		}
+		if waiting {
+			return "waiting for messages", nil
+		}
+		detail := fmt.Sprintf("processing, last poll %s ago", since)
+		if since > maxAge {
+			return detail, fmt.Errorf("no poll for %s, consumer is stuck", since)
+		}
+		return detail, nil
+	}
+}
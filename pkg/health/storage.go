@@ -0,0 +1,24 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// DirCheck проверяет, что в каталоге состояния можно создать файл:
+// сервис, который не может сохранить изменения, не должен принимать запросы
+func DirCheck(dir string) Check {
+	return func(ctx context.Context) (string, error) {
+		f, err := os.CreateTemp(dir, ".readyz-*")
+		if err != nil {
+			return "", fmt.Errorf("data directory is not writable: %w", err)
+		}
+		name := f.Name()
+		f.Close()
+		if err := os.Remove(name); err != nil {
+			return "", fmt.Errorf("failed to remove probe file: %w", err)
+		}
+		return dir, nil
+	}
+}
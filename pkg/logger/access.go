@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// AccessLog пишет каждый HTTP запрос в журнал l структурированными полями.
+// Успешные запросы к cfg.SkipPaths пропускаются, остальные успешные
+// записываются с вероятностью cfg.SampleRate. Ответы 4xx и 5xx пишутся всегда.
+// Подключается после RequestID, чтобы запись содержала идентификатор запроса,
+// и перед gin.Recovery, чтобы в журнал попадали ответы 500 после паники
+func AccessLog(l *zap.Logger, cfg *config.AccessLogConfig) gin.HandlerFunc {
+	return accessLog(l, cfg, rand.Float64)
+}
+
+// accessLog реализует AccessLog с заданным источником случайных чисел для выборки
+func accessLog(l *zap.Logger, cfg *config.AccessLogConfig, random func() float64) gin.HandlerFunc {
+	skip := make(map[string]bool, len(cfg.SkipPaths))
+	for _, path := range cfg.SkipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		if status < http.StatusBadRequest {
+			if skip[c.Request.URL.Path] {
+				return
+			}
+			if cfg.SampleRate < 1 && random() >= cfg.SampleRate {
+				return
+			}
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		size := c.Writer.Size()
+		if size < 0 {
+			size = 0
+		}
+
+		fields := []zap.Field{
+			zap.String("method", c.Request.Method),
+			zap.String("route", route),
+			zap.String("path", c.Request.URL.Path),
+			zap.Int("status", status),
+			zap.Duration("latency", time.Since(start)),
+			zap.String("clientIp", c.ClientIP()),
+			zap.Int("bodySize", size),
+			zap.String("userAgent", c.Request.UserAgent()),
+		}
+		if errs := c.Errors.ByType(gin.ErrorTypePrivate).String(); errs != "" {
+			fields = append(fields, zap.String("errors", errs))
+		}
+
+		level := zapcore.InfoLevel
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = zapcore.ErrorLevel
+		case status >= http.StatusBadRequest:
+			level = zapcore.WarnLevel
+		}
+		WithContext(c.Request.Context(), l).Check(level, "HTTP request").Write(fields...)
+	}
+}
@@ -0,0 +1,63 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// FieldRequestID — имя поля идентификатора запроса в логах всех сервисов
+const FieldRequestID = "requestId"
+
+type requestIDKey struct{}
+
+type fieldsKey struct{}
+
+// WithRequestID возвращает контекст с идентификатором запроса. Пустой id контекст не меняет
+func WithRequestID(ctx context.Context, id string) context.Context {
+	if id == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext возвращает идентификатор запроса из контекста или пустую строку
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithFields возвращает контекст, записи лога которого через WithContext
+// дополняются полями fields, например идентичностью клиента запроса
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(fieldsKey{}).([]zap.Field)
+	merged := make([]zap.Field, 0, len(existing)+len(fields))
+	merged = append(merged, existing...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey{}, merged)
+}
+
+// WithContext возвращает логгер l, дополненный идентификатором запроса и
+// полями из контекста. По идентификатору запроса объединяются логи всех
+// сервисов, через которые прошел запрос
+func WithContext(ctx context.Context, l *zap.Logger) *zap.Logger {
+	l = ForRequest(l, RequestIDFromContext(ctx))
+	if fields, _ := ctx.Value(fieldsKey{}).([]zap.Field); len(fields) > 0 {
+		l = l.With(fields...)
+	}
+	return l
+}
+
+// ForRequest возвращает логгер l, дополненный идентификатором запроса id.
+// Используется там, где контекста запроса нет, но id известен из сообщения
+func ForRequest(l *zap.Logger, id string) *zap.Logger {
+	if id == "" {
+		return l
+	}
+	return l.With(zap.String(FieldRequestID, id))
+}
+
+// FromContext возвращает глобальный логгер с идентификатором запроса из контекста
+func FromContext(ctx context.Context) *zap.Logger {
+	return WithContext(ctx, GetLogger())
+}
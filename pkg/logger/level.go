@@ -0,0 +1,152 @@
+package logger
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// LevelRequest представляет запрос на смену уровня логирования
+type LevelRequest struct {
+	// Level — новый уровень: debug, info, warn или error
+	Level string `json:"level" binding:"required" example:"debug"`
+	// Duration — через сколько вернуть прежний уровень, например 15m.
+	// Без Duration уровень меняется до следующего запроса или перезапуска
+	Duration string `json:"duration,omitempty" example:"15m"`
+}
+
+// LevelStatus описывает текущий уровень логирования
+type LevelStatus struct {
+	Level string `json:"level"`
+	// RevertTo и RevertAt заданы, если уровень временный
+	RevertTo string     `json:"revertTo,omitempty"`
+	RevertAt *time.Time `json:"revertAt,omitempty"`
+}
+
+// LevelControl меняет уровень логгера во время работы сервиса. Временный
+// уровень возвращается к исходному по таймеру, повторная смена уровня
+// отменяет таймер
+type LevelControl struct {
+	mu       sync.Mutex
+	level    zap.AtomicLevel
+	timer    *time.Timer
+	gen      uint64
+	revertTo zapcore.Level
+	revertAt time.Time
+}
+
+// NewLevelControl создает новый экземпляр LevelControl для уровня level
+func NewLevelControl(level zap.AtomicLevel) *LevelControl {
+	return &LevelControl{level: level}
+}
+
+var levels = NewLevelControl(zap.NewAtomicLevel())
+
+// Levels возвращает LevelControl логгера, созданного InitLogger
+func Levels() *LevelControl {
+	return levels
+}
+
+// Set устанавливает уровень level. Если duration больше нуля, через duration
+// возвращается уровень, действовавший до первой временной смены
+func (c *LevelControl) Set(level zapcore.Level, duration time.Duration) LevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	} else {
+		c.revertTo = c.level.Level()
+	}
+	c.gen++
+	c.level.SetLevel(level)
+
+	if duration > 0 {
+		gen := c.gen
+		c.revertAt = time.Now().Add(duration)
+		c.timer = time.AfterFunc(duration, func() { c.revert(gen) })
+	}
+	return c.status()
+}
+
+// Status возвращает текущий уровень логирования
+func (c *LevelControl) Status() LevelStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status()
+}
+
+// revert возвращает исходный уровень, если после смены gen уровень не менялся
+func (c *LevelControl) revert(gen uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gen != gen {
+		return
+	}
+	c.timer = nil
+	c.level.SetLevel(c.revertTo)
+}
+
+func (c *LevelControl) status() LevelStatus {
+	status := LevelStatus{Level: c.level.Level().String()}
+	if c.timer != nil {
+		revertAt := c.revertAt
+		status.RevertTo = c.revertTo.String()
+		status.RevertAt = &revertAt
+	}
+	return status
+}
+
+// GetLevel возвращает текущий уровень логирования
+// @Summary Уровень логирования
+// @Description Возвращает текущий уровень и время возврата временного уровня
+// @Tags logging
+// @Produce json
+// @Success 200 {object} LevelStatus
+// @Router /log-level [get]
+func (c *LevelControl) GetLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.Status())
+}
+
+// PutLevel меняет уровень логирования без перезапуска сервиса
+// @Summary Смена уровня логирования
+// @Description Меняет уровень логирования, временно при заданном duration
+// @Tags logging
+// @Accept json
+// @Produce json
+// @Param request body LevelRequest true "Новый уровень"
+// @Success 200 {object} LevelStatus
+// @Failure 400 {object} map[string]string
+// @Router /log-level [put]
+func (c *LevelControl) PutLevel(ctx *gin.Context) {
+	var req LevelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid log level request"})
+		return
+	}
+	level, err := zapcore.ParseLevel(req.Level)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unknown log level " + req.Level})
+		return
+	}
+	var duration time.Duration
+	if req.Duration != "" {
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil || duration <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid duration " + req.Duration})
+			return
+		}
+	}
+
+	status := c.Set(level, duration)
+	WithContext(ctx.Request.Context(), GetLogger()).Warn("Log level changed",
+		zap.String("level", status.Level),
+		zap.String("revertTo", status.RevertTo),
+		zap.String("clientIp", ctx.ClientIP()))
+	ctx.JSON(http.StatusOK, status)
+}
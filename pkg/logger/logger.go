@@ -1,25 +1,64 @@
 package logger
 
 import (
+	"fmt"
+
+	"kafka-notification-system/pkg/config"
+
 	"go.uber.org/zap"
-	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 var Logger *zap.Logger
 
-// InitLogger инициализирует логгер
+// InitLogger инициализирует логгер и политики скрытия данных пользователей
 func InitLogger(environment string) {
 	var err error
 	
-	if environment == "production" {
-		Logger, err = zap.NewProduction()
-	} else {
-		Logger, err = zap.NewDevelopment()
-	}
+	Logger, err = Build(config.LoadLoggingConfig(environment), levels.level)
 	
 	if err != nil {
 		panic(err)
 	}
+
+	SetRedaction(config.LoadRedactionConfig(environment))
+}
+
+// Build создает логгер по конфигурации cfg. Уровень логгера задается через
+// level, поэтому его можно менять во время работы
+func Build(cfg *config.LoggingConfig, level zap.AtomicLevel) (*zap.Logger, error) {
+	lvl, err := zapcore.ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log level: %w", err)
+	}
+	stacktrace, err := zapcore.ParseLevel(cfg.StacktraceLevel)
+	if err != nil {
+		return nil, fmt.Errorf("invalid stacktrace level: %w", err)
+	}
+	level.SetLevel(lvl)
+
+	encoderConfig := zap.NewProductionEncoderConfig()
+	if cfg.Encoding == "console" {
+		encoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+
+	zapConfig := zap.Config{
+		Level:             level,
+		Encoding:          cfg.Encoding,
+		EncoderConfig:     encoderConfig,
+		OutputPaths:       cfg.OutputPaths,
+		ErrorOutputPaths:  cfg.ErrorOutputPaths,
+		DisableCaller:     !cfg.Caller,
+		DisableStacktrace: true,
+	}
+	if cfg.SamplingInitial > 0 {
+		zapConfig.Sampling = &zap.SamplingConfig{
+			Initial:    cfg.SamplingInitial,
+			Thereafter: cfg.SamplingThereafter,
+		}
+	}
+
+	return zapConfig.Build(zap.AddStacktrace(stacktrace))
 }
 
 // GetLogger возвращает экземпляр логгера
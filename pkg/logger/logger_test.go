@@ -0,0 +1,264 @@
+package logger
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var seen string
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/ping", func(c *gin.Context) {
+		seen = RequestIDFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	tests := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "accepts client id", header: "req-42", expected: "req-42"},
+		{name: "generates missing id", header: ""},
+		{name: "replaces id with spaces", header: "bad id"},
+		{name: "replaces too long id", header: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			if tt.header != "" {
+				req.Header.Set(HeaderRequestID, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			returned := w.Header().Get(HeaderRequestID)
+			if returned != seen {
+				t.Errorf("Expected response id %q to match context id %q", returned, seen)
+			}
+			if tt.expected != "" && returned != tt.expected {
+				t.Errorf("Expected id %q, got %q", tt.expected, returned)
+			}
+			if tt.expected == "" && (returned == "" || returned == tt.header) {
+				t.Errorf("Expected generated id, got %q", returned)
+			}
+		})
+	}
+}
+
+func TestWithContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	base := zap.New(core)
+
+	WithContext(context.Background(), base).Info("without id")
+	WithContext(WithRequestID(context.Background(), "req-42"), base).Info("with id")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 log entries, got %d", len(entries))
+	}
+	if _, ok := entries[0].ContextMap()[FieldRequestID]; ok {
+		t.Error("Expected no requestId field without id in context")
+	}
+	if got := entries[1].ContextMap()[FieldRequestID]; got != "req-42" {
+		t.Errorf("Expected requestId req-42, got %v", got)
+	}
+}
+
+func TestAccessLog(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	core, logs := observer.New(zap.InfoLevel)
+
+	// Выборка пропускает каждый второй успешный запрос
+	calls := 0
+	random := func() float64 {
+		calls++
+		if calls%2 == 0 {
+			return 0.9
+		}
+		return 0.1
+	}
+
+	router := gin.New()
+	router.Use(RequestID())
+	router.Use(accessLog(zap.New(core), &config.AccessLogConfig{SkipPaths: []string{"/health"}, SampleRate: 0.5}, random))
+	router.GET("/health", func(c *gin.Context) { c.Status(http.StatusServiceUnavailable) })
+	router.GET("/recipients/:id", func(c *gin.Context) {
+		if c.Param("id") == "missing" {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		c.String(http.StatusOK, "ok")
+	})
+
+	serve := func(path string) {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(HeaderRequestID, "req-42")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	serve("/recipients/1")       // записан выборкой
+	serve("/recipients/2")       // пропущен выборкой
+	serve("/recipients/missing") // ошибки пишутся всегда
+	serve("/health")             // путь пропускается только при успешном ответе
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("Expected 3 access log entries, got %d", len(entries))
+	}
+
+	first := entries[0].ContextMap()
+	if first["route"] != "/recipients/:id" || first["path"] != "/recipients/1" {
+		t.Errorf("Expected route template and path, got %v / %v", first["route"], first["path"])
+	}
+	if first["status"] != int64(http.StatusOK) || first["bodySize"] != int64(2) {
+		t.Errorf("Expected status 200 and body size 2, got %v / %v", first["status"], first["bodySize"])
+	}
+	if first[FieldRequestID] != "req-42" {
+		t.Errorf("Expected requestId req-42, got %v", first[FieldRequestID])
+	}
+
+	if entries[1].Level != zap.WarnLevel || entries[1].ContextMap()["status"] != int64(http.StatusNotFound) {
+		t.Errorf("Expected warn entry for 404, got %v %v", entries[1].Level, entries[1].ContextMap()["status"])
+	}
+	if entries[2].Level != zap.ErrorLevel || entries[2].ContextMap()["path"] != "/health" {
+		t.Errorf("Expected error entry for failed /health, got %v %v", entries[2].Level, entries[2].ContextMap()["path"])
+	}
+}
+
+func TestRedaction(t *testing.T) {
+	defer SetRedaction(&config.RedactionConfig{
+		Text:    config.RedactFull,
+		ChatID:  config.RedactFull,
+		Contact: config.RedactFull,
+		Payload: config.RedactFull,
+	})
+	SetRedaction(&config.RedactionConfig{
+		Text:           config.RedactTruncate,
+		ChatID:         config.RedactHash,
+		Contact:        config.RedactHash,
+		Payload:        config.RedactDrop,
+		TruncateLength: 6,
+		HashKey:        "secret",
+	})
+
+	core, logs := observer.New(zap.InfoLevel)
+	zap.New(core).Info("Sending notification",
+		Text("text", "Привет, мир!"),
+		ChatID("chatId", 123456789),
+		Contact("email", "user@example.com"),
+		Payload("value", []byte(`{"text":"secret"}`)))
+
+	fields := logs.All()[0].ContextMap()
+	if fields["text"] != "Привет… (12 chars)" {
+		t.Errorf("Expected truncated text, got %v", fields["text"])
+	}
+	chatID, _ := fields["chatId"].(string)
+	if !strings.HasPrefix(chatID, "sha256:") || strings.Contains(chatID, "123456789") {
+		t.Errorf("Expected hashed chat id, got %v", fields["chatId"])
+	}
+	if fields["email"] != RedactContact("user@example.com") {
+		t.Errorf("Expected stable contact hash, got %v", fields["email"])
+	}
+	if _, ok := fields["value"]; ok {
+		t.Errorf("Expected payload to be dropped, got %v", fields["value"])
+	}
+
+	SetRedaction(&config.RedactionConfig{Text: "unknown"})
+	if RedactContact("user@example.com") != "[redacted]" {
+		t.Error("Expected unknown policy to hide contact")
+	}
+}
+
+func TestBuild(t *testing.T) {
+	level := zap.NewAtomicLevel()
+	l, err := Build(&config.LoggingConfig{
+		Level:              "warn",
+		Encoding:           "json",
+		OutputPaths:        []string{"stderr"},
+		ErrorOutputPaths:   []string{"stderr"},
+		SamplingInitial:    10,
+		SamplingThereafter: 10,
+		StacktraceLevel:    "error",
+	}, level)
+	if err != nil {
+		t.Fatalf("Failed to build logger: %v", err)
+	}
+	if l.Core().Enabled(zap.InfoLevel) || !l.Core().Enabled(zap.WarnLevel) {
+		t.Errorf("Expected warn level, got %s", level.Level())
+	}
+
+	level.SetLevel(zap.DebugLevel)
+	if !l.Core().Enabled(zap.DebugLevel) {
+		t.Error("Expected level change to apply to built logger")
+	}
+
+	if _, err := Build(&config.LoggingConfig{Level: "verbose", Encoding: "json", StacktraceLevel: "error"}, level); err == nil {
+		t.Error("Expected error for unknown level")
+	}
+}
+
+func TestLevelControl(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	control := NewLevelControl(zap.NewAtomicLevelAt(zap.InfoLevel))
+	router := gin.New()
+	router.GET("/log-level", control.GetLevel)
+	router.PUT("/log-level", control.PutLevel)
+
+	put := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/log-level", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := put(`{"level": "verbose"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown level, got %d", w.Code)
+	}
+	if w := put(`{"level": "debug", "duration": "-1m"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for negative duration, got %d", w.Code)
+	}
+
+	w := put(`{"level": "debug", "duration": "50ms"}`)
+	var status LevelStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &status); err != nil {
+		t.Fatalf("Failed to decode status: %v", err)
+	}
+	if status.Level != "debug" || status.RevertTo != "info" || status.RevertAt == nil {
+		t.Fatalf("Expected temporary debug level, got %+v", status)
+	}
+
+	// Повторная временная смена сохраняет исходный уровень для возврата
+	control.Set(zap.WarnLevel, 50*time.Millisecond)
+	if status := control.Status(); status.RevertTo != "info" {
+		t.Errorf("Expected revert to info, got %+v", status)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/log-level", nil))
+	if !strings.Contains(w.Body.String(), `"level":"info"`) || strings.Contains(w.Body.String(), "revertAt") {
+		t.Errorf("Expected level reverted to info, got %s", w.Body.String())
+	}
+
+	control.Set(zap.ErrorLevel, 0)
+	if status := control.Status(); status.Level != "error" || status.RevertAt != nil {
+		t.Errorf("Expected permanent error level, got %+v", status)
+	}
+}
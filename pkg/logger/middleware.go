@@ -0,0 +1,41 @@
+package logger
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HeaderRequestID — HTTP заголовок идентификатора запроса
+const HeaderRequestID = "X-Request-ID"
+
+// maxRequestIDLength ограничивает длину идентификатора, принятого от клиента
+const maxRequestIDLength = 128
+
+// RequestID принимает идентификатор запроса из заголовка X-Request-ID или
+// генерирует новый, возвращает его в ответе и сохраняет в контексте запроса
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(HeaderRequestID)
+		if !ValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		c.Header(HeaderRequestID, id)
+		c.Request = c.Request.WithContext(WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// ValidRequestID проверяет идентификатор, полученный извне: непустой, не длиннее
+// 128 символов, только видимые ASCII символы. Так чужой заголовок не ломает логи
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < '!' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"unicode/utf8"
+
+	"kafka-notification-system/pkg/config"
+
+	"go.uber.org/zap"
+)
+
+// Redactor скрывает данные пользователей в полях лога по политикам из
+// config.RedactionConfig. Неизвестная политика считается drop, чтобы
+// опечатка в настройках не открывала данные
+type Redactor struct {
+	text           string
+	chatID         string
+	contact        string
+	payload        string
+	truncateLength int
+	hashKey        []byte
+}
+
+// NewRedactor создает новый экземпляр Redactor
+func NewRedactor(cfg *config.RedactionConfig) *Redactor {
+	return &Redactor{
+		text:           cfg.Text,
+		chatID:         cfg.ChatID,
+		contact:        cfg.Contact,
+		payload:        cfg.Payload,
+		truncateLength: cfg.TruncateLength,
+		hashKey:        []byte(cfg.HashKey),
+	}
+}
+
+var redactor atomic.Pointer[Redactor]
+
+func init() {
+	redactor.Store(NewRedactor(&config.RedactionConfig{
+		Text:    config.RedactFull,
+		ChatID:  config.RedactFull,
+		Contact: config.RedactFull,
+		Payload: config.RedactFull,
+	}))
+}
+
+// SetRedaction задает политики скрытия данных для полей Text, ChatID, Contact и Payload
+func SetRedaction(cfg *config.RedactionConfig) {
+	redactor.Store(NewRedactor(cfg))
+}
+
+// Text возвращает поле с текстом уведомления
+func Text(key, value string) zap.Field {
+	r := redactor.Load()
+	return r.field(key, value, r.text)
+}
+
+// ChatID возвращает поле с идентификатором чата Telegram
+func ChatID(key string, id int64) zap.Field {
+	r := redactor.Load()
+	if r.chatID == config.RedactFull {
+		return zap.Int64(key, id)
+	}
+	return r.field(key, strconv.FormatInt(id, 10), r.chatID)
+}
+
+// Contact возвращает поле с контактом получателя: email, Slack ID или адресом webhook
+func Contact(key, value string) zap.Field {
+	r := redactor.Load()
+	return r.field(key, value, r.contact)
+}
+
+// RedactContact применяет политику для контактов к значению вне поля лога,
+// например к адресу в тексте ошибки. Политика drop заменяет значение на [redacted]
+func RedactContact(value string) string {
+	r := redactor.Load()
+	switch r.contact {
+	case config.RedactFull:
+		return value
+	case config.RedactHash:
+		return r.hash(value)
+	case config.RedactTruncate:
+		return r.truncate(value)
+	default:
+		return "[redacted]"
+	}
+}
+
+// Payload возвращает поле с сырым содержимым сообщения Kafka
+func Payload(key string, value []byte) zap.Field {
+	r := redactor.Load()
+	if r.payload == config.RedactFull {
+		return zap.ByteString(key, value)
+	}
+	return r.field(key, string(value), r.payload)
+}
+
+// field применяет политику policy к значению value
+func (r *Redactor) field(key, value, policy string) zap.Field {
+	switch policy {
+	case config.RedactFull:
+		return zap.String(key, value)
+	case config.RedactHash:
+		return zap.String(key, r.hash(value))
+	case config.RedactTruncate:
+		return zap.String(key, r.truncate(value))
+	default:
+		return zap.Skip()
+	}
+}
+
+// hash возвращает короткий HMAC-SHA256 значения: одинаковые значения дают
+// одинаковый хеш, поэтому записи одного получателя можно сопоставить
+func (r *Redactor) hash(value string) string {
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+	return "sha256:" + hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// truncate оставляет первые truncateLength символов значения и его длину
+func (r *Redactor) truncate(value string) string {
+	length := utf8.RuneCountInString(value)
+	if length <= r.truncateLength {
+		return value
+	}
+	runes := []rune(value)
+	return fmt.Sprintf("%s… (%d chars)", string(runes[:r.truncateLength]), length)
+}
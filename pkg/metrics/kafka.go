@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/segmentio/kafka-go"
+)
+
+// ReaderStatser определяет интерфейс статистики kafka.Reader
+type ReaderStatser interface {
+	Stats() kafka.ReaderStats
+}
+
+// WriterStatser определяет интерфейс статистики kafka.Writer
+type WriterStatser interface {
+	Stats() kafka.WriterStats
+}
+
+// kafkaCollector снимает Stats() с reader и writer при каждом опросе /metrics.
+// Счетчики kafka-go сбрасываются при каждом вызове Stats(), поэтому коллектор
+// накапливает их сам
+type kafkaCollector struct {
+	mu      sync.Mutex
+	readers map[string]*readerEntry
+	writers map[string]*writerEntry
+
+	readerMessages   *prometheus.Desc
+	readerErrors     *prometheus.Desc
+	readerRebalances *prometheus.Desc
+	readerLag        *prometheus.Desc
+	readerOffset     *prometheus.Desc
+	readerQueueLen   *prometheus.Desc
+	readerQueueCap   *prometheus.Desc
+	writerMessages   *prometheus.Desc
+	writerErrors     *prometheus.Desc
+	writerRetries    *prometheus.Desc
+	writerWrites     *prometheus.Desc
+}
+
+// readerEntry хранит reader и накопленные значения его счетчиков
+type readerEntry struct {
+	reader                      ReaderStatser
+	messages, errors, rebalance int64
+}
+
+// writerEntry хранит writer и накопленные значения его счетчиков
+type writerEntry struct {
+	writer                            WriterStatser
+	messages, errors, retries, writes int64
+}
+
+func newKafkaCollector() *kafkaCollector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "kafka", name), help, labels, nil)
+	}
+
+	return &kafkaCollector{
+		readers: make(map[string]*readerEntry),
+		writers: make(map[string]*writerEntry),
+
+		readerMessages:   desc("reader_messages_total", "Messages fetched by the Kafka reader.", "reader"),
+		readerErrors:     desc("reader_errors_total", "Kafka reader errors.", "reader"),
+		readerRebalances: desc("reader_rebalances_total", "Consumer group rebalances seen by the Kafka reader.", "reader"),
+		readerLag:        desc("reader_lag", "Kafka reader lag in messages.", "reader"),
+		readerOffset:     desc("reader_offset", "Current Kafka reader offset.", "reader"),
+		readerQueueLen:   desc("reader_queue_length", "Messages buffered in the Kafka reader queue.", "reader"),
+		readerQueueCap:   desc("reader_queue_capacity", "Capacity of the Kafka reader queue.", "reader"),
+		writerMessages:   desc("writer_messages_total", "Messages written by the Kafka writer.", "writer"),
+		writerErrors:     desc("writer_errors_total", "Kafka writer errors.", "writer"),
+		writerRetries:    desc("writer_retries_total", "Kafka writer retries.", "writer"),
+		writerWrites:     desc("writer_writes_total", "Kafka writer write requests.", "writer"),
+	}
+}
+
+// RegisterReader добавляет kafka.Reader в метрики под именем name, обычно топиком
+func (m *Metrics) RegisterReader(name string, reader ReaderStatser) {
+	if m == nil {
+		return
+	}
+	m.kafka.mu.Lock()
+	defer m.kafka.mu.Unlock()
+	m.kafka.readers[name] = &readerEntry{reader: reader}
+}
+
+// RegisterWriter добавляет kafka.Writer в метрики под именем name
+func (m *Metrics) RegisterWriter(name string, writer WriterStatser) {
+	if m == nil {
+		return
+	}
+	m.kafka.mu.Lock()
+	defer m.kafka.mu.Unlock()
+	m.kafka.writers[name] = &writerEntry{writer: writer}
+}
+
+// Describe реализует prometheus.Collector
+func (c *kafkaCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{
+		c.readerMessages, c.readerErrors, c.readerRebalances, c.readerLag,
+		c.readerOffset, c.readerQueueLen, c.readerQueueCap,
+		c.writerMessages, c.writerErrors, c.writerRetries, c.writerWrites,
+	} {
+		ch <- d
+	}
+}
+
+// Collect реализует prometheus.Collector
+func (c *kafkaCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, entry := range c.readers {
+		stats := entry.reader.Stats()
+		entry.messages += stats.Messages
+		entry.errors += stats.Errors
+		entry.rebalance += stats.Rebalances
+
+		ch <- prometheus.MustNewConstMetric(c.readerMessages, prometheus.CounterValue, float64(entry.messages), name)
+		ch <- prometheus.MustNewConstMetric(c.readerErrors, prometheus.CounterValue, float64(entry.errors), name)
+		ch <- prometheus.MustNewConstMetric(c.readerRebalances, prometheus.CounterValue, float64(entry.rebalance), name)
+		ch <- prometheus.MustNewConstMetric(c.readerLag, prometheus.GaugeValue, float64(stats.Lag), name)
+		ch <- prometheus.MustNewConstMetric(c.readerOffset, prometheus.GaugeValue, float64(stats.Offset), name)
+		ch <- prometheus.MustNewConstMetric(c.readerQueueLen, prometheus.GaugeValue, float64(stats.QueueLength), name)
+		ch <- prometheus.MustNewConstMetric(c.readerQueueCap, prometheus.GaugeValue, float64(stats.QueueCapacity), name)
+	}
+
+	for name, entry := range c.writers {
+		stats := entry.writer.Stats()
+		entry.messages += stats.Messages
+		entry.errors += stats.Errors
+		entry.retries += stats.Retries
+		entry.writes += stats.Writes
+
+		ch <- prometheus.MustNewConstMetric(c.writerMessages, prometheus.CounterValue, float64(entry.messages), name)
+		ch <- prometheus.MustNewConstMetric(c.writerErrors, prometheus.CounterValue, float64(entry.errors), name)
+		ch <- prometheus.MustNewConstMetric(c.writerRetries, prometheus.CounterValue, float64(entry.retries), name)
+		ch <- prometheus.MustNewConstMetric(c.writerWrites, prometheus.CounterValue, float64(entry.writes), name)
+	}
+}
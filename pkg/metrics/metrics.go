@@ -0,0 +1,224 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace задает общий префикс метрик системы
+const namespace = "notification_system"
+
+// Статусы обработки сообщений consumer
+const (
+	StatusProcessed    = "processed"
+	StatusFailed       = "failed"
+	StatusDeadLettered = "dead_lettered"
+	StatusSkipped      = "skipped"
+	StatusExpired      = "expired"
+)
+
+// Значения метрики состояния circuit breaker
+const (
+	BreakerStateClosed   = 0
+	BreakerStateHalfOpen = 1
+	BreakerStateOpen     = 2
+)
+
+// Статусы публикации и отправки
+const (
+	ResultSuccess = "success"
+	ResultError   = "error"
+)
+
+// Metrics содержит метрики сервиса. Каждая метрика помечается меткой service
+// с ClientID из конфигурации Kafka. Методы допускают nil-получатель, поэтому
+// сервисы без метрик (например, в тестах) работают без изменений
+type Metrics struct {
+	registry        *prometheus.Registry
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+	published       *prometheus.CounterVec
+	publishDuration *prometheus.HistogramVec
+	messages        *prometheus.CounterVec
+	notifications   *prometheus.CounterVec
+	channelDuration *prometheus.HistogramVec
+	breakerState    *prometheus.GaugeVec
+	breakerOpens    *prometheus.CounterVec
+	breakerRejected *prometheus.CounterVec
+	kafka           *kafkaCollector
+}
+
+// New создает метрики сервиса с меткой service
+func New(service string) *Metrics {
+	registry := prometheus.NewRegistry()
+	registerer := prometheus.WrapRegistererWith(prometheus.Labels{"service": service}, registry)
+
+	m := &Metrics{
+		registry: registry,
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		published: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_published_total",
+			Help:      "Messages published to Kafka by topic, type and result.",
+		}, []string{"topic", "type", "result"}),
+		publishDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "publish_duration_seconds",
+			Help:      "Kafka publish latency by topic and result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"topic", "result"}),
+		messages: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "messages_consumed_total",
+			Help:      "Consumed messages by type and status: processed, failed, dead_lettered, skipped.",
+		}, []string{"type", "status"}),
+		notifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "notifications_total",
+			Help:      "Notification delivery outcomes by status.",
+		}, []string{"status"}),
+		channelDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "channel_send_duration_seconds",
+			Help:      "Delivery channel send latency by channel and result.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"channel", "result"}),
+		breakerState: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "circuit_breaker_state",
+			Help:      "Delivery channel circuit breaker state: 0 closed, 1 half-open, 2 open.",
+		}, []string{"channel"}),
+		breakerOpens: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "circuit_breaker_opened_total",
+			Help:      "Times the delivery channel circuit breaker opened.",
+		}, []string{"channel"}),
+		breakerRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "circuit_breaker_rejected_total",
+			Help:      "Sends rejected by an open delivery channel circuit breaker.",
+		}, []string{"channel"}),
+		kafka: newKafkaCollector(),
+	}
+
+	registerer.MustRegister(
+		m.httpRequests, m.httpDuration,
+		m.published, m.publishDuration,
+		m.messages, m.notifications, m.channelDuration,
+		m.breakerState, m.breakerOpens, m.breakerRejected,
+		m.kafka,
+	)
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler возвращает HTTP обработчик /metrics в формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware считает HTTP запросы и их длительность. Маршрут берется из шаблона
+// gin, поэтому параметры пути не увеличивают число рядов
+func (m *Metrics) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		status := strconv.Itoa(c.Writer.Status())
+		m.httpRequests.WithLabelValues(route, c.Request.Method, status).Inc()
+		m.httpDuration.WithLabelValues(route, c.Request.Method, status).Observe(time.Since(start).Seconds())
+	}
+}
+
+// ObservePublish учитывает публикацию сообщения в Kafka
+func (m *Metrics) ObservePublish(topic, messageType string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	result := resultOf(err)
+	m.published.WithLabelValues(topic, messageType, result).Inc()
+	m.publishDuration.WithLabelValues(topic, result).Observe(duration.Seconds())
+}
+
+// MessageConsumed учитывает обработку сообщения consumer по типу и статусу
+func (m *Metrics) MessageConsumed(messageType, status string) {
+	if m == nil {
+		return
+	}
+	if messageType == "" {
+		messageType = "unknown"
+	}
+	m.messages.WithLabelValues(messageType, status).Inc()
+}
+
+// NotificationStatus учитывает итог доставки уведомления
+func (m *Metrics) NotificationStatus(status string) {
+	if m == nil {
+		return
+	}
+	m.notifications.WithLabelValues(status).Inc()
+}
+
+// ObserveChannelSend учитывает отправку через канал доставки
+func (m *Metrics) ObserveChannelSend(channel string, err error, duration time.Duration) {
+	if m == nil {
+		return
+	}
+	m.channelDuration.WithLabelValues(channel, resultOf(err)).Observe(duration.Seconds())
+}
+
+// SetBreakerState задает состояние circuit breaker канала:
+// BreakerStateClosed, BreakerStateHalfOpen или BreakerStateOpen
+func (m *Metrics) SetBreakerState(channel string, state float64) {
+	if m == nil {
+		return
+	}
+	m.breakerState.WithLabelValues(channel).Set(state)
+}
+
+// BreakerOpened учитывает отключение канала circuit breaker
+func (m *Metrics) BreakerOpened(channel string) {
+	if m == nil {
+		return
+	}
+	m.breakerOpens.WithLabelValues(channel).Inc()
+}
+
+// BreakerRejected учитывает отправку, отклоненную отключенным каналом
+func (m *Metrics) BreakerRejected(channel string) {
+	if m == nil {
+		return
+	}
+	m.breakerRejected.WithLabelValues(channel).Inc()
+}
+
+// resultOf возвращает метку результата операции
+func resultOf(err error) string {
+	if err != nil {
+		return ResultError
+	}
+	return ResultSuccess
+}
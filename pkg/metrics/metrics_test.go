@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+)
+
+// scrape возвращает ответ /metrics в текстовом формате
+func scrape(t *testing.T, m *Metrics) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, err := io.ReadAll(w.Body)
+	if err != nil {
+		t.Fatalf("Failed to read metrics: %v", err)
+	}
+	return string(body)
+}
+
+func assertContains(t *testing.T, body string, lines ...string) {
+	t.Helper()
+	for _, line := range lines {
+		if !strings.Contains(body, line) {
+			t.Errorf("Expected metrics to contain %q", line)
+		}
+	}
+}
+
+func TestMetrics_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	m := New("producer-service")
+
+	router := gin.New()
+	router.Use(m.Middleware())
+	router.GET("/recipients/:id", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+
+	for _, path := range []string{"/recipients/1", "/recipients/2", "/missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	assertContains(t, scrape(t, m),
+		`notification_system_http_requests_total{method="GET",route="/recipients/:id",service="producer-service",status="404"} 2`,
+		`notification_system_http_requests_total{method="GET",route="unmatched",service="producer-service",status="404"} 1`,
+	)
+}
+
+func TestMetrics_Counters(t *testing.T) {
+	m := New("notification-service")
+
+	m.ObservePublish("notifications", "notification", nil, 10*time.Millisecond)
+	m.ObservePublish("notifications", "notification", errors.New("broker down"), time.Second)
+	m.MessageConsumed("notification", StatusProcessed)
+	m.MessageConsumed("", StatusFailed)
+	m.NotificationStatus("delivered")
+	m.ObserveChannelSend("telegram", nil, 50*time.Millisecond)
+
+	assertContains(t, scrape(t, m),
+		`notification_system_messages_published_total{result="error",service="notification-service",topic="notifications",type="notification"} 1`,
+		`notification_system_messages_consumed_total{service="notification-service",status="processed",type="notification"} 1`,
+		`notification_system_messages_consumed_total{service="notification-service",status="failed",type="unknown"} 1`,
+		`notification_system_notifications_total{service="notification-service",status="delivered"} 1`,
+		`notification_system_channel_send_duration_seconds_count{channel="telegram",result="success",service="notification-service"} 1`,
+	)
+}
+
+// fakeReader возвращает заданную статистику reader
+type fakeReader struct {
+	stats kafka.ReaderStats
+}
+
+func (r *fakeReader) Stats() kafka.ReaderStats {
+	return r.stats
+}
+
+func TestMetrics_KafkaReaderStatsAccumulate(t *testing.T) {
+	m := New("consumer-service")
+	reader := &fakeReader{stats: kafka.ReaderStats{Messages: 3, Lag: 7, QueueLength: 2, QueueCapacity: 100}}
+	m.RegisterReader("notifications", reader)
+
+	scrape(t, m)
+	reader.stats.Messages = 2
+	reader.stats.Lag = 4
+
+	assertContains(t, scrape(t, m),
+		`notification_system_kafka_reader_messages_total{reader="notifications",service="consumer-service"} 5`,
+		`notification_system_kafka_reader_lag{reader="notifications",service="consumer-service"} 4`,
+		`notification_system_kafka_reader_queue_capacity{reader="notifications",service="consumer-service"} 100`,
+	)
+}
+
+func TestMetrics_NilIsNoop(t *testing.T) {
+	var m *Metrics
+	m.ObservePublish("notifications", "notification", nil, time.Millisecond)
+	m.MessageConsumed("notification", StatusProcessed)
+	m.NotificationStatus("delivered")
+	m.ObserveChannelSend("telegram", nil, time.Millisecond)
+	m.RegisterReader("notifications", &fakeReader{})
+}
@@ -0,0 +1,79 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLimited возвращается, если запрос превысил лимит
+var ErrLimited = errors.New("rate limit exceeded")
+
+// Limit разрешает Count запросов за Period с равномерным восстановлением:
+// после исчерпания лимита следующий запрос возможен через Period/Count
+type Limit struct {
+	Count  int
+	Period time.Duration
+}
+
+// ParseLimit разбирает лимит вида <запросов>/<период>, например 600/1m.
+// Пустая строка, 0 и off отключают ограничение
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" || value == "off" {
+		return Limit{}, nil
+	}
+
+	count, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: expected <count>/<period>, e.g. 600/1m", value)
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit count %q", count)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit period %q", period)
+	}
+	return Limit{Count: n, Period: d}, nil
+}
+
+// Enabled сообщает, ограничивает ли лимит запросы
+func (l Limit) Enabled() bool {
+	return l.Count > 0 && l.Period > 0
+}
+
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return strconv.Itoa(l.Count) + "/" + l.Period.String()
+}
+
+// LimitError описывает превышение лимита: что ограничено и когда можно повторить запрос
+type LimitError struct {
+	// Scope — вид лимита: client или recipient
+	Scope      string
+	Limit      Limit
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s %s limit %s, retry after %s", ErrLimited, e.Scope, e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrLimited
+}
+
+// RetryAfterHeader возвращает значение заголовка Retry-After в целых секундах, не меньше 1
+func (e *LimitError) RetryAfterHeader() string {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		seconds = 1
+	}
+	return strconv.Itoa(seconds)
+}
@@ -0,0 +1,105 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"kafka-notification-system/pkg/auth"
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/logger"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// Виды лимитов
+const (
+	ScopeClient    = "client"
+	ScopeRecipient = "recipient"
+)
+
+// Limiter ограничивает частоту запросов клиента и сообщений одному адресату.
+// При недоступном хранилище счетчиков запросы пропускаются
+type Limiter struct {
+	store     Store
+	client    Limit
+	recipient Limit
+	logger    *zap.Logger
+}
+
+// NewLimiter создает новый экземпляр Limiter с лимитами из конфигурации
+func NewLimiter(store Store, cfg *config.RateLimitConfig) (*Limiter, error) {
+	client, err := ParseLimit(cfg.Client)
+	if err != nil {
+		return nil, err
+	}
+	recipient, err := ParseLimit(cfg.Recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Limiter{
+		store:     store,
+		client:    client,
+		recipient: recipient,
+		logger:    logger.GetLogger(),
+	}, nil
+}
+
+// Middleware ограничивает частоту запросов клиента. Клиент определяется по
+// API ключу или субъекту токена, а без аутентификации — по IP адресу, поэтому
+// middleware подключается после auth.Middleware
+func (l *Limiter) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := l.take(c.Request.Context(), ScopeClient, clientKey(c), l.client)
+		var limitErr *LimitError
+		if errors.As(err, &limitErr) {
+			logger.WithContext(c.Request.Context(), l.logger).Warn("Client rate limit exceeded", zap.Error(err))
+			Abort(c, limitErr)
+			return
+		}
+		c.Next()
+	}
+}
+
+// AllowRecipient учитывает одно сообщение адресату recipient: user:<userId>,
+// chat:<chatId> или audience:<name>. При превышении возвращает *LimitError
+func (l *Limiter) AllowRecipient(ctx context.Context, recipient string) error {
+	if recipient == "" {
+		return nil
+	}
+	return l.take(ctx, ScopeRecipient, recipient, l.recipient)
+}
+
+// take учитывает запрос по ключу key лимита scope
+func (l *Limiter) take(ctx context.Context, scope, key string, limit Limit) error {
+	if !limit.Enabled() {
+		return nil
+	}
+
+	result, err := l.store.Take(ctx, scope+"|"+key, limit)
+	if err != nil {
+		logger.WithContext(ctx, l.logger).Error("Rate limit store error, request allowed",
+			zap.String("scope", scope), zap.Error(err))
+		return nil
+	}
+	if !result.Allowed {
+		return &LimitError{Scope: scope, Limit: limit, RetryAfter: result.RetryAfter}
+	}
+	return nil
+}
+
+// Abort отвечает 429 Too Many Requests с заголовком Retry-After
+func Abort(c *gin.Context, err *LimitError) {
+	c.Header("Retry-After", err.RetryAfterHeader())
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+}
+
+// clientKey возвращает ключ клиента: способ аутентификации и идентификатор или IP адрес
+func clientKey(c *gin.Context) string {
+	if p := auth.PrincipalFromContext(c.Request.Context()); p != nil {
+		return p.Subject()
+	}
+	return "ip:" + c.ClientIP()
+}
@@ -0,0 +1,202 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kafka-notification-system/pkg/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expected Limit
+		wantErr  bool
+	}{
+		{value: "600/1m", expected: Limit{Count: 600, Period: time.Minute}},
+		{value: " 10/1s ", expected: Limit{Count: 10, Period: time.Second}},
+		{value: ""},
+		{value: "0"},
+		{value: "off"},
+		{value: "600", wantErr: true},
+		{value: "x/1m", wantErr: true},
+		{value: "10/0s", wantErr: true},
+		{value: "10/minute", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			limit, err := ParseLimit(tt.value)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Expected error for %q", tt.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if limit != tt.expected {
+				t.Errorf("Expected %+v, got %+v", tt.expected, limit)
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	limit := Limit{Count: 2, Period: time.Minute}
+
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+			t.Fatalf("Expected request %d to be allowed", i+1)
+		}
+	}
+	result, _ := store.Take(ctx, "a", limit)
+	if result.Allowed || result.RetryAfter != 30*time.Second {
+		t.Errorf("Expected rejection with retry after 30s, got %+v", result)
+	}
+	if result, _ := store.Take(ctx, "b", limit); !result.Allowed {
+		t.Error("Expected other key to have its own limit")
+	}
+
+	// Лимит восстанавливается равномерно: один запрос за Period/Count
+	now = now.Add(30 * time.Second)
+	if result, _ := store.Take(ctx, "a", limit); !result.Allowed {
+		t.Error("Expected request to be allowed after refill")
+	}
+
+	// Восстановившиеся счетчики удаляются
+	now = now.Add(2 * time.Minute)
+	store.Take(ctx, "c", limit)
+	if _, ok := store.buckets["a"]; ok {
+		t.Error("Expected idle bucket to be swept")
+	}
+}
+
+func TestLimiter_Middleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	limiter, err := NewLimiter(NewMemoryStore(), &config.RateLimitConfig{Client: "1/1m", Recipient: "off"})
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+
+	router := gin.New()
+	router.Use(limiter.Middleware())
+	router.POST("/messages", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/messages", nil)
+		req.RemoteAddr = ip + ":12345"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := send("10.0.0.1"); w.Code != http.StatusCreated {
+		t.Fatalf("Expected first request to pass, got %d", w.Code)
+	}
+	w := send("10.0.0.1")
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "60" {
+		t.Errorf("Expected Retry-After 60, got %q", w.Header().Get("Retry-After"))
+	}
+	if w := send("10.0.0.2"); w.Code != http.StatusCreated {
+		t.Errorf("Expected other client to pass, got %d", w.Code)
+	}
+
+	// Выключенный лимит адресата не ограничивает
+	for i := 0; i < 3; i++ {
+		if err := limiter.AllowRecipient(context.Background(), "chat:1"); err != nil {
+			t.Fatalf("Expected recipient limit to be disabled, got %v", err)
+		}
+	}
+}
+
+func TestLimiter_AllowRecipient(t *testing.T) {
+	limiter, err := NewLimiter(NewMemoryStore(), &config.RateLimitConfig{Recipient: "2/1m"})
+	if err != nil {
+		t.Fatalf("Failed to create limiter: %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := limiter.AllowRecipient(ctx, "chat:1"); err != nil {
+			t.Fatalf("Expected message %d to be allowed, got %v", i+1, err)
+		}
+	}
+
+	err = limiter.AllowRecipient(ctx, "chat:1")
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || !errors.Is(err, ErrLimited) {
+		t.Fatalf("Expected LimitError, got %v", err)
+	}
+	if limitErr.Scope != ScopeRecipient || limitErr.RetryAfterHeader() != "30" {
+		t.Errorf("Unexpected limit error: %+v", limitErr)
+	}
+
+	if err := limiter.AllowRecipient(ctx, "user:1"); err != nil {
+		t.Errorf("Expected other recipient to be allowed, got %v", err)
+	}
+}
+
+func TestLimiter_Middleware_IgnoresSpoofedForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		proxies []string
+		// allowed — пропускается ли второй запрос с другим X-Forwarded-For
+		allowed bool
+	}{
+		{name: "no trusted proxies", proxies: nil, allowed: false},
+		{name: "trusted proxy", proxies: []string{"10.0.0.0/8"}, allowed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter, err := NewLimiter(NewMemoryStore(), &config.RateLimitConfig{Client: "1/1m"})
+			if err != nil {
+				t.Fatalf("Failed to create limiter: %v", err)
+			}
+
+			// Так же, как Producer Service настраивает router по TRUSTED_PROXIES
+			router := gin.New()
+			if err := router.SetTrustedProxies(tt.proxies); err != nil {
+				t.Fatalf("Failed to set trusted proxies: %v", err)
+			}
+			router.Use(limiter.Middleware())
+			router.POST("/messages", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+			send := func(forwardedFor string) int {
+				req := httptest.NewRequest(http.MethodPost, "/messages", nil)
+				req.RemoteAddr = "10.0.0.1:12345"
+				req.Header.Set("X-Forwarded-For", forwardedFor)
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				return w.Code
+			}
+
+			if code := send("203.0.113.1"); code != http.StatusCreated {
+				t.Fatalf("Expected first request to pass, got %d", code)
+			}
+			code := send("203.0.113.2")
+			if allowed := code == http.StatusCreated; allowed != tt.allowed {
+				t.Errorf("Expected second request allowed=%v, got %d", tt.allowed, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StoreMemory — хранилище счетчиков в памяти процесса
+const StoreMemory = "memory"
+
+// sweepInterval — как часто MemoryStore удаляет восстановившиеся счетчики
+const sweepInterval = time.Minute
+
+// Result — результат попытки взять запрос из лимита
+type Result struct {
+	Allowed bool
+	// Remaining — сколько запросов еще доступно без ожидания
+	Remaining int
+	// RetryAfter — через сколько станет доступен следующий запрос, если Allowed == false
+	RetryAfter time.Duration
+}
+
+// Store хранит счетчики лимитов. Реализация для общего хранилища, например
+// Redis, позволяет нескольким экземплярам producer делить один лимит
+type Store interface {
+	// Take учитывает один запрос по ключу key в лимите limit
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// NewStore создает хранилище счетчиков по названию из конфигурации
+func NewStore(name string) (Store, error) {
+	switch name {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	}
+	return nil, fmt.Errorf("unknown rate limit store %q", name)
+}
+
+// MemoryStore хранит счетчики в памяти по алгоритму token bucket. Лимиты
+// не делятся между экземплярами сервиса и сбрасываются при перезапуске
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// bucket — счетчик одного ключа: доступные запросы на момент updated
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full — момент, когда счетчик полностью восстановится и его можно удалить
+	full time.Time
+}
+
+// NewMemoryStore создает новый экземпляр MemoryStore
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+// Take учитывает один запрос по ключу key в лимите limit
+func (s *MemoryStore) Take(_ context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	now := s.now()
+	// Запросов в наносекунду
+	rate := float64(limit.Count) / float64(limit.Period)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Count), updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(float64(limit.Count), b.tokens+float64(elapsed)*rate)
+		b.updated = now
+	}
+
+	result := Result{Allowed: b.tokens >= 1}
+	if result.Allowed {
+		b.tokens--
+		result.Remaining = int(b.tokens)
+	} else {
+		result.RetryAfter = time.Duration((1 - b.tokens) / rate)
+	}
+	b.full = now.Add(time.Duration((float64(limit.Count) - b.tokens) / rate))
+	return result, nil
+}
+
+// sweep удаляет полностью восстановившиеся счетчики. Вызывается под блокировкой
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,94 @@
+package recipient
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"kafka-notification-system/pkg/logger"
+	"kafka-notification-system/pkg/shared"
+)
+
+var (
+	// ErrNotFound возвращается, если получатель отсутствует в реестре
+	ErrNotFound = errors.New("recipient not found")
+	// ErrAudienceNotFound возвращается, если аудитория отсутствует в реестре
+	ErrAudienceNotFound = errors.New("audience not found")
+)
+
+// Client обращается к HTTP API реестра получателей
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewClient создает новый экземпляр Client
+func NewClient(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Get возвращает получателя по его идентификатору
+func (c *Client) Get(ctx context.Context, userID string) (*shared.Recipient, error) {
+	var recipient shared.Recipient
+	if err := c.get(ctx, "/recipients/"+url.PathEscape(userID), &recipient); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, userID)
+		}
+		return nil, err
+	}
+	return &recipient, nil
+}
+
+// GetAudience возвращает аудиторию рассылки по названию
+func (c *Client) GetAudience(ctx context.Context, name string) (*shared.Audience, error) {
+	var audience shared.Audience
+	if err := c.get(ctx, "/audiences/"+url.PathEscape(name), &audience); err != nil {
+		if errors.Is(err, errNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrAudienceNotFound, name)
+		}
+		return nil, err
+	}
+	return &audience, nil
+}
+
+// errNotFound сигнализирует об ответе 404 от реестра
+var errNotFound = errors.New("not found")
+
+// get выполняет GET запрос к реестру и декодирует JSON ответ в v
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to build registry request: %w", err)
+	}
+	// Реестр пишет в логи тот же идентификатор запроса, что и вызывающий сервис
+	if id := logger.RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(logger.HeaderRequestID, id)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to query recipient registry: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return errNotFound
+	default:
+		return fmt.Errorf("recipient registry returned status %d", resp.StatusCode)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("failed to decode registry response: %w", err)
+	}
+	return nil
+}
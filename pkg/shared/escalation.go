@@ -0,0 +1,65 @@
+package shared
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// EscalationStep задает один шаг политики эскалации: канал доставки и время
+// ожидания подтверждения, после которого уведомление уходит на следующий шаг
+type EscalationStep struct {
+	Channel string `json:"channel" example:"telegram"`
+	// Contacts заменяют контакты получателя, например общий webhook дежурной смены
+	Contacts *ContactPoints `json:"contacts,omitempty"`
+	// AckTimeout — время ожидания подтверждения. Пустое значение завершает
+	// эскалацию после успешной доставки шага
+	AckTimeout string `json:"ackTimeout,omitempty" example:"5m"`
+}
+
+// EscalationPolicy представляет именованную политику эскалации. Шаги выполняются
+// по порядку: следующий шаг начинается при ошибке доставки или если получатель
+// не подтвердил уведомление за AckTimeout
+type EscalationPolicy struct {
+	Name        string           `json:"name" example:"oncall-critical"`
+	Description string           `json:"description,omitempty"`
+	Steps       []EscalationStep `json:"steps"`
+	CreatedAt   int64            `json:"createdAt"`
+	UpdatedAt   int64            `json:"updatedAt"`
+}
+
+// Validate проверяет каналы и время ожидания подтверждения каждого шага
+func (p *EscalationPolicy) Validate() error {
+	if len(p.Steps) == 0 {
+		return errors.New("at least one step is required")
+	}
+
+	for i, step := range p.Steps {
+		if !IsValidChannel(step.Channel) {
+			return fmt.Errorf("step %d: unknown channel %q", i+1, step.Channel)
+		}
+		if step.Contacts != nil && !step.Contacts.HasContact(step.Channel) {
+			return fmt.Errorf("step %d: contacts have no %s contact", i+1, step.Channel)
+		}
+		if _, err := step.Timeout(); err != nil {
+			return fmt.Errorf("step %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// Timeout возвращает время ожидания подтверждения шага. Ноль означает, что подтверждение не ожидается
+func (s *EscalationStep) Timeout() (time.Duration, error) {
+	if s.AckTimeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(s.AckTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ackTimeout %q: %w", s.AckTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, errors.New("ackTimeout must be positive")
+	}
+	return timeout, nil
+}
@@ -0,0 +1,28 @@
+package shared
+
+import "testing"
+
+func TestEscalationPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		steps   []EscalationStep
+		wantErr bool
+	}{
+		{"valid", []EscalationStep{{Channel: ChannelTelegram, AckTimeout: "5m"}, {Channel: ChannelEmail}}, false},
+		{"no steps", nil, true},
+		{"unknown channel", []EscalationStep{{Channel: "sms"}}, true},
+		{"invalid timeout", []EscalationStep{{Channel: ChannelTelegram, AckTimeout: "soon"}}, true},
+		{"negative timeout", []EscalationStep{{Channel: ChannelTelegram, AckTimeout: "-1m"}}, true},
+		{"contacts without channel contact", []EscalationStep{{Channel: ChannelWebhook,
+			Contacts: &ContactPoints{Email: "oncall@example.com"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := EscalationPolicy{Name: "oncall", Steps: tt.steps}
+			if err := policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package shared
+
+// Заголовки Kafka сообщений
+const (
+	HeaderMessageType = "message-type"
+	HeaderPriority    = "priority"
+	HeaderBroadcastID = "broadcast-id"
+	HeaderRequestID   = "request-id"
+
+	// Заголовки повторной обработки: номер попытки, последняя ошибка,
+	// момент, не раньше которого сообщение обрабатывается снова, и исходный топик
+	HeaderRetryAttempt     = "retry-attempt"
+	HeaderRetryLastError   = "retry-last-error"
+	HeaderRetryDueAt       = "retry-due-at"
+	HeaderRetryOriginTopic = "retry-origin-topic"
+
+	// Заголовки dead letter topic: причина ошибки, число попыток,
+	// позиция исходного сообщения и сервис, который не смог его обработать
+	HeaderErrorTimestamp  = "error-timestamp"
+	HeaderErrorClass      = "error-class"
+	HeaderErrorMessage    = "error-message"
+	HeaderErrorAttempts   = "error-attempts"
+	HeaderErrorService    = "error-service"
+	HeaderSourceTopic     = "source-topic"
+	HeaderSourcePartition = "source-partition"
+	HeaderSourceOffset    = "source-offset"
+
+	// Заголовки повторной публикации из dead letter topic: число повторных
+	// публикаций сообщения и идентификатор записи, из которой оно опубликовано
+	HeaderReplayCount  = "replay-count"
+	HeaderReplayedFrom = "replayed-from"
+)
@@ -0,0 +1,202 @@
+package shared
+
+import (
+	"fmt"
+	"time"
+)
+
+// Каналы доставки уведомлений
+const (
+	ChannelTelegram = "telegram"
+	ChannelEmail    = "email"
+	ChannelSlack    = "slack"
+	ChannelWebhook  = "webhook"
+)
+
+// DefaultChannelPriority задает порядок каналов, если у получателя он не указан
+var DefaultChannelPriority = []string{ChannelTelegram, ChannelSlack, ChannelEmail, ChannelWebhook}
+
+// ContactPoints содержит контакты получателя в каждом из каналов
+type ContactPoints struct {
+	TelegramChatID int64  `json:"telegramChatId,omitempty" example:"123456"`
+	Email          string `json:"email,omitempty" example:"user@example.com"`
+	SlackUserID    string `json:"slackUserId,omitempty" example:"U024BE7LH"`
+	WebhookURL     string `json:"webhookUrl,omitempty" example:"https://example.com/hooks/notify"`
+}
+
+// Recipient представляет получателя уведомлений в реестре
+type Recipient struct {
+	ID          string        `json:"id" example:"user-42"`
+	Name        string        `json:"name,omitempty" example:"Jane Doe"`
+	Contacts    ContactPoints `json:"contacts"`
+	Channels    []string      `json:"channels,omitempty" example:"telegram,email"`
+	Preferences Preferences   `json:"preferences"`
+	CreatedAt   int64         `json:"createdAt"`
+	UpdatedAt   int64         `json:"updatedAt"`
+}
+
+// Preferences содержит пользовательские настройки доставки уведомлений
+type Preferences struct {
+	OptOut     bool                          `json:"optOut"`
+	Categories map[string]CategoryPreference `json:"categories,omitempty"`
+	QuietHours *QuietHours                   `json:"quietHours,omitempty"`
+}
+
+// CategoryPreference задает настройки доставки для одной категории уведомлений
+type CategoryPreference struct {
+	Muted    bool     `json:"muted"`
+	Channels []string `json:"channels,omitempty" example:"email"`
+}
+
+// QuietHours задает ежедневное окно тишины в часовом поясе получателя.
+// Окно может переходить через полночь, например 22:00-08:00
+type QuietHours struct {
+	Start    string `json:"start" example:"22:00"`
+	End      string `json:"end" example:"08:00"`
+	TimeZone string `json:"timeZone" example:"Europe/Moscow"`
+}
+
+// IsValidChannel проверяет, что канал поддерживается системой
+func IsValidChannel(channel string) bool {
+	switch channel {
+	case ChannelTelegram, ChannelEmail, ChannelSlack, ChannelWebhook:
+		return true
+	}
+	return false
+}
+
+// AllowsChannel проверяет, что канал входит в ограничение allowed.
+// Пустое ограничение разрешает любой канал
+func AllowsChannel(allowed []string, channel string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+	for _, c := range allowed {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// HasContact проверяет, указан ли у получателя контакт для канала
+func (c *ContactPoints) HasContact(channel string) bool {
+	switch channel {
+	case ChannelTelegram:
+		return c.TelegramChatID != 0
+	case ChannelEmail:
+		return c.Email != ""
+	case ChannelSlack:
+		return c.SlackUserID != ""
+	case ChannelWebhook:
+		return c.WebhookURL != ""
+	}
+	return false
+}
+
+// DeliveryChannels возвращает каналы получателя в порядке приоритета,
+// оставляя только те, для которых указан контакт
+func (r *Recipient) DeliveryChannels() []string {
+	priority := r.Channels
+	if len(priority) == 0 {
+		priority = DefaultChannelPriority
+	}
+
+	channels := make([]string, 0, len(priority))
+	for _, channel := range priority {
+		if r.Contacts.HasContact(channel) {
+			channels = append(channels, channel)
+		}
+	}
+	return channels
+}
+
+// Validate проверяет категории и окно тишины
+func (p *Preferences) Validate() error {
+	for category, pref := range p.Categories {
+		for _, channel := range pref.Channels {
+			if !IsValidChannel(channel) {
+				return fmt.Errorf("unknown channel %q in category %q", channel, category)
+			}
+		}
+	}
+
+	if p.QuietHours != nil {
+		if err := p.QuietHours.Validate(); err != nil {
+			return fmt.Errorf("invalid quiet hours: %w", err)
+		}
+	}
+	return nil
+}
+
+// Validate проверяет формат времени и часовой пояс окна тишины
+func (q *QuietHours) Validate() error {
+	if _, err := parseClock(q.Start); err != nil {
+		return err
+	}
+	if _, err := parseClock(q.End); err != nil {
+		return err
+	}
+	if _, err := time.LoadLocation(q.TimeZone); err != nil {
+		return fmt.Errorf("unknown time zone %q", q.TimeZone)
+	}
+	return nil
+}
+
+// ActiveUntil проверяет, попадает ли момент now в окно тишины, и возвращает
+// момент окончания окна. Если окно не активно, возвращается false
+func (q *QuietHours) ActiveUntil(now time.Time) (time.Time, bool) {
+	loc, err := time.LoadLocation(q.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err := parseClock(q.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := parseClock(q.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := now.In(loc)
+	current := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	at := func(days int, clock time.Duration) time.Time {
+		return time.Date(local.Year(), local.Month(), local.Day()+days,
+			int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, loc)
+	}
+
+	if start < end {
+		if current >= start && current < end {
+			return at(0, end), true
+		}
+		return time.Time{}, false
+	}
+
+	// Окно переходит через полночь
+	if current >= start {
+		return at(1, end), true
+	}
+	if current < end {
+		return at(0, end), true
+	}
+	return time.Time{}, false
+}
+
+// parseClock разбирает время суток в формате HH:MM
+func parseClock(value string) (time.Duration, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// Audience представляет именованную группу получателей для рассылок
+type Audience struct {
+	Name        string   `json:"name" example:"oncall-backend"`
+	Description string   `json:"description,omitempty"`
+	Members     []string `json:"members" example:"user-42,user-43"`
+	CreatedAt   int64    `json:"createdAt"`
+	UpdatedAt   int64    `json:"updatedAt"`
+}
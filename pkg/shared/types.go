@@ -2,6 +2,9 @@ package shared
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -11,13 +14,59 @@ type BaseKafkaMessage struct {
 	Type      string      `json:"type"`
 	Payload   interface{} `json:"payload"`
 	Timestamp int64       `json:"timestamp"`
+	ExpiresAt int64       `json:"expiresAt,omitempty"`
+	// RequestID — идентификатор HTTP запроса, породившего сообщение
+	RequestID string `json:"requestId,omitempty"`
 }
 
-// NotificationMessage представляет сообщение для отправки уведомления в Telegram
+// NotificationMessage представляет сообщение для отправки уведомления.
+// Адресат задается либо напрямую через ChatID, либо через UserID из реестра получателей
 type NotificationMessage struct {
-	ChatID    int64  `json:"chatId"`
+	ChatID    int64  `json:"chatId,omitempty"`
+	UserID    string `json:"userId,omitempty"`
+	Category  string `json:"category,omitempty"`
 	Text      string `json:"text"`
+	Digest    bool   `json:"digest,omitempty"`
 	MessageID string `json:"messageId,omitempty"`
+	// EscalationPolicy — название политики эскалации для критических уведомлений
+	EscalationPolicy string `json:"escalationPolicy,omitempty"`
+	// Channels ограничивает каналы доставки. Пустой список — любые каналы по настройкам получателя
+	Channels []string `json:"channels,omitempty" example:"telegram,email"`
+}
+
+// BroadcastMessage представляет рассылку одного уведомления всем участникам аудитории
+type BroadcastMessage struct {
+	Audience string `json:"audience" example:"oncall-backend"`
+	Category string `json:"category,omitempty"`
+	Text     string `json:"text"`
+	// Channels ограничивает каналы доставки уведомлений участникам рассылки
+	Channels []string `json:"channels,omitempty" example:"email"`
+}
+
+// Типы Kafka сообщений
+const (
+	MessageTypeNotification = "notification"
+	MessageTypeBroadcast    = "broadcast"
+)
+
+// Приоритеты сообщений. Каждому приоритету соответствует свой топик,
+// чтобы срочные уведомления не ждали в очереди за массовыми рассылками
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// Priorities перечисляет приоритеты в порядке убывания важности
+var Priorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// IsValidPriority проверяет, что приоритет поддерживается. Пустой приоритет означает normal
+func IsValidPriority(priority string) bool {
+	switch priority {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	}
+	return false
 }
 
 // KafkaMessage представляет типизированное Kafka сообщение
@@ -27,8 +76,95 @@ type KafkaMessage struct {
 
 // CreateMessageRequest представляет запрос на создание сообщения
 type CreateMessageRequest struct {
-	Type    string      `json:"type" binding:"required" example:"notification"`
-	Payload interface{} `json:"payload" binding:"required" example:"{\"chatId\": 123456, \"text\": \"Hello World\"}"`
+	Type      string      `json:"type" binding:"required" example:"notification"`
+	Payload   interface{} `json:"payload" binding:"required" example:"{\"chatId\": 123456, \"text\": \"Hello World\"}"`
+	Priority  string      `json:"priority,omitempty" example:"normal" enums:"high,normal,low"`
+	TTL       string      `json:"ttl,omitempty" example:"10m"`
+	ExpiresAt int64       `json:"expiresAt,omitempty" example:"1735689600000"`
+}
+
+// Validate проверяет запрос на создание сообщения: приоритет должен быть одним
+// из high, normal, low, срок жизни — в будущем. Уведомление должно содержать
+// текст и адресата: chatId или userId, рассылка — текст и название аудитории
+func (r *CreateMessageRequest) Validate(now time.Time) error {
+	if !IsValidPriority(r.Priority) {
+		return fmt.Errorf("invalid priority %q: expected high, normal or low", r.Priority)
+	}
+	if _, err := r.ResolveExpiresAt(now); err != nil {
+		return err
+	}
+
+	switch r.Type {
+	case MessageTypeNotification:
+		return ValidateNotificationPayload(r.Payload)
+	case MessageTypeBroadcast:
+		return ValidateBroadcastPayload(r.Payload)
+	}
+	return nil
+}
+
+// ResolveExpiresAt вычисляет момент истечения сообщения в миллисекундах:
+// ttl отсчитывается от created, expiresAt задается явно. Ноль означает,
+// что сообщение не истекает
+func (r *CreateMessageRequest) ResolveExpiresAt(created time.Time) (int64, error) {
+	switch {
+	case r.TTL != "" && r.ExpiresAt != 0:
+		return 0, errors.New("specify either ttl or expiresAt, not both")
+	case r.TTL != "":
+		ttl, err := time.ParseDuration(r.TTL)
+		if err != nil {
+			return 0, fmt.Errorf("invalid ttl %q: %w", r.TTL, err)
+		}
+		if ttl <= 0 {
+			return 0, errors.New("ttl must be positive")
+		}
+		return created.Add(ttl).UnixMilli(), nil
+	case r.ExpiresAt != 0:
+		if r.ExpiresAt <= created.UnixMilli() {
+			return 0, errors.New("expiresAt must be in the future")
+		}
+		return r.ExpiresAt, nil
+	}
+	return 0, nil
+}
+
+// Recipient возвращает адресата запроса: user:<userId>, chat:<chatId> или
+// audience:<name>. У сообщений других типов адресата нет
+func (r *CreateMessageRequest) Recipient() (string, error) {
+	payloadBytes, err := json.Marshal(r.Payload)
+	if err != nil {
+		return "", err
+	}
+
+	switch r.Type {
+	case MessageTypeNotification:
+		var notification NotificationMessage
+		if err := json.Unmarshal(payloadBytes, &notification); err != nil {
+			return "", err
+		}
+		return notification.Recipient(), nil
+	case MessageTypeBroadcast:
+		var broadcast BroadcastMessage
+		if err := json.Unmarshal(payloadBytes, &broadcast); err != nil {
+			return "", err
+		}
+		return broadcast.Recipient(), nil
+	}
+	return "", nil
+}
+
+// Recipient возвращает адресата уведомления. Уведомление с userId доставляется
+// получателю из реестра, даже если задан chatId
+func (n *NotificationMessage) Recipient() string {
+	if n.UserID != "" {
+		return "user:" + n.UserID
+	}
+	return "chat:" + strconv.FormatInt(n.ChatID, 10)
+}
+
+// Recipient возвращает адресата рассылки
+func (b *BroadcastMessage) Recipient() string {
+	return "audience:" + b.Audience
 }
 
 // CreateMessageResponse представляет ответ на создание сообщения
@@ -68,9 +204,34 @@ func FromJSON(data []byte) (*KafkaMessage, error) {
 	return &msg, nil
 }
 
+// IsExpired проверяет, истек ли срок жизни сообщения к моменту now
+func (m *KafkaMessage) IsExpired(now time.Time) bool {
+	return m.ExpiresAt > 0 && now.UnixMilli() >= m.ExpiresAt
+}
+
 // IsNotificationMessage проверяет, является ли сообщение уведомлением
 func (m *KafkaMessage) IsNotificationMessage() bool {
-	return m.Type == "notification"
+	return m.Type == MessageTypeNotification
+}
+
+// IsBroadcastMessage проверяет, является ли сообщение рассылкой на аудиторию
+func (m *KafkaMessage) IsBroadcastMessage() bool {
+	return m.Type == MessageTypeBroadcast
+}
+
+// GetBroadcastPayload извлекает payload как BroadcastMessage
+func (m *KafkaMessage) GetBroadcastPayload() (*BroadcastMessage, error) {
+	payloadBytes, err := json.Marshal(m.Payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcast BroadcastMessage
+	if err := json.Unmarshal(payloadBytes, &broadcast); err != nil {
+		return nil, err
+	}
+
+	return &broadcast, nil
 }
 
 // GetNotificationPayload извлекает payload как NotificationMessage
@@ -89,6 +250,58 @@ func (m *KafkaMessage) GetNotificationPayload() (*NotificationMessage, error) {
 	return &notification, nil
 }
 
+// ValidateNotificationPayload проверяет, что payload уведомления содержит текст и адресата
+func ValidateNotificationPayload(payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var notification NotificationMessage
+	if err := json.Unmarshal(payloadBytes, &notification); err != nil {
+		return err
+	}
+
+	if notification.Text == "" {
+		return errors.New("text is required")
+	}
+	if notification.ChatID == 0 && notification.UserID == "" {
+		return errors.New("either chatId or userId is required")
+	}
+	return validateChannels(notification.Channels)
+}
+
+// ValidateBroadcastPayload проверяет, что payload рассылки содержит аудиторию и текст
+func ValidateBroadcastPayload(payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	var broadcast BroadcastMessage
+	if err := json.Unmarshal(payloadBytes, &broadcast); err != nil {
+		return err
+	}
+
+	if broadcast.Audience == "" {
+		return errors.New("audience is required")
+	}
+	if broadcast.Text == "" {
+		return errors.New("text is required")
+	}
+	return validateChannels(broadcast.Channels)
+}
+
+// validateChannels проверяет, что все каналы ограничения поддерживаются системой
+func validateChannels(channels []string) error {
+	for _, channel := range channels {
+		if !IsValidChannel(channel) {
+			return fmt.Errorf("unknown channel %q", channel)
+		}
+	}
+	return nil
+}
+
 // IsValidKafkaMessage проверяет валидность структуры Kafka сообщения
 func IsValidKafkaMessage(data map[string]interface{}) bool {
 	requiredFields := []string{"id", "type", "payload", "timestamp"}
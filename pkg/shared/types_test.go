@@ -3,6 +3,7 @@ package shared
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 func TestNewKafkaMessage(t *testing.T) {
@@ -128,3 +129,80 @@ func TestIsValidKafkaMessage(t *testing.T) {
 		t.Error("Expected invalid message to return false")
 	}
 }
+
+func TestCreateMessageRequest_ResolveExpiresAt(t *testing.T) {
+	created := time.UnixMilli(1_700_000_000_000)
+
+	tests := []struct {
+		name     string
+		req      CreateMessageRequest
+		expected int64
+		wantErr  bool
+	}{
+		{"no expiry", CreateMessageRequest{}, 0, false},
+		{"ttl", CreateMessageRequest{TTL: "10m"}, created.Add(10 * time.Minute).UnixMilli(), false},
+		{"expiresAt", CreateMessageRequest{ExpiresAt: created.UnixMilli() + 1000}, created.UnixMilli() + 1000, false},
+		{"invalid ttl", CreateMessageRequest{TTL: "soon"}, 0, true},
+		{"negative ttl", CreateMessageRequest{TTL: "-1m"}, 0, true},
+		{"expiresAt in the past", CreateMessageRequest{ExpiresAt: created.UnixMilli() - 1}, 0, true},
+		{"both", CreateMessageRequest{TTL: "1m", ExpiresAt: created.UnixMilli() + 1000}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expiresAt, err := tt.req.ResolveExpiresAt(created)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if expiresAt != tt.expected {
+				t.Errorf("Expected expiresAt %d, got %d", tt.expected, expiresAt)
+			}
+		})
+	}
+}
+
+func TestCreateMessageRequest_Validate(t *testing.T) {
+	now := time.Now()
+	notification := map[string]interface{}{"chatId": 1, "text": "Hello"}
+
+	tests := []struct {
+		name    string
+		req     CreateMessageRequest
+		wantErr bool
+	}{
+		{"valid notification", CreateMessageRequest{Type: MessageTypeNotification, Payload: notification}, false},
+		{"valid broadcast", CreateMessageRequest{Type: MessageTypeBroadcast,
+			Payload: map[string]interface{}{"audience": "oncall", "text": "Hello"}}, false},
+		{"invalid priority", CreateMessageRequest{Type: MessageTypeNotification, Payload: notification, Priority: "urgent"}, true},
+		{"invalid ttl", CreateMessageRequest{Type: MessageTypeNotification, Payload: notification, TTL: "soon"}, true},
+		{"missing recipient", CreateMessageRequest{Type: MessageTypeNotification,
+			Payload: map[string]interface{}{"text": "Hello"}}, true},
+		{"missing audience", CreateMessageRequest{Type: MessageTypeBroadcast,
+			Payload: map[string]interface{}{"text": "Hello"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.req.Validate(now); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestKafkaMessage_IsExpired(t *testing.T) {
+	message := NewKafkaMessage("notification", nil)
+	now := time.UnixMilli(message.Timestamp)
+
+	if message.IsExpired(now.Add(time.Hour)) {
+		t.Error("Expected message without expiresAt to never expire")
+	}
+
+	message.ExpiresAt = now.Add(time.Minute).UnixMilli()
+	if message.IsExpired(now) {
+		t.Error("Expected message to be alive before expiresAt")
+	}
+	if !message.IsExpired(now.Add(time.Minute)) {
+		t.Error("Expected message to be expired at expiresAt")
+	}
+}
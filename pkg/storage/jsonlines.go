@@ -0,0 +1,106 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONLines хранит журнал записей в файле, по одной JSON записи на строку.
+// Записи только добавляются, поэтому файл подходит для аудита
+type JSONLines struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewJSONLines создает новый экземпляр JSONLines
+func NewJSONLines(path string) *JSONLines {
+	return &JSONLines{path: path}
+}
+
+// Append дописывает запись v в конец файла
+func (f *JSONLines) Append(v interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %w", f.path, err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+	}
+
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write %s: %w", f.path, err)
+	}
+	return file.Sync()
+}
+
+// ReadAll читает все записи файла и передает каждую в decode.
+// Отсутствующий файл не считается ошибкой
+func (f *JSONLines) ReadAll(decode func(line []byte) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.Open(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if err := decode(scanner.Bytes()); err != nil {
+			return fmt.Errorf("failed to decode %s: %w", f.path, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// Rewrite атомарно заменяет содержимое файла записями values через временный
+// файл и rename. Используется для сжатия журнала
+func (f *JSONLines) Rewrite(values []interface{}) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var data []byte
+	for _, v := range values {
+		line, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s: %w", f.path, err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(f.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", f.path, err)
+	}
+
+	tmp := f.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, f.path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", f.path, err)
+	}
+	return nil
+}
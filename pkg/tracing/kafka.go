@@ -0,0 +1,94 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderCarrier передает контекст трассировки в заголовках Kafka сообщения
+// (traceparent, tracestate, baggage)
+type HeaderCarrier struct {
+	Headers *[]kafka.Header
+}
+
+var _ propagation.TextMapCarrier = HeaderCarrier{}
+
+// Get возвращает значение заголовка
+func (c HeaderCarrier) Get(key string) string {
+	for _, h := range *c.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set заменяет значение заголовка
+func (c HeaderCarrier) Set(key, value string) {
+	headers := (*c.Headers)[:0:0]
+	for _, h := range *c.Headers {
+		if h.Key != key {
+			headers = append(headers, h)
+		}
+	}
+	*c.Headers = append(headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+// Keys возвращает ключи всех заголовков
+func (c HeaderCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.Headers))
+	for _, h := range *c.Headers {
+		keys = append(keys, h.Key)
+	}
+	return keys
+}
+
+// Inject возвращает копию заголовков с контекстом трассировки ctx
+func Inject(ctx context.Context, headers []kafka.Header) []kafka.Header {
+	result := append([]kafka.Header(nil), headers...)
+	otel.GetTextMapPropagator().Inject(ctx, HeaderCarrier{Headers: &result})
+	return result
+}
+
+// Extract возвращает ctx с контекстом трассировки из заголовков сообщения
+func Extract(ctx context.Context, headers []kafka.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, HeaderCarrier{Headers: &headers})
+}
+
+// StartConsumer начинает спан обработки сообщения, продолжающий трассировку из его заголовков
+func StartConsumer(ctx context.Context, message kafka.Message) (context.Context, trace.Span) {
+	ctx = Extract(ctx, message.Headers)
+	return Tracer().Start(ctx, "process "+message.Topic,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", message.Topic),
+			attribute.Int("messaging.kafka.destination.partition", message.Partition),
+			attribute.Int64("messaging.kafka.message.offset", message.Offset),
+		))
+}
+
+// StartProducer начинает спан публикации сообщения в топик
+func StartProducer(ctx context.Context, topic string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "publish "+topic,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attribute.String("messaging.system", "kafka"),
+			attribute.String("messaging.destination.name", topic),
+		))
+}
+
+// End завершает спан, отмечая ошибку, если она есть
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
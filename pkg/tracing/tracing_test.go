@@ -0,0 +1,119 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"kafka-notification-system/pkg/config"
+	"kafka-notification-system/pkg/shared"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// setupRecorder подключает глобальный TracerProvider, который записывает спаны в память
+func setupRecorder(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestInit_NoneExporter(t *testing.T) {
+	shutdown, err := Init("test-service", &config.TracingConfig{Exporter: config.TracingExporterNone})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Unexpected shutdown error: %v", err)
+	}
+
+	if _, err := Init("test-service", &config.TracingConfig{Exporter: "jaeger"}); err == nil {
+		t.Error("Expected error for unknown exporter")
+	}
+}
+
+func TestInjectExtract_RoundTrip(t *testing.T) {
+	recorder := setupRecorder(t)
+
+	ctx, producer := StartProducer(context.Background(), "notifications")
+	headers := Inject(ctx, []kafka.Header{{Key: shared.HeaderMessageType, Value: []byte("notification")}})
+	producer.End()
+
+	message := kafka.Message{Topic: "notifications", Headers: headers}
+	if got := (HeaderCarrier{Headers: &message.Headers}).Get(shared.HeaderMessageType); got != "notification" {
+		t.Errorf("Expected message-type header to be kept, got %q", got)
+	}
+	if (HeaderCarrier{Headers: &message.Headers}).Get("traceparent") == "" {
+		t.Fatal("Expected traceparent header")
+	}
+
+	// Повторная вставка заменяет traceparent, а не дублирует его
+	headers = Inject(ctx, headers)
+	if len(headers) != 2 {
+		t.Errorf("Expected 2 headers after reinjection, got %d", len(headers))
+	}
+
+	_, consumer := StartConsumer(context.Background(), message)
+	consumer.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[1].Name() != "process notifications" || spans[1].SpanKind() != trace.SpanKindConsumer {
+		t.Errorf("Unexpected consumer span %q (%s)", spans[1].Name(), spans[1].SpanKind())
+	}
+	if spans[1].Parent().SpanID() != spans[0].SpanContext().SpanID() {
+		t.Error("Expected consumer span to be a child of the producer span")
+	}
+	if spans[1].SpanContext().TraceID() != spans[0].SpanContext().TraceID() {
+		t.Error("Expected consumer span to continue the producer trace")
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := setupRecorder(t)
+
+	router := gin.New()
+	router.Use(Middleware())
+	router.GET("/messages/:id", func(c *gin.Context) {
+		if !trace.SpanContextFromContext(c.Request.Context()).IsValid() {
+			t.Error("Expected span in request context")
+		}
+		c.Status(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/messages/1", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /messages/:id" {
+		t.Errorf("Expected span name 'GET /messages/:id', got %q", span.Name())
+	}
+	if span.SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected span to continue client trace, got %s", span.SpanContext().TraceID())
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("Expected error status for 5xx response, got %v", span.Status().Code)
+	}
+}
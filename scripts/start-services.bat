@@ -25,12 +25,24 @@ timeout /t 2 /nobreak >nul
 echo Starting Notification Service on port 3002...
 start "Notification Service" powershell -NoExit -Command "$env:PORT='3002'; go run ./cmd/notification-service"
 
+timeout /t 2 /nobreak >nul
+
+echo Starting Recipient Service on port 3003...
+start "Recipient Service" powershell -NoExit -Command "$env:PORT='3003'; go run ./cmd/recipient-service"
+
+timeout /t 2 /nobreak >nul
+
+echo Starting Fan-out Service on port 3004...
+start "Fan-out Service" powershell -NoExit -Command "$env:PORT='3004'; go run ./cmd/fanout-service"
+
 echo.
 echo ✅ All services started!
 echo.
 echo 🌐 Producer Service (with Swagger): http://localhost:3000/api/index.html
 echo ⚙️  Consumer Service: http://localhost:3001/health
 echo 📱 Notification Service: http://localhost:3002/health
+echo 📇 Recipient Service: http://localhost:3003/health
+echo 📣 Fan-out Service: http://localhost:3004/health
 echo.
 echo 📋 To test the system, send a POST request to:
 echo    curl -X POST http://localhost:3000/messages ^
@@ -51,6 +51,10 @@ sleep 2
 start_service "Consumer Service" "3001" "go run ./cmd/consumer-service"
 sleep 2
 start_service "Notification Service" "3002" "go run ./cmd/notification-service"
+sleep 2
+start_service "Recipient Service" "3003" "go run ./cmd/recipient-service"
+sleep 2
+start_service "Fan-out Service" "3004" "go run ./cmd/fanout-service"
 
 echo ""
 echo "✅ All services started!"
@@ -58,6 +62,8 @@ echo ""
 echo "🌐 Producer Service (with Swagger): http://localhost:3000/api/index.html"
 echo "⚙️  Consumer Service: http://localhost:3001/health"
 echo "📱 Notification Service: http://localhost:3002/health"
+echo "📇 Recipient Service: http://localhost:3003/health"
+echo "📣 Fan-out Service: http://localhost:3004/health"
 echo ""
 echo "📋 To test the system, send a POST request to:"
 echo "   curl -X POST http://localhost:3000/messages \\"